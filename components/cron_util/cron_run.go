@@ -0,0 +1,344 @@
+package cron_util
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"os/exec"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultMaxRuns 每个任务保留的执行记录数量
+const DefaultMaxRuns = 20
+
+// maxOutputSize 每次执行保留的标准输出和标准错误的最大长度，超出时保留末尾
+const maxOutputSize = 64 * 1024
+
+// killGracePeriod 超时后先向进程组发送 SIGTERM，等待这段时间后仍未退出则发送 SIGKILL
+// 使用变量以便测试中缩短
+var killGracePeriod = 10 * time.Second
+
+// killWaitDelay 命令被终止后等待输出管道关闭的最长时间
+const killWaitDelay = 5 * time.Second
+
+// DefaultRetryDelay 未设置重试间隔时第一次重试前等待的时间
+const DefaultRetryDelay = 10 * time.Second
+
+// maxRetryDelay 重试间隔翻倍后的上限
+const maxRetryDelay = time.Hour
+
+// MaxRetries 允许设置的最大重试次数
+const MaxRetries = 10
+
+// ErrTaskRunning 任务的并发策略为 skip 且上一次执行尚未结束
+var ErrTaskRunning = errors.New("任务正在执行中")
+
+// tailBuffer 只保留最后 limit 字节的输出
+type tailBuffer struct {
+	limit     int
+	data      []byte
+	truncated bool
+}
+
+func (b *tailBuffer) Write(p []byte) (int, error) {
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.limit; over > 0 {
+		b.data = b.data[over:]
+		b.truncated = true
+	}
+	return len(p), nil
+}
+
+func (b *tailBuffer) String() string {
+	if b.truncated {
+		return "...（输出过长，只保留最后部分）\n" + string(b.data)
+	}
+	return string(b.data)
+}
+
+// RunTask 立即在后台执行任务，返回执行中或排队中的记录
+// 任务的并发策略为 skip 且上一次执行尚未结束时返回 ErrTaskRunning
+func (m *CronTaskManager) RunTask(id int) (*CronRun, error) {
+	m.tasksMutex.RLock()
+	task, exists := m.tasks[id]
+	m.tasksMutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("任务不存在")
+	}
+
+	run, spec, err := m.startRun(task, CronTriggerManual)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := *run
+	go m.finishRun(task, spec, run)
+	return &snapshot, nil
+}
+
+// GetRuns 获取任务的执行记录，最新的在前
+func (m *CronTaskManager) GetRuns(id int) ([]CronRun, error) {
+	m.tasksMutex.RLock()
+	_, exists := m.tasks[id]
+	m.tasksMutex.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("任务不存在")
+	}
+
+	m.runsMutex.Lock()
+	defer m.runsMutex.Unlock()
+
+	runs := m.runs[id]
+	result := make([]CronRun, 0, len(runs))
+	for i := len(runs) - 1; i >= 0; i-- {
+		result = append(result, *runs[i])
+	}
+	return result, nil
+}
+
+// executeTask 按定时表达式执行任务，设置了随机延迟时先等待一段随机时间
+func (m *CronTaskManager) executeTask(task *CronTask) {
+	m.tasksMutex.RLock()
+	name, jitter := task.Name, task.Jitter
+	m.tasksMutex.RUnlock()
+
+	if jitter > 0 {
+		time.Sleep(time.Duration(rand.Int63n(int64(jitter)*int64(time.Second) + 1)))
+	}
+
+	run, spec, err := m.startRun(task, CronTriggerSchedule)
+	if errors.Is(err, ErrTaskRunning) {
+		fmt.Printf("任务 %s 上一次执行尚未结束，跳过本次执行\n", name)
+		m.addRun(task.ID, CronTriggerSchedule, 1, CronRunSkipped)
+		return
+	}
+	m.finishRun(task, spec, run)
+}
+
+// startRun 按任务的并发策略创建执行记录，返回记录和开始执行时任务的配置
+// 并发策略为 skip 时在这里获取任务的执行锁，获取失败返回 ErrTaskRunning
+func (m *CronTaskManager) startRun(task *CronTask, trigger string) (*CronRun, CronTask, error) {
+	m.tasksMutex.RLock()
+	spec := *task
+	m.tasksMutex.RUnlock()
+
+	status := CronRunRunning
+	switch spec.Concurrency {
+	case CronConcurrencySkip:
+		if !m.taskLock(task.ID).TryLock() {
+			return nil, spec, ErrTaskRunning
+		}
+	case CronConcurrencyQueue:
+		status = CronRunQueued
+	}
+
+	m.tasksMutex.Lock()
+	task.LastRun = time.Now()
+	task.LastStatus = status
+	m.tasksMutex.Unlock()
+
+	return m.addRun(task.ID, trigger, 1, status), spec, nil
+}
+
+// addRun 创建执行记录并加入任务的历史，超出保留数量时删除最旧的记录
+func (m *CronTaskManager) addRun(taskID int, trigger string, attempt int, status string) *CronRun {
+	m.runsMutex.Lock()
+	defer m.runsMutex.Unlock()
+
+	m.lastRunID++
+	run := &CronRun{
+		ID:        m.lastRunID,
+		TaskID:    taskID,
+		Trigger:   trigger,
+		Attempt:   attempt,
+		Status:    status,
+		StartedAt: time.Now(),
+	}
+	// 跳过的执行没有执行过程
+	if status == CronRunSkipped {
+		run.FinishedAt = run.StartedAt
+	}
+
+	runs := append(m.runs[taskID], run)
+	if over := len(runs) - m.maxRuns; over > 0 {
+		runs = runs[over:]
+	}
+	m.runs[taskID] = runs
+	return run
+}
+
+// taskLock 返回任务的执行锁，并发策略为 skip 和 queue 的任务通过它避免重叠执行
+func (m *CronTaskManager) taskLock(id int) *sync.Mutex {
+	m.runsMutex.Lock()
+	defer m.runsMutex.Unlock()
+
+	lock, exists := m.locks[id]
+	if !exists {
+		lock = &sync.Mutex{}
+		m.locks[id] = lock
+	}
+	return lock
+}
+
+// finishRun 执行任务的命令并记录结果，失败或超时后按配置的次数重试
+// 重试期间一直持有执行锁，重试也算作同一次执行
+func (m *CronTaskManager) finishRun(task *CronTask, spec CronTask, run *CronRun) {
+	switch spec.Concurrency {
+	case CronConcurrencySkip:
+		defer m.taskLock(task.ID).Unlock()
+	case CronConcurrencyQueue:
+		lock := m.taskLock(task.ID)
+		lock.Lock()
+		defer lock.Unlock()
+
+		m.runsMutex.Lock()
+		run.Status = CronRunRunning
+		run.StartedAt = time.Now()
+		m.runsMutex.Unlock()
+	}
+
+	for attempt := 1; ; attempt++ {
+		status := m.execute(task, spec, run)
+		if status == CronRunSuccess || attempt > spec.Retries {
+			return
+		}
+
+		delay := retryDelay(spec, attempt)
+		fmt.Printf("任务 %s 执行失败，%s 后进行第 %d 次重试\n", spec.Name, delay, attempt)
+		time.Sleep(delay)
+
+		// 任务已被删除或修改时不再重试
+		m.tasksMutex.RLock()
+		current := m.tasks[task.ID] == task
+		m.tasksMutex.RUnlock()
+		if !current {
+			return
+		}
+
+		run = m.addRun(task.ID, CronTriggerRetry, attempt+1, CronRunRunning)
+	}
+}
+
+// execute 执行一次任务的命令，把结果写入执行记录并保存任务的执行状态
+func (m *CronTaskManager) execute(task *CronTask, spec CronTask, run *CronRun) string {
+	m.tasksMutex.Lock()
+	task.LastRun = time.Now()
+	task.LastStatus = CronRunRunning
+	m.tasksMutex.Unlock()
+
+	fmt.Printf("执行任务 %s: %s\n", spec.Name, spec.Command)
+	stdout, stderr, exitCode, status, err := runCommand(spec)
+	fmt.Printf("任务 %s 执行结束: %s\n", spec.Name, status)
+
+	m.runsMutex.Lock()
+	run.Stdout = stdout
+	run.Stderr = stderr
+	run.ExitCode = exitCode
+	run.Status = status
+	if err != nil {
+		run.Error = err.Error()
+	}
+	run.FinishedAt = time.Now()
+	run.Duration = run.FinishedAt.Sub(run.StartedAt).Seconds()
+	m.runsMutex.Unlock()
+
+	m.tasksMutex.Lock()
+	task.LastStatus = status
+	// 任务已被删除或修改时，不再保存旧任务的状态
+	if m.tasks[task.ID] == task {
+		if err := m.save(); err != nil {
+			fmt.Printf("保存任务 %s 的执行状态失败: %v\n", spec.Name, err)
+		}
+	}
+	m.tasksMutex.Unlock()
+
+	return status
+}
+
+// retryDelay 返回第 attempt 次执行失败后的等待时间，每次翻倍，最长为 maxRetryDelay
+func retryDelay(task CronTask, attempt int) time.Duration {
+	delay := DefaultRetryDelay
+	if task.RetryDelay > 0 {
+		delay = time.Duration(task.RetryDelay) * time.Second
+	}
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// runCommand 通过 sh -c 执行任务的命令，返回输出、退出码和状态
+// 命令在独立的进程组中运行，超时后先向整个进程组发送 SIGTERM，宽限期后再发送 SIGKILL
+func runCommand(task CronTask) (string, string, int, string, error) {
+	ctx := context.Background()
+	if task.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(task.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	stdout := &tailBuffer{limit: maxOutputSize}
+	stderr := &tailBuffer{limit: maxOutputSize}
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", task.Command)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	var killTimer *time.Timer
+	cmd.Cancel = func() error {
+		pgid := cmd.Process.Pid
+		killTimer = time.AfterFunc(killGracePeriod, func() {
+			syscall.Kill(-pgid, syscall.SIGKILL)
+		})
+		return syscall.Kill(-pgid, syscall.SIGTERM)
+	}
+	cmd.WaitDelay = killGracePeriod + killWaitDelay
+
+	env := os.Environ()
+	dir := task.WorkDir
+	if task.User != "" {
+		u, err := user.Lookup(task.User)
+		if err != nil {
+			return "", "", -1, CronRunFailed, fmt.Errorf("用户 %s 不存在", task.User)
+		}
+		uid, _ := strconv.ParseUint(u.Uid, 10, 32)
+		gid, _ := strconv.ParseUint(u.Gid, 10, 32)
+		cmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(uid), Gid: uint32(gid)}
+		env = append(env, "HOME="+u.HomeDir, "USER="+u.Username, "LOGNAME="+u.Username)
+		if dir == "" {
+			dir = u.HomeDir
+		}
+	}
+	for key, value := range task.Env {
+		env = append(env, key+"="+value)
+	}
+	cmd.Env = env
+	cmd.Dir = dir
+
+	err := cmd.Run()
+	if killTimer != nil {
+		killTimer.Stop()
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return stdout.String(), stderr.String(), -1, CronRunTimeout, fmt.Errorf("执行超过 %d 秒，已终止", task.Timeout)
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case err == nil:
+		return stdout.String(), stderr.String(), 0, CronRunSuccess, nil
+	case errors.As(err, &exitErr):
+		return stdout.String(), stderr.String(), exitErr.ExitCode(), CronRunFailed, nil
+	default:
+		return stdout.String(), stderr.String(), -1, CronRunFailed, err
+	}
+}
@@ -0,0 +1,266 @@
+package cron_util
+
+import (
+	"errors"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestRetryDelay 测试重试间隔每次翻倍，且不超过上限
+func TestRetryDelay(t *testing.T) {
+	cases := []struct {
+		retryDelay int
+		attempt    int
+		want       time.Duration
+	}{
+		{0, 1, DefaultRetryDelay},
+		{0, 2, 2 * DefaultRetryDelay},
+		{0, 3, 4 * DefaultRetryDelay},
+		{5, 1, 5 * time.Second},
+		{5, 4, 40 * time.Second},
+		{60, 10, maxRetryDelay},
+		{7200, 1, maxRetryDelay},
+	}
+	for _, c := range cases {
+		if got := retryDelay(CronTask{RetryDelay: c.retryDelay}, c.attempt); got != c.want {
+			t.Errorf("retryDelay(%d, %d) = %v, want %v", c.retryDelay, c.attempt, got, c.want)
+		}
+	}
+}
+
+// TestTailBuffer 测试输出超出上限时只保留末尾
+func TestTailBuffer(t *testing.T) {
+	cases := []struct {
+		writes []string
+		want   string
+	}{
+		{[]string{"abc"}, "abc"},
+		{[]string{"abc", "de"}, "abcde"},
+		{[]string{"abcdef"}, "...（输出过长，只保留最后部分）\nbcdef"},
+		{[]string{"abc", "def", "g"}, "...（输出过长，只保留最后部分）\ncdefg"},
+	}
+	for _, c := range cases {
+		b := &tailBuffer{limit: 5}
+		for _, w := range c.writes {
+			if n, err := b.Write([]byte(w)); n != len(w) || err != nil {
+				t.Errorf("Write(%q) = %d, %v", w, n, err)
+			}
+		}
+		if got := b.String(); got != c.want {
+			t.Errorf("%v: expected %q, got %q", c.writes, c.want, got)
+		}
+	}
+}
+
+// processAlive 判断进程是否仍在运行，僵尸进程视为已退出
+func processAlive(pid int) bool {
+	data, err := os.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return false
+	}
+	fields := strings.Fields(string(data[strings.LastIndex(string(data), ")")+1:]))
+	return len(fields) > 0 && fields[0] != "Z"
+}
+
+// expectProcessGone 等待命令写入 pidFile 的子进程退出
+func expectProcessGone(t *testing.T, pidFile string) {
+	t.Helper()
+	data, err := os.ReadFile(pidFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, _ := strconv.Atoi(strings.TrimSpace(string(data)))
+	for deadline := time.Now().Add(3 * time.Second); time.Now().Before(deadline); time.Sleep(50 * time.Millisecond) {
+		if !processAlive(pid) {
+			return
+		}
+	}
+	t.Errorf("Expected child process %d to be killed", pid)
+}
+
+// TestRunCommandTimeout 测试超时后整个进程组被终止，忽略 SIGTERM 的进程在宽限期后被 SIGKILL
+func TestRunCommandTimeout(t *testing.T) {
+	defer func(grace time.Duration) { killGracePeriod = grace }(killGracePeriod)
+	killGracePeriod = 200 * time.Millisecond
+
+	cases := []struct {
+		name    string
+		command string
+	}{
+		{"sigterm", "sleep 30 & echo $! > pid; wait"},
+		{"sigkill", "trap '' TERM; sleep 30 & echo $! > pid; wait; wait"},
+	}
+	for _, c := range cases {
+		dir := t.TempDir()
+		start := time.Now()
+		_, _, exitCode, status, err := runCommand(CronTask{Command: c.command, WorkDir: dir, Timeout: 1})
+		if status != CronRunTimeout || exitCode != -1 || err == nil {
+			t.Errorf("%s: expected timeout, got %s %d %v", c.name, status, exitCode, err)
+		}
+		if elapsed := time.Since(start); elapsed > 5*time.Second {
+			t.Errorf("%s: expected command to be killed soon after timeout, took %v", c.name, elapsed)
+		}
+		expectProcessGone(t, filepath.Join(dir, "pid"))
+	}
+}
+
+// TestRunCommand 测试退出码、工作目录、环境变量和运行用户
+func TestRunCommand(t *testing.T) {
+	dir := t.TempDir()
+	stdout, stderr, exitCode, status, err := runCommand(CronTask{
+		Command: `pwd; echo "$GREETING"; echo oops >&2; exit 3`,
+		WorkDir: dir,
+		Env:     map[string]string{"GREETING": "hello"},
+	})
+	if status != CronRunFailed || exitCode != 3 || err != nil {
+		t.Errorf("Expected exit code 3, got %s %d %v", status, exitCode, err)
+	}
+	if stdout != dir+"\nhello\n" || stderr != "oops\n" {
+		t.Errorf("Unexpected output %q %q", stdout, stderr)
+	}
+
+	if _, _, _, status, err := runCommand(CronTask{Command: "true", User: "servon-missing-user"}); status != CronRunFailed || err == nil {
+		t.Errorf("Expected missing user to fail, got %s %v", status, err)
+	}
+
+	if os.Geteuid() != 0 {
+		t.Skip("running as another user requires root")
+	}
+	nobody, err := user.Lookup("nobody")
+	if err != nil {
+		t.Skip("user nobody not found")
+	}
+	stdout, _, _, status, err = runCommand(CronTask{Command: `id -u; echo "$USER"`, User: "nobody", WorkDir: "/"})
+	if status != CronRunSuccess || stdout != nobody.Uid+"\nnobody\n" {
+		t.Errorf("Expected command to run as nobody, got %s %q %v", status, stdout, err)
+	}
+}
+
+// newTestCronManager 创建只保存在内存中的任务管理器，并加入一个任务
+func newTestCronManager(t *testing.T, task CronTask) (*CronTaskManager, *CronTask) {
+	m, err := NewCronTaskManager("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	task.ID = 1
+	task.Name = "test"
+	m.tasks[task.ID] = &task
+	return m, m.tasks[task.ID]
+}
+
+// TestRunHistory 测试执行记录只保留最近的 maxRuns 条
+func TestRunHistory(t *testing.T) {
+	m, task := newTestCronManager(t, CronTask{Command: "true"})
+	m.maxRuns = 3
+	for i := 0; i < 4; i++ {
+		m.executeTask(task)
+	}
+	runs, _ := m.GetRuns(task.ID)
+	if len(runs) != 3 {
+		t.Fatalf("Expected history to be trimmed to 3, got %d", len(runs))
+	}
+	for i, run := range runs {
+		if run.Status != CronRunSuccess || (i > 0 && run.ID != runs[i-1].ID-1) {
+			t.Errorf("Expected the latest successful runs, got %+v", runs)
+			break
+		}
+	}
+	if task.LastStatus != CronRunSuccess {
+		t.Errorf("Expected task status success, got %s", task.LastStatus)
+	}
+}
+
+// waitRuns 等待任务的所有执行结束，返回执行记录，最新的在前
+func waitRuns(t *testing.T, m *CronTaskManager, id, count int) []CronRun {
+	t.Helper()
+	for deadline := time.Now().Add(10 * time.Second); time.Now().Before(deadline); time.Sleep(20 * time.Millisecond) {
+		runs, _ := m.GetRuns(id)
+		done := len(runs) == count
+		for _, run := range runs {
+			done = done && run.Status != CronRunRunning && run.Status != CronRunQueued
+		}
+		if done {
+			return runs
+		}
+	}
+	runs, _ := m.GetRuns(id)
+	t.Fatalf("Timed out waiting for %d runs, got %+v", count, runs)
+	return nil
+}
+
+// TestConcurrencySkip 测试上一次执行未结束时，手动执行被拒绝，定时执行记为跳过
+func TestConcurrencySkip(t *testing.T) {
+	m, task := newTestCronManager(t, CronTask{Command: "sleep 0.5", Concurrency: CronConcurrencySkip})
+
+	first, err := m.RunTask(task.ID)
+	if err != nil || first.Status != CronRunRunning {
+		t.Fatalf("Expected first run to start, got %+v, %v", first, err)
+	}
+	if _, err := m.RunTask(task.ID); !errors.Is(err, ErrTaskRunning) {
+		t.Errorf("Expected ErrTaskRunning, got %v", err)
+	}
+	m.executeTask(task)
+
+	runs := waitRuns(t, m, task.ID, 2)
+	skipped := runs[0]
+	if skipped.Status != CronRunSkipped || skipped.Trigger != CronTriggerSchedule || !skipped.FinishedAt.Equal(skipped.StartedAt) {
+		t.Errorf("Expected a finished skipped record, got %+v", skipped)
+	}
+	if runs[1].ID != first.ID || runs[1].Status != CronRunSuccess {
+		t.Errorf("Expected first run to succeed, got %+v", runs[1])
+	}
+
+	// 上一次执行结束后可以再次执行
+	if _, err := m.RunTask(task.ID); err != nil {
+		t.Errorf("Expected run after the previous one finished, got %v", err)
+	}
+	waitRuns(t, m, task.ID, 3)
+}
+
+// TestConcurrencyQueue 测试排队的执行依次进行，不会重叠
+func TestConcurrencyQueue(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "log")
+	m, task := newTestCronManager(t, CronTask{
+		Command:     "echo start >> " + log + "; sleep 0.2; echo end >> " + log,
+		Concurrency: CronConcurrencyQueue,
+	})
+
+	for i := 0; i < 3; i++ {
+		run, err := m.RunTask(task.ID)
+		if err != nil || run.Status != CronRunQueued {
+			t.Fatalf("Expected run to be queued, got %+v, %v", run, err)
+		}
+	}
+
+	for _, run := range waitRuns(t, m, task.ID, 3) {
+		if run.Status != CronRunSuccess {
+			t.Errorf("Expected queued run to succeed, got %+v", run)
+		}
+	}
+	data, _ := os.ReadFile(log)
+	if got := strings.Fields(string(data)); strings.Join(got, " ") != "start end start end start end" {
+		t.Errorf("Expected runs to be serialized, got %v", got)
+	}
+}
+
+// TestRetry 测试失败后按配置的次数重试，每次重试单独记录
+func TestRetry(t *testing.T) {
+	m, task := newTestCronManager(t, CronTask{Command: "exit 2", Retries: 1, RetryDelay: 1})
+
+	m.executeTask(task)
+	runs, _ := m.GetRuns(task.ID)
+	if len(runs) != 2 {
+		t.Fatalf("Expected 2 attempts, got %+v", runs)
+	}
+	if runs[0].Trigger != CronTriggerRetry || runs[0].Attempt != 2 || runs[0].ExitCode != 2 || runs[1].Attempt != 1 {
+		t.Errorf("Unexpected retry records: %+v", runs)
+	}
+	if task.LastStatus != CronRunFailed {
+		t.Errorf("Expected task status failed, got %s", task.LastStatus)
+	}
+}
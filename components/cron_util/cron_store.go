@@ -0,0 +1,104 @@
+package cron_util
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// cronStore 定时任务在磁盘上的存储格式
+type cronStore struct {
+	LastID int         `json:"last_id"`
+	Tasks  []*CronTask `json:"tasks"`
+}
+
+// load 从存储文件读取任务，并把已启用的任务注册到调度器
+// 文件不存在时视为没有任务；无法解析时把文件备份为 .bak，避免之后的写入覆盖原有内容
+func (m *CronTaskManager) load() error {
+	if m.storePath == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(m.storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("读取定时任务失败: %v", err)
+	}
+
+	var store cronStore
+	if err := json.Unmarshal(data, &store); err != nil {
+		backup := m.storePath + ".bak"
+		os.Rename(m.storePath, backup)
+		return fmt.Errorf("解析定时任务失败，已备份为 %s: %v", backup, err)
+	}
+
+	m.tasksMutex.Lock()
+	defer m.tasksMutex.Unlock()
+
+	m.lastID = store.LastID
+	for _, task := range store.Tasks {
+		if task == nil {
+			continue
+		}
+		task.NextRun = time.Time{}
+		if task.ID > m.lastID {
+			m.lastID = task.ID
+		}
+		// 上次退出时仍在执行的任务不会再有结果
+		if task.LastStatus == CronRunRunning {
+			task.LastStatus = ""
+		}
+		if task.Enabled {
+			if err := m.schedule(task); err != nil {
+				fmt.Printf("注册定时任务 %s 失败，已禁用: %v\n", task.Name, err)
+				task.Enabled = false
+			}
+		}
+		m.tasks[task.ID] = task
+	}
+	return nil
+}
+
+// save 把所有任务写入存储文件，调用方需持有 tasksMutex
+// 先写临时文件再重命名，避免中途失败时留下写了一半的文件
+func (m *CronTaskManager) save() error {
+	if m.storePath == "" {
+		return nil
+	}
+
+	store := cronStore{
+		LastID: m.lastID,
+		Tasks:  make([]*CronTask, 0, len(m.tasks)),
+	}
+	for _, task := range m.tasks {
+		store.Tasks = append(store.Tasks, task)
+	}
+	sort.Slice(store.Tasks, func(i, j int) bool {
+		return store.Tasks[i].ID < store.Tasks[j].ID
+	})
+
+	data, err := json.MarshalIndent(store, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化定时任务失败: %v", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(m.storePath), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	// 任务的环境变量中可能包含密钥，只允许所有者读写
+	tmpPath := m.storePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("保存定时任务失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, m.storePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("保存定时任务失败: %v", err)
+	}
+	return nil
+}
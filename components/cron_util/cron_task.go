@@ -2,12 +2,22 @@ package cron_util
 
 import (
 	"fmt"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/robfig/cron/v3"
 )
 
+// envKeyPattern 环境变量名的格式
+var envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// scheduleParser 与调度器相同的定时表达式解析器，第一位为秒
+var scheduleParser = cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
 // ValidationError 表示字段验证错误
 type ValidationError struct {
 	Field   string `json:"field"`
@@ -32,35 +42,93 @@ type CronTaskManager struct {
 	tasks        map[int]*CronTask
 	tasksMutex   sync.RWMutex
 	lastID       int
+	runs         map[int][]*CronRun // 任务ID -> 执行记录，按时间顺序排列
+	runsMutex    sync.Mutex
+	lastRunID    int
+	locks        map[int]*sync.Mutex // 任务ID -> 执行锁，由 runsMutex 保护，删除任务时保留以免执行中的任务解锁失败
+	maxRuns      int                 // 每个任务保留的执行记录数量
+	storePath    string              // 任务的存储文件，为空时只保存在内存中
+	startOnce    sync.Once
 }
 
-// NewCronTaskManager 创建一个新的CronTaskManager实例
-func NewCronTaskManager() *CronTaskManager {
+// NewCronTaskManager 创建一个新的CronTaskManager实例，并从 storePath 加载已保存的任务
+// 任务在调用 Start 之后才会按定时表达式执行
+func NewCronTaskManager(storePath string) (*CronTaskManager, error) {
 	manager := &CronTaskManager{
 		cronInstance: cron.New(cron.WithSeconds()),
 		tasks:        make(map[int]*CronTask),
 		lastID:       0,
+		runs:         make(map[int][]*CronRun),
+		locks:        make(map[int]*sync.Mutex),
+		maxRuns:      DefaultMaxRuns,
+		storePath:    storePath,
+	}
+	return manager, manager.load()
+}
+
+// Start 启动调度器，重复调用只启动一次
+func (m *CronTaskManager) Start() {
+	m.startOnce.Do(m.cronInstance.Start)
+}
+
+// schedule 把任务注册到调度器，调用方需持有 tasksMutex
+func (m *CronTaskManager) schedule(task *CronTask) error {
+	entryID, err := m.cronInstance.AddFunc(task.spec(), func() {
+		m.executeTask(task)
+	})
+	if err != nil {
+		return err
 	}
-	manager.cronInstance.Start()
-	return manager
+	task.entryID = entryID
+	return nil
 }
 
-// GetTasks 获取所有定时任务
+// GetTasks 获取所有定时任务，按ID排序
+// 返回的是任务的副本，执行中的任务更新状态时不会影响调用方
 func (m *CronTaskManager) GetTasks() []*CronTask {
 	m.tasksMutex.RLock()
 	defer m.tasksMutex.RUnlock()
 
 	result := make([]*CronTask, 0, len(m.tasks))
 	for _, task := range m.tasks {
+		snapshot := *task
 		if task.Enabled {
 			entry := m.cronInstance.Entry(task.entryID)
-			task.NextRun = entry.Next
+			snapshot.NextRun = entry.Next
 		}
-		result = append(result, task)
+		result = append(result, &snapshot)
 	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ID < result[j].ID
+	})
 	return result
 }
 
+// NextRuns 计算定时表达式在 timezone 时区中之后的 n 次执行时间，timezone 为空时使用服务器时区
+func NextRuns(schedule, timezone string, n int) ([]time.Time, error) {
+	task := CronTask{Schedule: schedule, Timezone: timezone}
+	if timezone != "" {
+		if _, err := time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("无效的时区: %s", timezone)
+		}
+	}
+	sched, err := scheduleParser.Parse(task.spec())
+	if err != nil {
+		return nil, fmt.Errorf("无效的定时表达式: %v", err)
+	}
+
+	result := make([]time.Time, 0, n)
+	next := time.Now()
+	for i := 0; i < n; i++ {
+		next = sched.Next(next)
+		if next.IsZero() {
+			break
+		}
+		result = append(result, next)
+	}
+	return result, nil
+}
+
 // validateTask 验证任务的各个字段
 func (m *CronTaskManager) validateTask(task CronTask) error {
 	var errors []ValidationError
@@ -85,8 +153,7 @@ func (m *CronTaskManager) validateTask(task CronTask) error {
 			Message: "定时表达式不能为空",
 		})
 	} else {
-		parser := cron.NewParser(cron.Second | cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow)
-		if _, err := parser.Parse(task.Schedule); err != nil {
+		if _, err := scheduleParser.Parse(task.Schedule); err != nil {
 			errors = append(errors, ValidationError{
 				Field:   "schedule",
 				Message: "无效的定时表达式: " + err.Error(),
@@ -94,6 +161,77 @@ func (m *CronTaskManager) validateTask(task CronTask) error {
 		}
 	}
 
+	if task.WorkDir != "" && !filepath.IsAbs(task.WorkDir) {
+		errors = append(errors, ValidationError{
+			Field:   "work_dir",
+			Message: "工作目录必须是绝对路径",
+		})
+	}
+
+	if task.User != "" {
+		if _, err := user.Lookup(task.User); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "user",
+				Message: fmt.Sprintf("用户 %s 不存在", task.User),
+			})
+		}
+	}
+
+	for key := range task.Env {
+		if !envKeyPattern.MatchString(key) {
+			errors = append(errors, ValidationError{
+				Field:   "env",
+				Message: fmt.Sprintf("无效的环境变量名: %s", key),
+			})
+		}
+	}
+
+	if task.Timeout < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "timeout",
+			Message: "超时时间不能小于 0",
+		})
+	}
+
+	switch task.Concurrency {
+	case "", CronConcurrencyAllow, CronConcurrencySkip, CronConcurrencyQueue:
+	default:
+		errors = append(errors, ValidationError{
+			Field:   "concurrency",
+			Message: fmt.Sprintf("无效的并发策略: %s，可选值: allow、skip、queue", task.Concurrency),
+		})
+	}
+
+	if task.Timezone != "" {
+		if _, err := time.LoadLocation(task.Timezone); err != nil {
+			errors = append(errors, ValidationError{
+				Field:   "timezone",
+				Message: fmt.Sprintf("无效的时区: %s", task.Timezone),
+			})
+		}
+	}
+
+	if task.Jitter < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "jitter",
+			Message: "随机延迟不能小于 0",
+		})
+	}
+
+	if task.Retries < 0 || task.Retries > MaxRetries {
+		errors = append(errors, ValidationError{
+			Field:   "retries",
+			Message: fmt.Sprintf("重试次数必须在 0 到 %d 之间", MaxRetries),
+		})
+	}
+
+	if task.RetryDelay < 0 {
+		errors = append(errors, ValidationError{
+			Field:   "retry_delay",
+			Message: "重试间隔不能小于 0",
+		})
+	}
+
 	if len(errors) > 0 {
 		return ValidationErrors{Errors: errors}
 	}
@@ -112,11 +250,11 @@ func (m *CronTaskManager) CreateTask(task CronTask) (*CronTask, error) {
 	m.lastID++
 	task.ID = m.lastID
 	task.Enabled = true
+	task.LastRun = time.Time{}
+	task.LastStatus = ""
 
-	entryID, err := m.cronInstance.AddFunc(task.Schedule, func() {
-		m.executeTask(&task)
-	})
-	if err != nil {
+	if err := m.schedule(&task); err != nil {
+		m.lastID--
 		return nil, ValidationErrors{
 			Errors: []ValidationError{
 				{
@@ -126,10 +264,15 @@ func (m *CronTaskManager) CreateTask(task CronTask) (*CronTask, error) {
 			},
 		}
 	}
-
-	task.entryID = entryID
 	m.tasks[task.ID] = &task
 
+	if err := m.save(); err != nil {
+		m.cronInstance.Remove(task.entryID)
+		delete(m.tasks, task.ID)
+		m.lastID--
+		return nil, err
+	}
+
 	return &task, nil
 }
 
@@ -147,24 +290,42 @@ func (m *CronTaskManager) UpdateTask(task CronTask) (*CronTask, error) {
 		return nil, fmt.Errorf("任务不存在")
 	}
 
+	// 执行状态不属于可编辑的字段，保留原任务的记录
+	task.LastRun = existingTask.LastRun
+	task.LastStatus = existingTask.LastStatus
+
 	if existingTask.Enabled {
 		m.cronInstance.Remove(existingTask.entryID)
 	}
-
 	if task.Enabled {
-		entryID, err := m.cronInstance.AddFunc(task.Schedule, func() {
-			m.executeTask(&task)
-		})
-		if err != nil {
+		if err := m.schedule(&task); err != nil {
+			m.restore(existingTask)
 			return nil, fmt.Errorf("添加任务失败: %v", err)
 		}
-		task.entryID = entryID
 	}
-
 	m.tasks[task.ID] = &task
+
+	if err := m.save(); err != nil {
+		if task.Enabled {
+			m.cronInstance.Remove(task.entryID)
+		}
+		m.restore(existingTask)
+		return nil, err
+	}
+
 	return &task, nil
 }
 
+// restore 在修改失败时恢复任务原来的状态，调用方需持有 tasksMutex
+func (m *CronTaskManager) restore(task *CronTask) {
+	m.tasks[task.ID] = task
+	if task.Enabled {
+		if err := m.schedule(task); err != nil {
+			task.Enabled = false
+		}
+	}
+}
+
 // DeleteTask 删除定时任务
 func (m *CronTaskManager) DeleteTask(id int) error {
 	m.tasksMutex.Lock()
@@ -180,6 +341,15 @@ func (m *CronTaskManager) DeleteTask(id int) error {
 	}
 
 	delete(m.tasks, id)
+
+	if err := m.save(); err != nil {
+		m.restore(task)
+		return err
+	}
+
+	m.runsMutex.Lock()
+	delete(m.runs, id)
+	m.runsMutex.Unlock()
 	return nil
 }
 
@@ -197,26 +367,21 @@ func (m *CronTaskManager) ToggleTask(id int) (*CronTask, error) {
 		m.cronInstance.Remove(task.entryID)
 		task.Enabled = false
 	} else {
-		entryID, err := m.cronInstance.AddFunc(task.Schedule, func() {
-			m.executeTask(task)
-		})
-		if err != nil {
+		if err := m.schedule(task); err != nil {
 			return nil, err
 		}
-		task.entryID = entryID
 		task.Enabled = true
 	}
 
-	return task, nil
-}
-
-// executeTask 执行定时任务
-func (m *CronTaskManager) executeTask(task *CronTask) {
-	m.tasksMutex.Lock()
-	task.LastRun = time.Now()
-	m.tasksMutex.Unlock()
+	if err := m.save(); err != nil {
+		if task.Enabled {
+			m.cronInstance.Remove(task.entryID)
+		}
+		task.Enabled = !task.Enabled
+		m.restore(task)
+		return nil, err
+	}
 
-	// 执行命令
-	// TODO: 根据实际需求实现命令执行逻辑
-	fmt.Printf("执行任务 %s: %s\n", task.Name, task.Command)
+	snapshot := *task
+	return &snapshot, nil
 }
@@ -8,13 +8,71 @@ import (
 
 // CronTask 定义了一个定时任务的结构
 type CronTask struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Command     string    `json:"command"`
-	Schedule    string    `json:"schedule"`
-	Description string    `json:"description"`
-	Enabled     bool      `json:"enabled"`
-	LastRun     time.Time `json:"last_run,omitempty"`
-	NextRun     time.Time `json:"next_run,omitempty"`
+	ID          int               `json:"id"`
+	Name        string            `json:"name"`
+	Command     string            `json:"command"`
+	Schedule    string            `json:"schedule"`
+	Description string            `json:"description"`
+	Enabled     bool              `json:"enabled"`
+	WorkDir     string            `json:"work_dir,omitempty"`    // 执行命令的工作目录，为空时使用运行用户的主目录
+	User        string            `json:"user,omitempty"`        // 以该用户身份执行，为空时使用 Servon 的运行用户
+	Env         map[string]string `json:"env,omitempty"`         // 额外的环境变量
+	Timeout     int               `json:"timeout,omitempty"`     // 最长执行时间（秒），超时后终止命令，0 表示不限制
+	Concurrency string            `json:"concurrency,omitempty"` // 上一次执行未结束时的处理方式，见 CronConcurrency* 常量，为空时同 allow
+	Timezone    string            `json:"timezone,omitempty"`    // 定时表达式使用的时区，如 Asia/Shanghai，为空时使用服务器时区
+	Jitter      int               `json:"jitter,omitempty"`      // 按定时表达式触发后随机延迟的最长时间（秒），用于错开同一时刻的任务
+	Retries     int               `json:"retries,omitempty"`     // 执行失败或超时后的重试次数
+	RetryDelay  int               `json:"retry_delay,omitempty"` // 第一次重试前等待的时间（秒），之后每次翻倍，为 0 时使用 DefaultRetryDelay
+	LastRun     time.Time         `json:"last_run,omitempty"`
+	LastStatus  string            `json:"last_status,omitempty"` // 最近一次执行的状态
+	NextRun     time.Time         `json:"next_run,omitempty"`
 	entryID     cron.EntryID
 }
+
+// spec 返回注册到调度器的定时表达式，设置了时区时加上 CRON_TZ 前缀
+func (t CronTask) spec() string {
+	if t.Timezone == "" {
+		return t.Schedule
+	}
+	return "CRON_TZ=" + t.Timezone + " " + t.Schedule
+}
+
+// 上一次执行未结束时的处理方式
+const (
+	CronConcurrencyAllow = "allow" // 同时执行
+	CronConcurrencySkip  = "skip"  // 跳过本次执行
+	CronConcurrencyQueue = "queue" // 等待上一次执行结束后再执行
+)
+
+// 任务执行的状态
+const (
+	CronRunQueued  = "queued"  // 等待上一次执行结束
+	CronRunRunning = "running" // 执行中
+	CronRunSuccess = "success" // 退出码为 0
+	CronRunFailed  = "failed"  // 退出码非 0 或无法启动
+	CronRunTimeout = "timeout" // 超时被终止
+	CronRunSkipped = "skipped" // 上一次执行未结束，已跳过
+)
+
+// 任务执行的触发方式
+const (
+	CronTriggerSchedule = "schedule" // 按定时表达式触发
+	CronTriggerManual   = "manual"   // 手动立即执行
+	CronTriggerRetry    = "retry"    // 失败后重试
+)
+
+// CronRun 任务的一次执行记录
+type CronRun struct {
+	ID         int       `json:"id"`
+	TaskID     int       `json:"task_id"`
+	Trigger    string    `json:"trigger"`
+	Attempt    int       `json:"attempt"` // 第几次尝试，从 1 开始，重试时递增
+	Status     string    `json:"status"`
+	ExitCode   int       `json:"exit_code"`
+	Stdout     string    `json:"stdout"`
+	Stderr     string    `json:"stderr"`
+	Error      string    `json:"error,omitempty"` // 无法启动命令等非退出码的错误
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at,omitempty"`
+	Duration   float64   `json:"duration"` // 执行耗时（秒）
+}
@@ -0,0 +1,322 @@
+package cron_util
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// crontabNameLength 导入的任务名称取命令的前若干个字符
+const crontabNameLength = 40
+
+// IsSystemCrontab 判断文件是否为带用户列的系统 crontab，如 /etc/crontab 和 /etc/cron.d 中的文件
+func IsSystemCrontab(path string) bool {
+	path = filepath.Clean(path)
+	return path == "/etc/crontab" || filepath.Dir(path) == "/etc/cron.d"
+}
+
+// ParseCrontab 把 crontab 内容转换为定时任务，返回任务和无法导入的行的说明
+// system 为 true 时按系统 crontab 解析，第六列为执行用户
+// 5 位的定时表达式会在前面补上秒，文件中的环境变量会附加到之后的每个任务，CRON_TZ 转换为任务的时区，
+// TZ 只影响命令的运行环境，与 cron 一样作为环境变量保留
+func ParseCrontab(content, source string, system bool) ([]CronTask, []string) {
+	var tasks []CronTask
+	var warnings []string
+	env := map[string]string{}
+	timezone := ""
+
+	for i, raw := range strings.Split(content, "\n") {
+		lineNo := i + 1
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if key, value, ok := parseCrontabEnv(line); ok {
+			switch key {
+			case "CRON_TZ":
+				timezone = value
+			case "MAILTO", "MAILFROM", "SHELL":
+				warnings = append(warnings, fmt.Sprintf("第 %d 行: 不支持 %s，已忽略", lineNo, key))
+			default:
+				env[key] = value
+			}
+			continue
+		}
+
+		task, err := parseCrontabLine(line, system)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("第 %d 行: %v", lineNo, err))
+			continue
+		}
+
+		task.Timezone = timezone
+		if len(env) > 0 {
+			task.Env = make(map[string]string, len(env))
+			for key, value := range env {
+				task.Env[key] = value
+			}
+		}
+		task.Description = fmt.Sprintf("从 %s 第 %d 行导入", source, lineNo)
+		task.Enabled = true
+		tasks = append(tasks, task)
+	}
+
+	return tasks, warnings
+}
+
+// parseCrontabEnv 解析 NAME=value 形式的环境变量行，值两端的引号会被去掉
+func parseCrontabEnv(line string) (string, string, bool) {
+	key, value, ok := strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+	key = strings.TrimSpace(key)
+	if !envKeyPattern.MatchString(key) {
+		return "", "", false
+	}
+
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return key, value, true
+}
+
+// parseCrontabLine 解析一行任务
+func parseCrontabLine(line string, system bool) (CronTask, error) {
+	var task CronTask
+
+	descriptor := strings.HasPrefix(line, "@")
+	fieldCount := 5
+	if descriptor {
+		fieldCount = 1
+	}
+	if system {
+		fieldCount++
+	}
+
+	fields, rest := splitFields(line, fieldCount)
+	if len(fields) < fieldCount || rest == "" {
+		return task, fmt.Errorf("格式不正确")
+	}
+
+	if descriptor {
+		if fields[0] == "@reboot" {
+			return task, fmt.Errorf("不支持 @reboot")
+		}
+		task.Schedule = fields[0]
+	} else {
+		schedule := fields[:5]
+		schedule[4] = normalizeDow(schedule[4])
+		task.Schedule = "0 " + strings.Join(schedule, " ")
+	}
+	if system {
+		task.User = fields[len(fields)-1]
+	}
+
+	command, err := unescapeCrontabCommand(rest)
+	if err != nil {
+		return task, err
+	}
+	task.Command = command
+	task.Name = crontabTaskName(command)
+
+	if _, err := scheduleParser.Parse(task.Schedule); err != nil {
+		return task, fmt.Errorf("无法转换定时表达式 %s: %v", task.Schedule, err)
+	}
+	return task, nil
+}
+
+// splitFields 从行首取出 n 个以空白分隔的字段，返回字段和剩余部分
+func splitFields(line string, n int) ([]string, string) {
+	fields := make([]string, 0, n)
+	rest := line
+	for len(fields) < n {
+		rest = strings.TrimLeft(rest, " \t")
+		if rest == "" {
+			break
+		}
+		end := strings.IndexAny(rest, " \t")
+		if end < 0 {
+			fields = append(fields, rest)
+			rest = ""
+			break
+		}
+		fields = append(fields, rest[:end])
+		rest = rest[end:]
+	}
+	return fields, strings.TrimSpace(rest)
+}
+
+// normalizeDow 把星期字段中表示周日的 7 转换为 0，调度器只接受 0-6
+func normalizeDow(field string) string {
+	items := strings.Split(field, ",")
+	for i, item := range items {
+		switch {
+		case item == "7":
+			items[i] = "0"
+		case strings.HasSuffix(item, "-7") && !strings.Contains(item, "/"):
+			start := strings.TrimSuffix(item, "-7")
+			if start == "7" {
+				items[i] = "0"
+			} else if start == "6" {
+				items[i] = "6,0"
+			} else {
+				items[i] = start + "-6,0"
+			}
+		}
+	}
+	return strings.Join(items, ",")
+}
+
+// unescapeCrontabCommand 处理命令中的 %，crontab 中未转义的 % 表示换行和标准输入，无法转换
+func unescapeCrontabCommand(command string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(command); i++ {
+		switch {
+		case command[i] == '\\' && i+1 < len(command) && command[i+1] == '%':
+			b.WriteByte('%')
+			i++
+		case command[i] == '%':
+			return "", fmt.Errorf("命令中包含未转义的 %%（作为标准输入），不支持导入")
+		default:
+			b.WriteByte(command[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// crontabTaskName 根据命令生成任务名称
+func crontabTaskName(command string) string {
+	name := []rune(strings.Join(strings.Fields(command), " "))
+	if len(name) > crontabNameLength {
+		return string(name[:crontabNameLength]) + "..."
+	}
+	return string(name)
+}
+
+// FormatCrontab 把任务转换为 crontab 格式，system 为 true 时输出带用户列的系统 crontab 格式
+// 秒不为 0 的定时表达式、@every 等 crontab 无法表示的任务会以注释的形式输出
+// 工作目录和环境变量写入命令中，禁用的任务会被注释掉
+func FormatCrontab(tasks []*CronTask, system bool) string {
+	sorted := make([]*CronTask, len(tasks))
+	copy(sorted, tasks)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	var b strings.Builder
+	b.WriteString("# 由 Servon 导出\n")
+
+	timezone := ""
+	for _, task := range sorted {
+		b.WriteString("\n# " + task.Name)
+		if task.Description != "" {
+			b.WriteString(": " + task.Description)
+		}
+		b.WriteString("\n")
+
+		if notes := unsupportedOptions(task, system); len(notes) > 0 {
+			b.WriteString("# crontab 不支持的配置: " + strings.Join(notes, ", ") + "\n")
+		}
+
+		schedule, err := crontabSchedule(task.Schedule)
+		if err != nil {
+			fmt.Fprintf(&b, "# 无法转换: %v\n# %s %s\n", err, task.Schedule, task.Command)
+			continue
+		}
+
+		if task.Timezone != timezone {
+			b.WriteString("CRON_TZ=" + task.Timezone + "\n")
+			timezone = task.Timezone
+		}
+
+		line := schedule
+		if system {
+			user := task.User
+			if user == "" {
+				user = "root"
+			}
+			line += " " + user
+		}
+		line += " " + crontabCommand(task)
+		if !task.Enabled {
+			line = "# " + line
+		}
+		b.WriteString(line + "\n")
+	}
+
+	return b.String()
+}
+
+// crontabSchedule 把带秒的定时表达式转换为 5 位的 crontab 格式，秒必须为 0
+func crontabSchedule(schedule string) (string, error) {
+	if strings.HasPrefix(schedule, "@") {
+		if strings.HasPrefix(schedule, "@every") {
+			return "", fmt.Errorf("crontab 不支持 @every")
+		}
+		return schedule, nil
+	}
+
+	fields := strings.Fields(schedule)
+	if len(fields) != 6 {
+		return "", fmt.Errorf("定时表达式应为 6 位")
+	}
+	if second, err := strconv.Atoi(fields[0]); err != nil || second != 0 {
+		return "", fmt.Errorf("crontab 的最小单位为分钟，秒必须为 0")
+	}
+	return strings.Join(fields[1:], " "), nil
+}
+
+// crontabCommand 生成任务在 crontab 中的命令，工作目录和环境变量写入命令中，% 需要转义
+func crontabCommand(task *CronTask) string {
+	var parts []string
+	if task.WorkDir != "" {
+		parts = append(parts, "cd "+shellQuote(task.WorkDir)+" || exit 1")
+	}
+	if len(task.Env) > 0 {
+		keys := make([]string, 0, len(task.Env))
+		for key := range task.Env {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		assignments := make([]string, 0, len(keys))
+		for _, key := range keys {
+			assignments = append(assignments, key+"="+shellQuote(task.Env[key]))
+		}
+		parts = append(parts, "export "+strings.Join(assignments, " "))
+	}
+	parts = append(parts, task.Command)
+
+	return strings.ReplaceAll(strings.Join(parts, "; "), "%", "\\%")
+}
+
+// unsupportedOptions 返回任务中 crontab 无法表示的配置，用户 crontab 中无法指定执行用户
+func unsupportedOptions(task *CronTask, system bool) []string {
+	var notes []string
+	if !system && task.User != "" {
+		notes = append(notes, "user="+task.User)
+	}
+	if task.Timeout > 0 {
+		notes = append(notes, fmt.Sprintf("timeout=%d", task.Timeout))
+	}
+	if task.Concurrency != "" && task.Concurrency != CronConcurrencyAllow {
+		notes = append(notes, "concurrency="+task.Concurrency)
+	}
+	if task.Jitter > 0 {
+		notes = append(notes, fmt.Sprintf("jitter=%d", task.Jitter))
+	}
+	if task.Retries > 0 {
+		notes = append(notes, fmt.Sprintf("retries=%d", task.Retries))
+	}
+	return notes
+}
+
+// shellQuote 用单引号包裹字符串，供 sh 解析
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
@@ -0,0 +1,130 @@
+package cron_util
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestParseCrontab 测试用户 crontab 的解析
+func TestParseCrontab(t *testing.T) {
+	content := `# 备份
+PATH=/usr/local/bin:/usr/bin:/bin
+MAILTO=ops@example.com
+CRON_TZ=Asia/Shanghai
+
+30 3 * * 1-7 /opt/backup.sh --date=$(date +\%F)
+@hourly   curl -s http://127.0.0.1/ping
+@reboot /opt/start.sh
+*/5 * * * * echo "%stdin"
+61 * * * * echo bad
+`
+
+	tasks, warnings := ParseCrontab(content, "crontab", false)
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d: %+v", len(tasks), tasks)
+	}
+	// MAILTO、@reboot、未转义的 % 和无效的分钟
+	if len(warnings) != 4 {
+		t.Errorf("Expected 4 warnings, got %d: %v", len(warnings), warnings)
+	}
+
+	backup := tasks[0]
+	if backup.Schedule != "0 30 3 * * 1-6,0" {
+		t.Errorf("Expected seconds and normalized Sunday, got %q", backup.Schedule)
+	}
+	if backup.Command != "/opt/backup.sh --date=$(date +%F)" {
+		t.Errorf("Expected unescaped %%, got %q", backup.Command)
+	}
+	if backup.Timezone != "Asia/Shanghai" || backup.Env["PATH"] != "/usr/local/bin:/usr/bin:/bin" {
+		t.Errorf("Expected timezone and env from file, got %q %v", backup.Timezone, backup.Env)
+	}
+	if backup.User != "" || !backup.Enabled || backup.Description != "从 crontab 第 6 行导入" {
+		t.Errorf("Unexpected task fields: %+v", backup)
+	}
+
+	if tasks[1].Schedule != "@hourly" || tasks[1].Command != "curl -s http://127.0.0.1/ping" {
+		t.Errorf("Unexpected descriptor task: %+v", tasks[1])
+	}
+
+	for _, task := range tasks {
+		task.Name = "imported"
+		if err := (&CronTaskManager{}).validateTask(task); err != nil {
+			t.Errorf("Expected imported task to be valid, got %v", err)
+		}
+	}
+}
+
+// TestParseCrontabTZ 测试只有 CRON_TZ 设置任务的时区，TZ 作为命令的环境变量保留
+func TestParseCrontabTZ(t *testing.T) {
+	tasks, _ := ParseCrontab("TZ=UTC\n0 1 * * * date\nCRON_TZ=Asia/Shanghai\n0 2 * * * date\n", "crontab", false)
+	if len(tasks) != 2 {
+		t.Fatalf("Expected 2 tasks, got %d: %+v", len(tasks), tasks)
+	}
+	if tasks[0].Timezone != "" || tasks[0].Env["TZ"] != "UTC" {
+		t.Errorf("Expected TZ to stay in env, got %q %v", tasks[0].Timezone, tasks[0].Env)
+	}
+	if tasks[1].Timezone != "Asia/Shanghai" || tasks[1].Env["TZ"] != "UTC" {
+		t.Errorf("Expected CRON_TZ to set timezone, got %q %v", tasks[1].Timezone, tasks[1].Env)
+	}
+}
+
+// TestParseSystemCrontab 测试带用户列的系统 crontab 的解析
+func TestParseSystemCrontab(t *testing.T) {
+	content := "17 *\t* * *\troot    cd / && run-parts --report /etc/cron.hourly\n" +
+		"@daily deploy /srv/app/cleanup.sh\n" +
+		"* * * * *\n"
+
+	tasks, warnings := ParseCrontab(content, "/etc/crontab", true)
+	if len(tasks) != 2 || len(warnings) != 1 {
+		t.Fatalf("Expected 2 tasks and 1 warning, got %+v %v", tasks, warnings)
+	}
+	if tasks[0].User != "root" || tasks[0].Schedule != "0 17 * * * *" || tasks[0].Command != "cd / && run-parts --report /etc/cron.hourly" {
+		t.Errorf("Unexpected system task: %+v", tasks[0])
+	}
+	if tasks[1].User != "deploy" || tasks[1].Schedule != "@daily" {
+		t.Errorf("Unexpected descriptor system task: %+v", tasks[1])
+	}
+
+	if !IsSystemCrontab("/etc/crontab") || !IsSystemCrontab("/etc/cron.d/php") || IsSystemCrontab("/var/spool/cron/crontabs/root") {
+		t.Error("Unexpected system crontab detection")
+	}
+}
+
+// TestFormatCrontab 测试导出为 crontab 格式，导出的内容可以重新导入
+func TestFormatCrontab(t *testing.T) {
+	tasks := []*CronTask{
+		{ID: 2, Name: "report", Command: "date +%F > /tmp/report", Schedule: "0 0 8 * * 1-5", Enabled: true,
+			WorkDir: "/srv/app", Env: map[string]string{"B": "it's", "A": "1"}, Timezone: "Asia/Shanghai", User: "deploy"},
+		{ID: 1, Name: "ping", Command: "echo ping", Schedule: "@hourly", Enabled: false, Retries: 2},
+		{ID: 3, Name: "fast", Command: "echo fast", Schedule: "*/10 * * * * *", Enabled: true},
+	}
+
+	output := FormatCrontab(tasks, true)
+	for _, expected := range []string{
+		"# ping\n",
+		"# crontab 不支持的配置: retries=2",
+		"# @hourly root echo ping",
+		"CRON_TZ=Asia/Shanghai\n0 8 * * 1-5 deploy cd '/srv/app' || exit 1; export A='1' B='it'\\''s'; date +\\%F > /tmp/report",
+		"# 无法转换: crontab 的最小单位为分钟，秒必须为 0",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, output)
+		}
+	}
+	if strings.Index(output, "report") < strings.Index(output, "ping") {
+		t.Error("Expected tasks to be sorted by ID")
+	}
+
+	imported, _ := ParseCrontab(output, "export", true)
+	if len(imported) != 1 {
+		t.Fatalf("Expected only the enabled convertible task to be imported, got %+v", imported)
+	}
+	if imported[0].Schedule != "0 0 8 * * 1-5" || imported[0].User != "deploy" || imported[0].Timezone != "Asia/Shanghai" ||
+		!strings.HasSuffix(imported[0].Command, "date +%F > /tmp/report") {
+		t.Errorf("Unexpected round trip result: %+v", imported[0])
+	}
+
+	if user := FormatCrontab(tasks[:1], false); !strings.Contains(user, "user=deploy") || strings.Contains(user, " deploy cd") {
+		t.Errorf("Expected user crontab without user column, got:\n%s", user)
+	}
+}
@@ -2,6 +2,7 @@
 package git
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,7 @@ import (
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/object"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
 // GitUtil 提供Git操作相关的功能
@@ -23,6 +25,11 @@ func NewGitUtil() *GitUtil {
 
 // CloneRepo 克隆代码仓库
 func (g *GitUtil) CloneRepo(url, branch, targetDir string, auth *http.BasicAuth) error {
+	return g.CloneRepoContext(context.Background(), url, branch, targetDir, auth)
+}
+
+// CloneRepoContext 克隆代码仓库，ctx 结束时中止克隆
+func (g *GitUtil) CloneRepoContext(ctx context.Context, url, branch, targetDir string, auth *http.BasicAuth) error {
 	if auth != nil {
 		// 确保URL使用HTTPS格式
 		if !strings.HasPrefix(url, "https://") {
@@ -55,7 +62,7 @@ func (g *GitUtil) CloneRepo(url, branch, targetDir string, auth *http.BasicAuth)
 	}
 
 	// 执行克隆
-	_, err := git.PlainClone(targetDir, false, cloneOptions)
+	_, err := git.PlainCloneContext(ctx, targetDir, false, cloneOptions)
 	if err != nil {
 		return fmt.Errorf("克隆仓库失败: %v", err)
 	}
@@ -63,6 +70,64 @@ func (g *GitUtil) CloneRepo(url, branch, targetDir string, auth *http.BasicAuth)
 	return nil
 }
 
+// RemoteFile 从远程分支读取的文件
+type RemoteFile struct {
+	Branch  string // 读取的分支，未指定分支时为远程仓库的默认分支
+	Name    string // 找到的文件名，候选文件都不存在时为空
+	Content []byte // 文件内容
+}
+
+// ReadRemoteFile 读取远程分支最新提交中的文件，names 为候选文件名，返回第一个存在的文件
+// 只在内存中浅拉取该分支的最新提交，不检出工作目录，branch 为空时读取默认分支
+func (g *GitUtil) ReadRemoteFile(ctx context.Context, url, branch string, auth *http.BasicAuth, names ...string) (*RemoteFile, error) {
+	cloneOptions := &git.CloneOptions{
+		URL:          url,
+		Depth:        1,
+		SingleBranch: true,
+		NoCheckout:   true,
+		Tags:         git.NoTags,
+	}
+	if auth != nil {
+		cloneOptions.Auth = auth
+	}
+	if branch != "" {
+		cloneOptions.ReferenceName = plumbing.NewBranchReferenceName(branch)
+	}
+
+	repo, err := git.CloneContext(ctx, memory.NewStorage(), nil, cloneOptions)
+	if err != nil {
+		return nil, fmt.Errorf("拉取分支失败: %v", err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("获取HEAD失败: %v", err)
+	}
+	commit, err := repo.CommitObject(head.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("获取提交对象失败: %v", err)
+	}
+
+	file := &RemoteFile{Branch: head.Name().Short()}
+	for _, name := range names {
+		f, err := commit.File(name)
+		if err == object.ErrFileNotFound {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %v", name, err)
+		}
+
+		content, err := f.Contents()
+		if err != nil {
+			return nil, fmt.Errorf("读取 %s 失败: %v", name, err)
+		}
+		file.Name, file.Content = name, []byte(content)
+		break
+	}
+	return file, nil
+}
+
 // GetCurrentBranch 获取当前分支名
 func (g *GitUtil) GetCurrentBranch(repoPath string) (string, error) {
 	repo, err := git.PlainOpen(repoPath)
@@ -79,6 +79,7 @@ import (
 	"net/http"
 	"servon/components/events"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -94,6 +95,7 @@ import (
 type GitHubIntegration struct {
 	eventBus   events.IEventBus
 	tokenCache *TokenCacheManager
+	webhookMu  sync.Mutex // 保证同一投递ID的查重和保存是原子的
 }
 
 // NewGitHubIntegration 创建一个新的GitHub集成实例
@@ -125,10 +127,20 @@ func (g *GitHubIntegration) HandleCallback(c *gin.Context) (string, error) {
 		return "", err
 	}
 
+	// GitHub 会为 manifest 中的 webhook 生成密钥，缺失时由本地生成并同步到 GitHub
+	secret := result.WebhookSecret
+	syncSecret := secret == ""
+	if syncSecret {
+		if secret, err = generateWebhookSecret(); err != nil {
+			return "", fmt.Errorf("生成 webhook 密钥失败: %v", err)
+		}
+	}
+
 	// 直接保存到磁盘
 	err = SaveAppConfig(&GitHubConfig{
 		GitHubAppID:         result.ID,
 		GitHubAppPrivateKey: result.PEM,
+		GitHubWebhookSecret: secret,
 		Installations:       make(map[int64]*Installation),
 		UpdatedAt:           time.Now().Format(time.RFC3339),
 	})
@@ -136,6 +148,12 @@ func (g *GitHubIntegration) HandleCallback(c *gin.Context) (string, error) {
 		return "", fmt.Errorf("保存GitHub App配置失败: %v", err)
 	}
 
+	if syncSecret {
+		if err := g.updateRemoteWebhookSecret(secret); err != nil {
+			return "", fmt.Errorf("同步 webhook 密钥失败，请执行 servon github webhook rotate-secret 重试: %v", err)
+		}
+	}
+
 	return result.GetInstallURL(), nil
 }
 
@@ -349,6 +367,7 @@ func (g *GitHubIntegration) generateJWT() (string, error) {
 }
 
 // ProcessWebhookEvent 处理 GitHub webhook 请求
+// 依次校验签名和投递ID，通过后保存事件数据并分发
 func (g *GitHubIntegration) ProcessWebhookEvent(c *gin.Context) error {
 	event := c.GetHeader("X-GitHub-Event")
 	eventID := c.GetHeader("X-GitHub-Delivery")
@@ -368,25 +387,42 @@ func (g *GitHubIntegration) ProcessWebhookEvent(c *gin.Context) error {
 	}
 
 	// 验证 webhook
-	if err := validateWebhook(c); err != nil {
-		return fmt.Errorf("webhook validation failed: %v", err)
+	if err := validateWebhook(c, appConfig, payload); err != nil {
+		return err
 	}
 
-	// 保存 webhook 数据
-	if err := g.SaveWebhookPayload(WebhookDir, event, eventID, payload); err != nil {
+	g.webhookMu.Lock()
+	processed, err := isDeliveryProcessed(WebhookDir, eventID)
+	if err == nil && !processed {
+		// 保存 webhook 数据，文件名中的投递ID同时用于防重放
+		err = g.SaveWebhookPayload(WebhookDir, event, eventID, payload)
+	}
+	g.webhookMu.Unlock()
+
+	if err != nil {
 		return fmt.Errorf("failed to save webhook payload: %v", err)
 	}
+	if processed {
+		return ErrDuplicateDelivery
+	}
 
 	return g.handleEvent(event, payload, g.eventBus)
 }
 
-func validateWebhook(c *gin.Context) error {
-	signature := c.GetHeader("X-Hub-Signature-256")
-	if signature == "" {
-		return fmt.Errorf("missing signature")
+// validateWebhook 校验 webhook 的签名和投递ID
+func validateWebhook(c *gin.Context, appConfig *GitHubConfig, payload []byte) error {
+	if appConfig.GitHubWebhookSecret == "" {
+		return fmt.Errorf("%w: 未配置 webhook 密钥，请执行 servon github webhook rotate-secret", ErrInvalidSignature)
+	}
+
+	if err := verifySignature(payload, c.GetHeader("X-Hub-Signature-256"), validWebhookSecrets(appConfig)...); err != nil {
+		return err
+	}
+
+	if !deliveryIDPattern.MatchString(c.GetHeader("X-GitHub-Delivery")) {
+		return fmt.Errorf("无效的 X-GitHub-Delivery")
 	}
 
-	// TODO: 实现签名验证
 	return nil
 }
 
@@ -466,12 +502,37 @@ func (g *GitHubIntegration) handleInstallationEvent(payload []byte) error {
 }
 
 // handlePushEvent 处理代码推送事件
+// 只处理分支推送，标签推送和分支删除会被忽略
 func (g *GitHubIntegration) handlePushEvent(payload []byte, eventBus events.IEventBus) error {
-	// TODO: 解析 payload 并发布事件
+	var event struct {
+		Ref        string `json:"ref"`
+		After      string `json:"after"`
+		Deleted    bool   `json:"deleted"`
+		Repository struct {
+			FullName      string `json:"full_name"`
+			DefaultBranch string `json:"default_branch"`
+		} `json:"repository"`
+		Pusher struct {
+			Name string `json:"name"`
+		} `json:"pusher"`
+	}
+
+	if err := json.Unmarshal(payload, &event); err != nil {
+		return fmt.Errorf("解析推送事件失败: %v", err)
+	}
+
+	if event.Deleted || !strings.HasPrefix(event.Ref, "refs/heads/") {
+		return nil
+	}
+
 	return eventBus.Publish(events.Event{
 		Type: events.GitPush,
 		Data: map[string]interface{}{
-			"payload": string(payload),
+			"repository":     event.Repository.FullName,
+			"branch":         strings.TrimPrefix(event.Ref, "refs/heads/"),
+			"default_branch": event.Repository.DefaultBranch,
+			"commit":         event.After,
+			"pusher":         event.Pusher.Name,
 		},
 	})
 }
@@ -8,9 +8,10 @@ import (
 
 // AppCreationResult 表示 GitHub App 创建的结果
 type AppCreationResult struct {
-	ID   int64  `json:"id"`   // GitHub App 的唯一标识符
-	Name string `json:"name"` // GitHub App 的名称
-	PEM  string `json:"pem"`  // GitHub App 的私钥
+	ID            int64  `json:"id"`             // GitHub App 的唯一标识符
+	Name          string `json:"name"`           // GitHub App 的名称
+	PEM           string `json:"pem"`            // GitHub App 的私钥
+	WebhookSecret string `json:"webhook_secret"` // GitHub 为 manifest 中 webhook 生成的密钥
 }
 
 // GitHubRepo 表示一个GitHub仓库的基本信息
@@ -54,11 +55,13 @@ type GitHubManifest struct {
 
 // GitHubConfig 表示 GitHub App 的配置信息
 type GitHubConfig struct {
-	GitHubAppID         int64                   `json:"github_app_id"`          // GitHub App 的ID
-	GitHubAppPrivateKey string                  `json:"github_app_private_key"` // GitHub App 的私钥
-	GitHubWebhookSecret string                  `json:"github_webhook_secret"`  // Webhook 的密钥
-	Installations       map[int64]*Installation `json:"installations"`          // 所有安装实例的映射表
-	UpdatedAt           string                  `json:"updated_at"`             // 最后更新时间
+	GitHubAppID         int64                   `json:"github_app_id"`           // GitHub App 的ID
+	GitHubAppPrivateKey string                  `json:"github_app_private_key"`  // GitHub App 的私钥
+	GitHubWebhookSecret string                  `json:"github_webhook_secret"`   // Webhook 的密钥
+	PreviousSecret      string                  `json:"previous_webhook_secret"` // 轮换前的旧密钥，宽限期内仍然有效
+	SecretRotatedAt     string                  `json:"secret_rotated_at"`       // 最近一次轮换密钥的时间
+	Installations       map[int64]*Installation `json:"installations"`           // 所有安装实例的映射表
+	UpdatedAt           string                  `json:"updated_at"`              // 最后更新时间
 }
 
 // Installation 表示 GitHub App 的安装信息
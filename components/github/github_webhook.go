@@ -0,0 +1,158 @@
+package github
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// webhookSecretGracePeriod 轮换密钥后旧密钥继续有效的时间，避免正在投递的事件验证失败
+const webhookSecretGracePeriod = time.Hour
+
+var (
+	// ErrInvalidSignature webhook 签名缺失或不匹配
+	ErrInvalidSignature = errors.New("webhook 签名验证失败")
+	// ErrDuplicateDelivery 相同 X-GitHub-Delivery 的事件已经处理过
+	ErrDuplicateDelivery = errors.New("webhook 事件已处理，忽略重复投递")
+)
+
+// deliveryIDPattern GitHub 的投递ID为 GUID，限制字符集以便安全地用于文件名匹配
+var deliveryIDPattern = regexp.MustCompile(`^[0-9a-fA-F-]{1,64}$`)
+
+// verifySignature 使用 HMAC-SHA256 校验 X-Hub-Signature-256 头，比较过程为常量时间
+// secrets 中任意一个密钥校验通过即视为成功，空密钥会被忽略
+func verifySignature(payload []byte, signature string, secrets ...string) error {
+	const prefix = "sha256="
+	if len(signature) <= len(prefix) || signature[:len(prefix)] != prefix {
+		return ErrInvalidSignature
+	}
+
+	expected, err := hex.DecodeString(signature[len(prefix):])
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	for _, secret := range secrets {
+		if secret == "" {
+			continue
+		}
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write(payload)
+		if hmac.Equal(mac.Sum(nil), expected) {
+			return nil
+		}
+	}
+
+	return ErrInvalidSignature
+}
+
+// validWebhookSecrets 返回当前可用于校验签名的密钥，轮换宽限期内包含旧密钥
+func validWebhookSecrets(config *GitHubConfig) []string {
+	secrets := []string{config.GitHubWebhookSecret}
+
+	if config.PreviousSecret != "" {
+		rotatedAt, err := time.Parse(time.RFC3339, config.SecretRotatedAt)
+		if err == nil && time.Since(rotatedAt) < webhookSecretGracePeriod {
+			secrets = append(secrets, config.PreviousSecret)
+		}
+	}
+
+	return secrets
+}
+
+// isDeliveryProcessed 根据 SaveWebhookPayload 保存的文件名判断投递ID是否已经处理过
+func isDeliveryProcessed(dataDir, deliveryID string) (bool, error) {
+	matches, err := filepath.Glob(filepath.Join(dataDir, fmt.Sprintf("*_%s_*.json", deliveryID)))
+	if err != nil {
+		return false, err
+	}
+	return len(matches) > 0, nil
+}
+
+// generateWebhookSecret 生成新的 webhook 密钥
+func generateWebhookSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// RotateWebhookSecret 生成新的 webhook 密钥，同步到 GitHub App 后保存到本地配置
+// 旧密钥在宽限期内仍然有效
+// 返回值:
+//   - string: 新的密钥
+//   - error: 处理过程中的错误
+func (g *GitHubIntegration) RotateWebhookSecret() (string, error) {
+	appConfig, err := LoadAppConfig()
+	if err != nil {
+		return "", fmt.Errorf("加载 GitHub App 配置失败: %v", err)
+	}
+	if appConfig == nil {
+		return "", fmt.Errorf("GitHub App 配置不存在")
+	}
+
+	secret, err := generateWebhookSecret()
+	if err != nil {
+		return "", fmt.Errorf("生成 webhook 密钥失败: %v", err)
+	}
+
+	if err := g.updateRemoteWebhookSecret(secret); err != nil {
+		return "", err
+	}
+
+	appConfig.PreviousSecret = appConfig.GitHubWebhookSecret
+	appConfig.SecretRotatedAt = time.Now().Format(time.RFC3339)
+	appConfig.GitHubWebhookSecret = secret
+	if err := SaveAppConfig(appConfig); err != nil {
+		return "", fmt.Errorf("GitHub 上的密钥已更新，但保存本地配置失败，新密钥为 %s: %v", secret, err)
+	}
+
+	return secret, nil
+}
+
+// updateRemoteWebhookSecret 通过 GitHub API 更新 App 的 webhook 密钥
+func (g *GitHubIntegration) updateRemoteWebhookSecret(secret string) error {
+	jwt, err := g.generateJWT()
+	if err != nil {
+		return fmt.Errorf("生成 JWT 失败: %v", err)
+	}
+
+	body, err := json.Marshal(map[string]string{"secret": secret})
+	if err != nil {
+		return fmt.Errorf("序列化请求失败: %v", err)
+	}
+
+	req, err := http.NewRequest(http.MethodPatch, "https://api.github.com/app/hook/config", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("创建 HTTP 请求失败: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送请求失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("更新 GitHub webhook 密钥失败: %s - %s", resp.Status, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package github
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+// sign 计算 payload 的 X-Hub-Signature-256 头
+func sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// TestVerifySignature 测试 webhook 签名校验
+func TestVerifySignature(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/main"}`)
+
+	if err := verifySignature(payload, sign("secret", payload), "secret"); err != nil {
+		t.Errorf("Expected valid signature, got %v", err)
+	}
+
+	// 轮换后旧密钥仍可校验
+	if err := verifySignature(payload, sign("old", payload), "new", "old"); err != nil {
+		t.Errorf("Expected previous secret to be accepted, got %v", err)
+	}
+
+	cases := map[string]string{
+		"wrong secret":   sign("other", payload),
+		"missing header": "",
+		"sha1 header":    "sha1=" + hex.EncodeToString(make([]byte, 20)),
+		"invalid hex":    "sha256=zz",
+	}
+	for name, signature := range cases {
+		if err := verifySignature(payload, signature, "secret"); err != ErrInvalidSignature {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+
+	// 篡改 payload 后签名失效
+	if err := verifySignature([]byte(`{"ref":"refs/heads/evil"}`), sign("secret", payload), "secret"); err == nil {
+		t.Error("Expected tampered payload to be rejected")
+	}
+
+	// 空密钥不能通过校验
+	if err := verifySignature(payload, sign("", payload), ""); err == nil {
+		t.Error("Expected empty secret to be rejected")
+	}
+}
+
+// TestIsDeliveryProcessed 测试根据已保存文件识别重复投递
+func TestIsDeliveryProcessed(t *testing.T) {
+	dir := t.TempDir()
+	id := "72d3162e-cc78-11e3-81ab-4c9367dc0958"
+
+	processed, err := isDeliveryProcessed(dir, id)
+	if err != nil || processed {
+		t.Fatalf("Expected unprocessed delivery, got %v, %v", processed, err)
+	}
+
+	g := &GitHubIntegration{}
+	if err := g.SaveWebhookPayload(dir, "push", id, []byte(`{}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	processed, err = isDeliveryProcessed(dir, id)
+	if err != nil || !processed {
+		t.Fatalf("Expected processed delivery, got %v, %v", processed, err)
+	}
+
+	// 其他投递ID不受影响
+	processed, _ = isDeliveryProcessed(dir, "00000000-0000-0000-0000-000000000000")
+	if processed {
+		t.Error("Expected different delivery ID to be unprocessed")
+	}
+}
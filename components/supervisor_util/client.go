@@ -0,0 +1,364 @@
+package supervisor_util
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// DefaultSocket supervisord 默认的 unix socket
+const DefaultSocket = "/var/run/supervisor.sock"
+
+// supervisor 的错误码，见 supervisor/xmlrpc.py 中的 Faults
+const (
+	FaultShutdownState  = 6
+	FaultBadName        = 10
+	FaultNoFile         = 20
+	FaultFailed         = 30
+	FaultSpawnError     = 50
+	FaultAlreadyStarted = 60
+	FaultNotRunning     = 70
+	FaultAlreadyAdded   = 90
+	FaultStillRunning   = 91
+	FaultCantReread     = 92
+)
+
+// supervisor 的进程状态
+const (
+	StateStopped  = 0
+	StateStarting = 10
+	StateRunning  = 20
+	StateBackoff  = 30
+	StateStopping = 40
+	StateExited   = 100
+	StateFatal    = 200
+	StateUnknown  = 1000
+)
+
+// Fault supervisord 返回的错误
+type Fault struct {
+	Code   int
+	String string
+}
+
+func (f *Fault) Error() string {
+	return fmt.Sprintf("%s (%d)", f.String, f.Code)
+}
+
+// IsFault 判断错误是否为指定错误码的 Fault
+func IsFault(err error, code int) bool {
+	var fault *Fault
+	return errors.As(err, &fault) && fault.Code == code
+}
+
+// ProcessInfo getProcessInfo 和 getAllProcessInfo 返回的进程信息
+type ProcessInfo struct {
+	Name          string
+	Group         string
+	Description   string
+	Start         int64 // 启动时间（Unix 时间戳）
+	Stop          int64 // 最近一次停止的时间，从未停止过为 0
+	Now           int64 // supervisord 的当前时间
+	State         int
+	StateName     string
+	SpawnErr      string
+	ExitStatus    int
+	StdoutLogfile string
+	StderrLogfile string
+	PID           int
+}
+
+// FullName supervisorctl 中显示的名称，程序属于其他组时为 group:name
+func (p ProcessInfo) FullName() string {
+	if p.Group == "" || p.Group == p.Name {
+		return p.Name
+	}
+	return p.Group + ":" + p.Name
+}
+
+// Uptime 进程已运行的秒数，未运行时为 0
+func (p ProcessInfo) Uptime() int64 {
+	if p.State != StateRunning || p.Start == 0 || p.Now < p.Start {
+		return 0
+	}
+	return p.Now - p.Start
+}
+
+// Client 通过 unix socket 调用 supervisord 的 XML-RPC 接口
+type Client struct {
+	socket     string
+	httpClient *http.Client
+}
+
+// NewClient 创建客户端，socket 为空时使用 DefaultSocket
+func NewClient(socket string) *Client {
+	if socket == "" {
+		socket = DefaultSocket
+	}
+
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &Client{
+		socket: socket,
+		httpClient: &http.Client{
+			// 停止进程时会等待 stopwaitsecs，默认 10 秒
+			Timeout: 2 * time.Minute,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// Call 调用 XML-RPC 方法，返回解码后的结果
+func (c *Client) Call(method string, args ...interface{}) (interface{}, error) {
+	body, err := encodeCall(method, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Post("http://localhost/RPC2", "text/xml", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("连接 supervisord 失败 (%s): %w", c.socket, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取 supervisord 响应失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("supervisord 返回 %s", resp.Status)
+	}
+
+	return decodeResponse(data)
+}
+
+// GetState 获取 supervisord 的状态，如 RUNNING、SHUTDOWN
+func (c *Client) GetState() (string, error) {
+	result, err := c.Call("supervisor.getState")
+	if err != nil {
+		return "", err
+	}
+	fields, _ := result.(map[string]interface{})
+	name, _ := fields["statename"].(string)
+	return name, nil
+}
+
+// GetPID 获取 supervisord 的进程ID
+func (c *Client) GetPID() (int, error) {
+	result, err := c.Call("supervisor.getPID")
+	if err != nil {
+		return 0, err
+	}
+	pid, _ := result.(int)
+	return pid, nil
+}
+
+// GetSupervisorVersion 获取 supervisord 的版本
+func (c *Client) GetSupervisorVersion() (string, error) {
+	result, err := c.Call("supervisor.getSupervisorVersion")
+	if err != nil {
+		return "", err
+	}
+	version, _ := result.(string)
+	return version, nil
+}
+
+// Shutdown 关闭 supervisord
+func (c *Client) Shutdown() error {
+	_, err := c.Call("supervisor.shutdown")
+	return err
+}
+
+// GetAllProcessInfo 获取所有进程的信息
+func (c *Client) GetAllProcessInfo() ([]ProcessInfo, error) {
+	result, err := c.Call("supervisor.getAllProcessInfo")
+	if err != nil {
+		return nil, err
+	}
+
+	items, _ := result.([]interface{})
+	infos := make([]ProcessInfo, 0, len(items))
+	for _, item := range items {
+		fields, _ := item.(map[string]interface{})
+		infos = append(infos, processInfo(fields))
+	}
+	return infos, nil
+}
+
+// GetProcessInfo 获取单个进程的信息，name 可以是 name 或 group:name
+func (c *Client) GetProcessInfo(name string) (*ProcessInfo, error) {
+	result, err := c.Call("supervisor.getProcessInfo", name)
+	if err != nil {
+		return nil, err
+	}
+	fields, _ := result.(map[string]interface{})
+	info := processInfo(fields)
+	return &info, nil
+}
+
+// StartProcess 启动进程，wait 为 true 时等待进程进入 RUNNING 状态
+func (c *Client) StartProcess(name string, wait bool) error {
+	_, err := c.Call("supervisor.startProcess", name, wait)
+	return err
+}
+
+// StopProcess 停止进程，wait 为 true 时等待进程退出
+func (c *Client) StopProcess(name string, wait bool) error {
+	_, err := c.Call("supervisor.stopProcess", name, wait)
+	return err
+}
+
+// StopProcessGroup 停止组中的所有进程
+func (c *Client) StopProcessGroup(name string, wait bool) error {
+	_, err := c.Call("supervisor.stopProcessGroup", name, wait)
+	return err
+}
+
+// ReadProcessStdoutLog 从 offset 开始读取 length 字节的标准输出日志，offset 为负数时从末尾开始计算
+func (c *Client) ReadProcessStdoutLog(name string, offset, length int) (string, error) {
+	return c.readLog("supervisor.readProcessStdoutLog", name, offset, length)
+}
+
+// ReadProcessStderrLog 从 offset 开始读取 length 字节的标准错误日志，offset 为负数时从末尾开始计算
+func (c *Client) ReadProcessStderrLog(name string, offset, length int) (string, error) {
+	return c.readLog("supervisor.readProcessStderrLog", name, offset, length)
+}
+
+func (c *Client) readLog(method, name string, offset, length int) (string, error) {
+	result, err := c.Call(method, name, offset, length)
+	if err != nil {
+		return "", err
+	}
+	text, _ := result.(string)
+	return text, nil
+}
+
+// TailProcessStdoutLog 读取标准输出日志的末尾，offset 为上次读取返回的位置，首次读取传 0
+// 返回读取到的内容、下次读取的位置，以及是否有内容因超过 length 而被跳过
+func (c *Client) TailProcessStdoutLog(name string, offset, length int) (string, int, bool, error) {
+	return c.tailLog("supervisor.tailProcessStdoutLog", name, offset, length)
+}
+
+// TailProcessStderrLog 读取标准错误日志的末尾，参数和返回值同 TailProcessStdoutLog
+func (c *Client) TailProcessStderrLog(name string, offset, length int) (string, int, bool, error) {
+	return c.tailLog("supervisor.tailProcessStderrLog", name, offset, length)
+}
+
+func (c *Client) tailLog(method, name string, offset, length int) (string, int, bool, error) {
+	result, err := c.Call(method, name, offset, length)
+	if err != nil {
+		return "", 0, false, err
+	}
+
+	values, _ := result.([]interface{})
+	if len(values) != 3 {
+		return "", 0, false, fmt.Errorf("%s 返回了无效的结果", method)
+	}
+	text, _ := values[0].(string)
+	next, _ := values[1].(int)
+	overflow, _ := values[2].(bool)
+	return text, next, overflow, nil
+}
+
+// ReloadConfig 重新读取配置文件，返回新增、修改和删除的组，不会对进程做任何操作
+func (c *Client) ReloadConfig() (added, changed, removed []string, err error) {
+	result, err := c.Call("supervisor.reloadConfig")
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// 返回值为 [[added, changed, removed]]
+	outer, _ := result.([]interface{})
+	if len(outer) != 1 {
+		return nil, nil, nil, fmt.Errorf("reloadConfig 返回了无效的结果")
+	}
+	lists, _ := outer[0].([]interface{})
+	if len(lists) != 3 {
+		return nil, nil, nil, fmt.Errorf("reloadConfig 返回了无效的结果")
+	}
+	return stringList(lists[0]), stringList(lists[1]), stringList(lists[2]), nil
+}
+
+// AddProcessGroup 启用配置文件中新增的组
+func (c *Client) AddProcessGroup(name string) error {
+	_, err := c.Call("supervisor.addProcessGroup", name)
+	return err
+}
+
+// RemoveProcessGroup 移除已停止的组
+func (c *Client) RemoveProcessGroup(name string) error {
+	_, err := c.Call("supervisor.removeProcessGroup", name)
+	return err
+}
+
+// Update 和 supervisorctl update 相同：重新读取配置，停止并移除已删除或修改的组，再添加新增或修改的组
+func (c *Client) Update() error {
+	added, changed, removed, err := c.ReloadConfig()
+	if err != nil {
+		return err
+	}
+
+	for _, group := range append(removed, changed...) {
+		if err := c.StopProcessGroup(group, true); err != nil && !IsFault(err, FaultBadName) {
+			return fmt.Errorf("停止 %s 失败: %w", group, err)
+		}
+		if err := c.RemoveProcessGroup(group); err != nil && !IsFault(err, FaultBadName) {
+			return fmt.Errorf("移除 %s 失败: %w", group, err)
+		}
+	}
+
+	for _, group := range append(added, changed...) {
+		if err := c.AddProcessGroup(group); err != nil && !IsFault(err, FaultAlreadyAdded) {
+			return fmt.Errorf("添加 %s 失败: %w", group, err)
+		}
+	}
+	return nil
+}
+
+// processInfo 把 XML-RPC 返回的结构体转换为 ProcessInfo
+func processInfo(fields map[string]interface{}) ProcessInfo {
+	str := func(key string) string {
+		value, _ := fields[key].(string)
+		return value
+	}
+	num := func(key string) int {
+		value, _ := fields[key].(int)
+		return value
+	}
+
+	return ProcessInfo{
+		Name:          str("name"),
+		Group:         str("group"),
+		Description:   str("description"),
+		Start:         int64(num("start")),
+		Stop:          int64(num("stop")),
+		Now:           int64(num("now")),
+		State:         num("state"),
+		StateName:     str("statename"),
+		SpawnErr:      str("spawnerr"),
+		ExitStatus:    num("exitstatus"),
+		StdoutLogfile: str("stdout_logfile"),
+		StderrLogfile: str("stderr_logfile"),
+		PID:           num("pid"),
+	}
+}
+
+// stringList 把 XML-RPC 数组转换为字符串切片
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	list := make([]string, 0, len(items))
+	for _, item := range items {
+		if text, ok := item.(string); ok {
+			list = append(list, text)
+		}
+	}
+	return list
+}
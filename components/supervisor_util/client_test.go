@@ -0,0 +1,205 @@
+package supervisor_util
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+)
+
+var methodPattern = regexp.MustCompile(`<methodName>([^<]+)</methodName>`)
+
+// fakeSupervisord 在 unix socket 上模拟 supervisord 的 XML-RPC 接口，记录收到的调用
+type fakeSupervisord struct {
+	mu        sync.Mutex
+	calls     []string
+	responses map[string]string
+}
+
+func (f *fakeSupervisord) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	match := methodPattern.FindSubmatch(body)
+	if r.URL.Path != "/RPC2" || match == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	method := strings.TrimPrefix(string(match[1]), "supervisor.")
+	f.mu.Lock()
+	f.calls = append(f.calls, method)
+	response, ok := f.responses[method]
+	f.mu.Unlock()
+
+	if !ok {
+		response = fault(1, "UNKNOWN_METHOD")
+	} else if !strings.HasPrefix(response, "<fault>") {
+		response = "<params><param><value>" + response + "</value></param></params>"
+	}
+	w.Header().Set("Content-Type", "text/xml")
+	io.WriteString(w, `<?xml version="1.0"?><methodResponse>`+response+`</methodResponse>`)
+}
+
+func fault(code int, text string) string {
+	return `<fault><value><struct>` +
+		`<member><name>faultCode</name><value><int>` + strconv.Itoa(code) + `</int></value></member>` +
+		`<member><name>faultString</name><value><string>` + text + `</string></value></member>` +
+		`</struct></value></fault>`
+}
+
+// startFake 启动模拟的 supervisord，返回连接它的客户端
+func startFake(t *testing.T, responses map[string]string) (*Client, *fakeSupervisord) {
+	// unix socket 路径有长度限制，不使用 t.TempDir
+	dir, err := os.MkdirTemp("", "sv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "supervisor.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeSupervisord{responses: responses}
+	server := &http.Server{Handler: fake}
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Close() })
+
+	return NewClient(socket), fake
+}
+
+// TestGetAllProcessInfo 测试进程信息的解析
+func TestGetAllProcessInfo(t *testing.T) {
+	process := func(name, group, state string, stateCode, pid, exit int) string {
+		return `<struct>` +
+			`<member><name>name</name><value><string>` + name + `</string></value></member>` +
+			`<member><name>group</name><value><string>` + group + `</string></value></member>` +
+			`<member><name>statename</name><value><string>` + state + `</string></value></member>` +
+			`<member><name>state</name><value><int>` + strconv.Itoa(stateCode) + `</int></value></member>` +
+			`<member><name>pid</name><value><int>` + strconv.Itoa(pid) + `</int></value></member>` +
+			`<member><name>exitstatus</name><value><int>` + strconv.Itoa(exit) + `</int></value></member>` +
+			`<member><name>start</name><value><int>1700000000</int></value></member>` +
+			`<member><name>now</name><value><int>1700090062</int></value></member>` +
+			`<member><name>description</name><value>Exited too quickly &amp; gave up</value></member>` +
+			`</struct>`
+	}
+
+	client, _ := startFake(t, map[string]string{
+		"getAllProcessInfo": `<array><data>` +
+			`<value>` + process("web", "web", "RUNNING", StateRunning, 42, 0) + `</value>` +
+			`<value>` + process("worker_00", "queue", "FATAL", StateFatal, 0, 3) + `</value>` +
+			`</data></array>`,
+	})
+
+	infos, err := client.GetAllProcessInfo()
+	if err != nil {
+		t.Fatalf("Expected process info, got %v", err)
+	}
+	if len(infos) != 2 {
+		t.Fatalf("Expected 2 processes, got %+v", infos)
+	}
+
+	web := infos[0]
+	if web.FullName() != "web" || web.PID != 42 || web.StateName != "RUNNING" || web.Uptime() != 90062 {
+		t.Errorf("Unexpected running process: %+v", web)
+	}
+
+	worker := infos[1]
+	if worker.FullName() != "queue:worker_00" || worker.ExitStatus != 3 || worker.Uptime() != 0 {
+		t.Errorf("Unexpected fatal process: %+v", worker)
+	}
+	if worker.Description != "Exited too quickly & gave up" {
+		t.Errorf("Expected untyped value to decode as string, got %q", worker.Description)
+	}
+}
+
+// TestFault 测试 supervisord 返回的错误
+func TestFault(t *testing.T) {
+	client, fake := startFake(t, map[string]string{
+		"startProcess": fault(FaultAlreadyStarted, "ALREADY_STARTED: web"),
+		"stopProcess":  `<boolean>1</boolean>`,
+	})
+
+	err := client.StartProcess("web", true)
+	if !IsFault(err, FaultAlreadyStarted) {
+		t.Errorf("Expected ALREADY_STARTED fault, got %v", err)
+	}
+	if IsFault(err, FaultBadName) {
+		t.Error("Expected fault code to be compared")
+	}
+
+	if err := client.StopProcess("web", true); err != nil {
+		t.Errorf("Expected stop to succeed, got %v", err)
+	}
+	if _, err := client.GetState(); !IsFault(err, 1) {
+		t.Errorf("Expected unknown method fault, got %v", err)
+	}
+
+	if strings.Join(fake.calls, ",") != "startProcess,stopProcess,getState" {
+		t.Errorf("Unexpected calls: %v", fake.calls)
+	}
+
+	missing := NewClient(filepath.Join(os.TempDir(), "missing-supervisor.sock"))
+	var f *Fault
+	if _, err := missing.GetState(); err == nil || errors.As(err, &f) {
+		t.Errorf("Expected connection error, got %v", err)
+	}
+}
+
+// TestTailProcessLog 测试日志读取，日志中的控制字符需要保留
+func TestTailProcessLog(t *testing.T) {
+	client, _ := startFake(t, map[string]string{
+		"tailProcessStdoutLog": "<array><data><value><string>\x1b[31mred\x1b[0m &lt;ok&gt;\n</string></value>" +
+			"<value><int>2048</int></value><value><boolean>1</boolean></value></data></array>",
+		"readProcessStderrLog": "<string>boom\n</string>",
+	})
+
+	text, offset, overflow, err := client.TailProcessStdoutLog("web", 0, 1024)
+	if err != nil {
+		t.Fatalf("Expected log, got %v", err)
+	}
+	if text != "\x1b[31mred\x1b[0m <ok>\n" || offset != 2048 || !overflow {
+		t.Errorf("Unexpected tail result: %q %d %v", text, offset, overflow)
+	}
+
+	if text, err := client.ReadProcessStderrLog("web", -1024, 0); err != nil || text != "boom\n" {
+		t.Errorf("Unexpected stderr log: %q %v", text, err)
+	}
+}
+
+// TestUpdate 测试 Update 按 supervisorctl update 的顺序处理配置的变化
+func TestUpdate(t *testing.T) {
+	list := func(names ...string) string {
+		values := ""
+		for _, name := range names {
+			values += "<value><string>" + name + "</string></value>"
+		}
+		return "<value><array><data>" + values + "</data></array></value>"
+	}
+
+	client, fake := startFake(t, map[string]string{
+		"reloadConfig": "<array><data><value><array><data>" +
+			list("new") + list("changed") + list("old") +
+			"</data></array></value></data></array>",
+		"stopProcessGroup":   `<array><data></data></array>`,
+		"removeProcessGroup": `<boolean>1</boolean>`,
+		"addProcessGroup":    `<boolean>1</boolean>`,
+	})
+
+	if err := client.Update(); err != nil {
+		t.Fatalf("Expected update to succeed, got %v", err)
+	}
+
+	expected := "reloadConfig,stopProcessGroup,removeProcessGroup,stopProcessGroup,removeProcessGroup,addProcessGroup,addProcessGroup"
+	if strings.Join(fake.calls, ",") != expected {
+		t.Errorf("Expected calls %s, got %v", expected, fake.calls)
+	}
+}
@@ -0,0 +1,195 @@
+package supervisor_util
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// controlBase 响应中的控制字符会被替换为从这里开始的私用区字符，解码后再换回来
+// supervisor 不会转义日志中的控制字符（如终端颜色），而 XML 1.0 中不允许出现这些字符
+const controlBase = 0xE000
+
+// xmlValue XML-RPC 中的值，未指定类型时内容为字符串
+type xmlValue struct {
+	Text     string     `xml:",chardata"`
+	String   *string    `xml:"string"`
+	Int      *string    `xml:"int"`
+	I4       *string    `xml:"i4"`
+	Boolean  *string    `xml:"boolean"`
+	Double   *string    `xml:"double"`
+	Base64   *string    `xml:"base64"`
+	DateTime *string    `xml:"dateTime.iso8601"`
+	Array    *xmlArray  `xml:"array"`
+	Struct   *xmlStruct `xml:"struct"`
+	Nil      *struct{}  `xml:"nil"`
+}
+
+type xmlArray struct {
+	Values []xmlValue `xml:"data>value"`
+}
+
+type xmlStruct struct {
+	Members []xmlMember `xml:"member"`
+}
+
+type xmlMember struct {
+	Name  string   `xml:"name"`
+	Value xmlValue `xml:"value"`
+}
+
+// methodResponse XML-RPC 响应，调用失败时只有 Fault
+type methodResponse struct {
+	Params []xmlValue `xml:"params>param>value"`
+	Fault  *xmlValue  `xml:"fault>value"`
+}
+
+// encodeCall 生成 XML-RPC 请求，参数支持 string、int、int64 和 bool
+func encodeCall(method string, args ...interface{}) ([]byte, error) {
+	var b bytes.Buffer
+	b.WriteString(`<?xml version="1.0"?><methodCall><methodName>`)
+	xml.EscapeText(&b, []byte(method))
+	b.WriteString(`</methodName><params>`)
+	for _, arg := range args {
+		b.WriteString(`<param><value>`)
+		switch v := arg.(type) {
+		case string:
+			b.WriteString(`<string>`)
+			xml.EscapeText(&b, []byte(v))
+			b.WriteString(`</string>`)
+		case int:
+			fmt.Fprintf(&b, `<int>%d</int>`, v)
+		case int64:
+			fmt.Fprintf(&b, `<int>%d</int>`, v)
+		case bool:
+			value := 0
+			if v {
+				value = 1
+			}
+			fmt.Fprintf(&b, `<boolean>%d</boolean>`, value)
+		default:
+			return nil, fmt.Errorf("不支持的参数类型: %T", arg)
+		}
+		b.WriteString(`</value></param>`)
+	}
+	b.WriteString(`</params></methodCall>`)
+	return b.Bytes(), nil
+}
+
+// decodeResponse 解析 XML-RPC 响应，返回第一个返回值，调用失败时返回 *Fault
+func decodeResponse(data []byte) (interface{}, error) {
+	var resp methodResponse
+	if err := xml.Unmarshal(escapeControl(data), &resp); err != nil {
+		return nil, fmt.Errorf("解析响应失败: %v", err)
+	}
+
+	if resp.Fault != nil {
+		value, err := resp.Fault.decode()
+		if err != nil {
+			return nil, err
+		}
+		fields, _ := value.(map[string]interface{})
+		fault := &Fault{}
+		fault.Code, _ = fields["faultCode"].(int)
+		fault.String, _ = fields["faultString"].(string)
+		return nil, fault
+	}
+
+	if len(resp.Params) == 0 {
+		return nil, nil
+	}
+	return resp.Params[0].decode()
+}
+
+// decode 把值转换为 string、int、bool、float64、[]interface{} 或 map[string]interface{}
+func (v xmlValue) decode() (interface{}, error) {
+	switch {
+	case v.String != nil:
+		return restoreControl(*v.String), nil
+	case v.Int != nil, v.I4 != nil:
+		text := v.Int
+		if text == nil {
+			text = v.I4
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(*text))
+		if err != nil {
+			return nil, fmt.Errorf("无效的整数: %s", *text)
+		}
+		return n, nil
+	case v.Boolean != nil:
+		return strings.TrimSpace(*v.Boolean) == "1", nil
+	case v.Double != nil:
+		f, err := strconv.ParseFloat(strings.TrimSpace(*v.Double), 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的浮点数: %s", *v.Double)
+		}
+		return f, nil
+	case v.Base64 != nil:
+		return *v.Base64, nil
+	case v.DateTime != nil:
+		return *v.DateTime, nil
+	case v.Array != nil:
+		values := make([]interface{}, 0, len(v.Array.Values))
+		for _, item := range v.Array.Values {
+			value, err := item.decode()
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case v.Struct != nil:
+		fields := make(map[string]interface{}, len(v.Struct.Members))
+		for _, member := range v.Struct.Members {
+			value, err := member.Value.decode()
+			if err != nil {
+				return nil, err
+			}
+			fields[member.Name] = value
+		}
+		return fields, nil
+	case v.Nil != nil:
+		return nil, nil
+	}
+	return restoreControl(v.Text), nil
+}
+
+// escapeControl 把 XML 中不允许出现的控制字符替换为私用区字符
+func escapeControl(data []byte) []byte {
+	if bytes.IndexFunc(data, isIllegalControl) < 0 {
+		return data
+	}
+
+	var b bytes.Buffer
+	for _, c := range data {
+		if isIllegalControl(rune(c)) {
+			b.WriteRune(controlBase + rune(c))
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.Bytes()
+}
+
+// restoreControl 把 escapeControl 替换的字符换回控制字符
+func restoreControl(text string) string {
+	if !strings.ContainsFunc(text, isEscapedControl) {
+		return text
+	}
+	return strings.Map(func(r rune) rune {
+		if isEscapedControl(r) {
+			return r - controlBase
+		}
+		return r
+	}, text)
+}
+
+func isIllegalControl(r rune) bool {
+	return r < 0x20 && r != '\t' && r != '\n' && r != '\r'
+}
+
+func isEscapedControl(r rune) bool {
+	return r >= controlBase && r < controlBase+0x20 && isIllegalControl(r-controlBase)
+}
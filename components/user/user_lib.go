@@ -2,9 +2,11 @@ package user
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
+	osuser "os/user"
 	"strings"
 	"time"
 )
@@ -150,6 +152,23 @@ func (u *UserManager) CreateUser(username string, password string) error {
 	return nil
 }
 
+// CreateSystemUser 创建不能登录的系统用户：没有 home 目录和密码，shell 为 nologin，用于以非 root 身份运行后台服务
+func (u *UserManager) CreateSystemUser(username string) error {
+	exists, err := u.UserExists(username)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("用户 %s 已存在", username)
+	}
+
+	err, output := RunShell("useradd", "--system", "--no-create-home", "--shell", nologinShell(), username)
+	if err != nil {
+		return fmt.Errorf("创建用户失败: %v: %s", err, strings.TrimSpace(output))
+	}
+	return nil
+}
+
 // DeleteUser 删除用户
 func (u *UserManager) DeleteUser(username string) error {
 	exists, err := u.UserExists(username)
@@ -169,10 +188,10 @@ func (u *UserManager) DeleteUser(username string) error {
 
 // UserExists 检查用户是否存在
 func (u *UserManager) UserExists(username string) (bool, error) {
-	err, output := RunShellWithOutput("id", username)
-
+	_, err := osuser.Lookup(username)
 	if err != nil {
-		if strings.Contains(output, "no such user") {
+		var unknown osuser.UnknownUserError
+		if errors.As(err, &unknown) {
 			return false, nil
 		}
 
@@ -181,3 +200,13 @@ func (u *UserManager) UserExists(username string) (bool, error) {
 
 	return true, nil
 }
+
+// nologinShell 禁止登录的 shell，不同发行版的路径不同
+func nologinShell() string {
+	for _, shell := range []string{"/usr/sbin/nologin", "/sbin/nologin"} {
+		if _, err := os.Stat(shell); err == nil {
+			return shell
+		}
+	}
+	return "/bin/false"
+}
@@ -0,0 +1,27 @@
+package user
+
+import "testing"
+
+// TestUserExists 测试存在和不存在的用户
+func TestUserExists(t *testing.T) {
+	u := NewUserManager()
+
+	tests := []struct {
+		username string
+		expected bool
+	}{
+		{"root", true},
+		{"servon-no-such-user", false},
+	}
+
+	for _, tt := range tests {
+		exists, err := u.UserExists(tt.username)
+		if err != nil {
+			t.Errorf("UserExists(%q) returned error: %v", tt.username, err)
+			continue
+		}
+		if exists != tt.expected {
+			t.Errorf("Expected UserExists(%q) to be %v, got %v", tt.username, tt.expected, exists)
+		}
+	}
+}
@@ -219,6 +219,54 @@ func (p *FileUtil) CopyDir(srcDir, destDir string) error {
 	return copy.Copy(srcDir, destDir, opt)
 }
 
+// CopyTree 复制目录并保留其中的相对软链接，用于发布构建好的项目
+// node_modules/.bin 中的命令是指向包内脚本的相对软链接，复制为普通文件后脚本中的相对路径会失效；
+// 绝对路径或指向目录外的软链接仍复制指向的内容，避免源目录删除后失效
+func (p *FileUtil) CopyTree(srcDir, destDir string) error {
+	srcInfo, err := os.Stat(srcDir)
+	if err != nil {
+		return fmt.Errorf("源目录不存在或无法访问: %v", err)
+	}
+	if !srcInfo.IsDir() {
+		return fmt.Errorf("源路径不是目录: %s", srcDir)
+	}
+
+	root, err := filepath.Abs(srcDir)
+	if err != nil {
+		return err
+	}
+
+	opt := copy.Options{
+		OnSymlink: func(src string) copy.SymlinkAction {
+			if isRelativeLinkInside(root, src) {
+				return copy.Shallow
+			}
+			return copy.Deep
+		},
+		OnDirExists: func(src, dest string) copy.DirExistsAction {
+			return copy.Merge
+		},
+	}
+
+	return copy.Copy(srcDir, destDir, opt)
+}
+
+// isRelativeLinkInside 判断软链接是否为相对路径，且指向 root 目录之内
+func isRelativeLinkInside(root, link string) bool {
+	target, err := os.Readlink(link)
+	if err != nil || filepath.IsAbs(target) {
+		return false
+	}
+
+	absLink, err := filepath.Abs(link)
+	if err != nil {
+		return false
+	}
+	resolved := filepath.Join(filepath.Dir(absLink), target)
+	rel, err := filepath.Rel(root, resolved)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // CopyFile 复制文件
 func (p *FileUtil) CopyFile(srcPath, destPath string) error {
 	src, err := os.Open(srcPath)
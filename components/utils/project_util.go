@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
 )
@@ -22,10 +23,13 @@ func (p *ProjectUtil) DetectProjectType(projectPath string) string {
 		return "laravel"
 	}
 
-	// Astro 项目特征
-	if fileExists(filepath.Join(projectPath, "astro.config.mjs")) ||
-		fileExists(filepath.Join(projectPath, "astro.config.js")) {
-		return "astro"
+	// Astro 项目特征，只有使用 @astrojs/node 适配器的项目需要运行 Node 服务，
+	// 默认的静态输出构建到 dist，按静态站点部署
+	if anyFileExists(projectPath, "astro.config.mjs", "astro.config.js", "astro.config.ts") {
+		if hasDependency(projectPath, "@astrojs/node") {
+			return "astro"
+		}
+		return "static"
 	}
 
 	// Flutter 项目特征
@@ -35,28 +39,98 @@ func (p *ProjectUtil) DetectProjectType(projectPath string) string {
 	}
 
 	// Next.js 项目特征
-	if fileExists(filepath.Join(projectPath, "next.config.js")) {
+	if anyFileExists(projectPath, "next.config.js", "next.config.mjs", "next.config.ts") {
 		return "nextjs"
 	}
 
 	// Nuxt.js 项目特征
-	if fileExists(filepath.Join(projectPath, "nuxt.config.ts")) {
+	if anyFileExists(projectPath, "nuxt.config.ts", "nuxt.config.js", "nuxt.config.mjs") {
 		return "nuxtjs"
 	}
 
-	// Remix 项目特征
-	if fileExists(filepath.Join(projectPath, "remix.config.js")) {
+	// Remix 项目特征，使用 Vite 的 Remix 项目没有 remix.config.js
+	if anyFileExists(projectPath, "remix.config.js", "remix.config.mjs") ||
+		hasDependency(projectPath, "@remix-run/dev") {
 		return "remix"
 	}
 
-	// Svelte 项目特征
-	if fileExists(filepath.Join(projectPath, "svelte.config.js")) {
-		return "svelte"
+	// Svelte 项目特征，普通的 Svelte + Vite 单页应用也有 svelte.config.js，
+	// 只有 SvelteKit 项目按 Node 服务部署，使用 adapter-static 的 SvelteKit 项目按静态站点部署
+	if anyFileExists(projectPath, "svelte.config.js", "svelte.config.mjs", "svelte.config.ts") {
+		if hasDependency(projectPath, "@sveltejs/kit") && !hasDependency(projectPath, "@sveltejs/adapter-static") {
+			return "svelte"
+		}
+		return "static"
+	}
+
+	// 后端服务项目特征，放在静态站点之前，附带 index.html 的后端项目仍按后端部署
+	if fileExists(filepath.Join(projectPath, "go.mod")) {
+		return "go"
+	}
+
+	if fileExists(filepath.Join(projectPath, "Cargo.toml")) {
+		return "rust"
+	}
+
+	if anyFileExists(projectPath, "pyproject.toml", "requirements.txt") {
+		return "python"
+	}
+
+	// 静态站点特征：Hugo 站点、Vite 或 Create React App 构建的前端项目、根目录的 index.html
+	if p.IsHugoSite(projectPath) ||
+		anyFileExists(projectPath, "vite.config.js", "vite.config.ts", "vite.config.mjs") ||
+		hasDependency(projectPath, "react-scripts") ||
+		fileExists(filepath.Join(projectPath, "index.html")) {
+		return "static"
+	}
+
+	// 其他类型都不匹配时，有 Dockerfile 或 Compose 文件的项目通过 Docker 部署
+	if anyFileExists(projectPath, "Dockerfile", "compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml") {
+		return "docker"
 	}
 
 	return "unknown"
 }
 
+// IsHugoSite 判断是否为 Hugo 站点
+func (p *ProjectUtil) IsHugoSite(projectPath string) bool {
+	if anyFileExists(projectPath, "hugo.toml", "hugo.yaml", "hugo.json") {
+		return true
+	}
+	return fileExists(filepath.Join(projectPath, "config.toml")) &&
+		dirExists(filepath.Join(projectPath, "content"))
+}
+
+// anyFileExists 检查目录中是否存在任一文件
+func anyFileExists(dir string, names ...string) bool {
+	for _, name := range names {
+		if fileExists(filepath.Join(dir, name)) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasDependency 检查 package.json 的 dependencies 或 devDependencies 中是否包含指定的包
+func hasDependency(dir, name string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return false
+	}
+
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return false
+	}
+
+	_, inDeps := pkg.Dependencies[name]
+	_, inDevDeps := pkg.DevDependencies[name]
+	return inDeps || inDevDeps
+}
+
 // fileExists 检查文件是否存在
 func fileExists(path string) bool {
 	info, err := os.Stat(path)
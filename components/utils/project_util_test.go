@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestDetectProjectTypeFrontend 测试 Svelte 和 Astro 项目按构建输出选择 Node 服务或静态站点部署
+func TestDetectProjectTypeFrontend(t *testing.T) {
+	cases := []struct {
+		name   string
+		config string
+		deps   string
+		want   string
+	}{
+		{"svelte spa", "svelte.config.js", `{"devDependencies": {"svelte": "^4.0.0", "vite": "^5.0.0"}}`, "static"},
+		{"sveltekit node", "svelte.config.js", `{"devDependencies": {"@sveltejs/kit": "^2.0.0", "@sveltejs/adapter-node": "^5.0.0"}}`, "svelte"},
+		{"sveltekit static", "svelte.config.js", `{"devDependencies": {"@sveltejs/kit": "^2.0.0", "@sveltejs/adapter-static": "^3.0.0"}}`, "static"},
+		{"astro static", "astro.config.mjs", `{"dependencies": {"astro": "^4.0.0"}}`, "static"},
+		{"astro node", "astro.config.mjs", `{"dependencies": {"astro": "^4.0.0", "@astrojs/node": "^8.0.0"}}`, "astro"},
+	}
+	for _, c := range cases {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, c.config), []byte("export default {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "package.json"), []byte(c.deps), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := DefaultProjectUtil.DetectProjectType(dir); got != c.want {
+			t.Errorf("%s: expected %s, got %s", c.name, c.want, got)
+		}
+	}
+}
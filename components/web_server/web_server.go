@@ -241,6 +241,11 @@ func (ws *WebServer) SetHost(host string) {
 	ws.config.Host = host
 }
 
+// IsDaemonProcess 判断当前进程是否为 RunInBackground 启动的后台服务器进程
+func IsDaemonProcess() bool {
+	return daemon.WasReborn()
+}
+
 // RunInBackground 在后台运行服务器（作为独立进程）
 func (ws *WebServer) RunInBackground() error {
 	// 检查服务器是否已经在运行
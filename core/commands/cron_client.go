@@ -0,0 +1,147 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"servon/components/cron_util"
+	"strings"
+	"time"
+)
+
+// cronBackend 定时任务命令的执行方式
+// 服务器运行时通过 Web API 操作服务器中的任务，否则直接使用 CronManager 读写任务文件
+type cronBackend interface {
+	GetCronTasks() ([]*cron_util.CronTask, error)
+	CreateCronTask(task cron_util.CronTask) (*cron_util.CronTask, error)
+	UpdateCronTask(task cron_util.CronTask) (*cron_util.CronTask, error)
+	DeleteCronTask(id int) error
+	ToggleCronTask(id int) (*cron_util.CronTask, error)
+	RunCronTask(id int) (*cron_util.CronRun, error)
+	GetCronTaskRuns(id int) ([]cron_util.CronRun, error)
+}
+
+// cronAPIClient 通过 Web API 管理运行中的服务器的定时任务
+type cronAPIClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// newCronAPIClient 创建访问 baseURL 的客户端，token 为具有 cron 作用域的 API 令牌
+func newCronAPIClient(baseURL, token string) *cronAPIClient {
+	return &cronAPIClient{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		token:   token,
+		client:  &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// isServerRunning 通过健康检查接口判断服务器是否在运行
+func isServerRunning(baseURL string) bool {
+	client := &http.Client{Timeout: time.Second}
+	resp, err := client.Get(strings.TrimRight(baseURL, "/") + "/health")
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	return resp.StatusCode == http.StatusOK
+}
+
+func (c *cronAPIClient) GetCronTasks() ([]*cron_util.CronTask, error) {
+	var tasks []*cron_util.CronTask
+	return tasks, c.do(http.MethodGet, "/cron/tasks", nil, &tasks)
+}
+
+func (c *cronAPIClient) CreateCronTask(task cron_util.CronTask) (*cron_util.CronTask, error) {
+	var created cron_util.CronTask
+	if err := c.do(http.MethodPost, "/cron/tasks", task, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+func (c *cronAPIClient) UpdateCronTask(task cron_util.CronTask) (*cron_util.CronTask, error) {
+	var updated cron_util.CronTask
+	if err := c.do(http.MethodPut, fmt.Sprintf("/cron/tasks/%d", task.ID), task, &updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+func (c *cronAPIClient) DeleteCronTask(id int) error {
+	return c.do(http.MethodDelete, fmt.Sprintf("/cron/tasks/%d", id), nil, nil)
+}
+
+func (c *cronAPIClient) ToggleCronTask(id int) (*cron_util.CronTask, error) {
+	var task cron_util.CronTask
+	if err := c.do(http.MethodPost, fmt.Sprintf("/cron/tasks/%d/toggle", id), nil, &task); err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+func (c *cronAPIClient) RunCronTask(id int) (*cron_util.CronRun, error) {
+	var run cron_util.CronRun
+	if err := c.do(http.MethodPost, fmt.Sprintf("/cron/tasks/%d/run", id), nil, &run); err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (c *cronAPIClient) GetCronTaskRuns(id int) ([]cron_util.CronRun, error) {
+	var runs []cron_util.CronRun
+	return runs, c.do(http.MethodGet, fmt.Sprintf("/cron/tasks/%d/runs", id), nil, &runs)
+}
+
+// do 发送请求并把响应解析到 result，响应不是 2xx 时返回接口返回的错误信息
+func (c *cronAPIClient) do(method, path string, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("序列化请求失败: %v", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("创建请求失败: %v", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("请求服务器失败: %v", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取响应失败: %v", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("%s", apiErr.Error)
+		}
+		return fmt.Errorf("服务器返回 %s", resp.Status)
+	}
+
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("解析响应失败: %v", err)
+	}
+	return nil
+}
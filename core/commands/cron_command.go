@@ -0,0 +1,659 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"servon/components/cron_util"
+	"servon/core/managers"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultServerURL 本机服务器的默认地址，与 servon server start 的默认端口一致
+const defaultServerURL = "http://127.0.0.1:9754"
+
+// cronTokenTTL CLI 访问服务器时使用的临时令牌的有效期，命令结束后立即吊销
+const cronTokenTTL = 10 * time.Minute
+
+// cronRunPollInterval 等待任务执行结束时查询执行记录的间隔
+const cronRunPollInterval = 500 * time.Millisecond
+
+// GetCronCommand 返回 cron 命令
+func GetCronCommand(manager *managers.FullManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "cron",
+		Short: "管理定时任务",
+	})
+
+	cmd.PersistentFlags().String("server", defaultServerURL, "服务器地址，服务器运行时通过其 API 管理任务")
+
+	cmd.AddCommand(newCronListCmd(manager))
+	cmd.AddCommand(newCronAddCmd(manager))
+	cmd.AddCommand(newCronEditCmd(manager))
+	cmd.AddCommand(newCronRemoveCmd(manager))
+	cmd.AddCommand(newCronEnableCmd(manager, true))
+	cmd.AddCommand(newCronEnableCmd(manager, false))
+	cmd.AddCommand(newCronRunCmd(manager))
+	cmd.AddCommand(newCronLogsCmd(manager))
+	cmd.AddCommand(newCronImportCmd(manager))
+	cmd.AddCommand(newCronExportCmd(manager))
+
+	return cmd
+}
+
+// withCronBackend 选择任务的操作方式后执行 fn
+// 服务器运行时签发临时令牌通过 API 操作，保证命令行和面板看到的是同一份任务；否则直接读写任务文件
+func withCronBackend(cmd *cobra.Command, manager *managers.FullManager, fn func(backend cronBackend) error) error {
+	server, _ := cmd.Flags().GetString("server")
+	if !isServerRunning(server) {
+		return fn(manager.CronManager)
+	}
+
+	plain, token, err := manager.TokenManager.CreateToken("cli-cron", []string{"cron"}, cronTokenTTL)
+	if err != nil {
+		return fmt.Errorf("签发访问服务器的令牌失败: %v", err)
+	}
+	defer manager.TokenManager.RevokeToken(token.ID)
+
+	return fn(newCronAPIClient(server, plain))
+}
+
+// newCronListCmd 返回 list 子命令
+func newCronListCmd(manager *managers.FullManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:     "list",
+		Short:   "列出所有定时任务",
+		Aliases: []string{"l", "ls"},
+		Run: func(cmd *cobra.Command, args []string) {
+			err := withCronBackend(cmd, manager, func(backend cronBackend) error {
+				tasks, err := backend.GetCronTasks()
+				if err != nil {
+					return err
+				}
+
+				if len(tasks) == 0 {
+					PrintInfo("暂无定时任务，使用 servon cron add 创建")
+					return nil
+				}
+
+				fmt.Printf("%-5s %-20s %-22s %-8s %-10s %-20s %s\n", "ID", "NAME", "SCHEDULE", "ENABLED", "STATUS", "NEXT RUN", "COMMAND")
+				for _, task := range tasks {
+					fmt.Printf("%-5d %-20s %-22s %-8s %-10s %-20s %s\n",
+						task.ID,
+						task.Name,
+						task.Schedule,
+						stringUtil.GetEmojiForBool(task.Enabled),
+						valueOr(task.LastStatus, "-"),
+						nextRunText(task),
+						task.Command,
+					)
+				}
+				return nil
+			})
+			if err != nil {
+				PrintErrorf("获取定时任务失败: %v", err)
+			}
+		},
+	})
+}
+
+// newCronAddCmd 返回 add 子命令
+func newCronAddCmd(manager *managers.FullManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "add",
+		Short: "创建定时任务",
+		Run: func(cmd *cobra.Command, args []string) {
+			var task cron_util.CronTask
+			if err := applyCronFlags(cmd, &task); err != nil {
+				PrintErrorf("%v", err)
+				return
+			}
+			if !printNextRuns(task) {
+				return
+			}
+
+			err := withCronBackend(cmd, manager, func(backend cronBackend) error {
+				created, err := backend.CreateCronTask(task)
+				if err != nil {
+					return err
+				}
+				PrintSuccessf("定时任务 %s 已创建 (ID: %d)", created.Name, created.ID)
+				return nil
+			})
+			if err != nil {
+				PrintErrorf("创建定时任务失败: %v", err)
+			}
+		},
+	})
+
+	addCronFlags(cmd)
+	cmd.MarkFlagRequired("name")
+	cmd.MarkFlagRequired("command")
+	cmd.MarkFlagRequired("schedule")
+
+	return cmd
+}
+
+// newCronEditCmd 返回 edit 子命令，只修改指定了的字段
+func newCronEditCmd(manager *managers.FullManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "edit [id]",
+		Short: "修改定时任务",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := parseCronTaskID(args[0])
+			if err != nil {
+				PrintErrorf("%v", err)
+				return
+			}
+
+			err = withCronBackend(cmd, manager, func(backend cronBackend) error {
+				task, err := findCronTask(backend, id)
+				if err != nil {
+					return err
+				}
+				if err := applyCronFlags(cmd, task); err != nil {
+					return err
+				}
+				if cmd.Flags().Changed("schedule") || cmd.Flags().Changed("timezone") {
+					if !printNextRuns(*task) {
+						return fmt.Errorf("定时表达式无效")
+					}
+				}
+
+				updated, err := backend.UpdateCronTask(*task)
+				if err != nil {
+					return err
+				}
+				PrintSuccessf("定时任务 %s 已更新", updated.Name)
+				return nil
+			})
+			if err != nil {
+				PrintErrorf("修改定时任务失败: %v", err)
+			}
+		},
+	})
+
+	addCronFlags(cmd)
+
+	return cmd
+}
+
+// newCronRemoveCmd 返回 rm 子命令
+func newCronRemoveCmd(manager *managers.FullManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:     "rm [id]",
+		Short:   "删除定时任务",
+		Aliases: []string{"remove", "delete"},
+		Args:    cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := parseCronTaskID(args[0])
+			if err != nil {
+				PrintErrorf("%v", err)
+				return
+			}
+
+			err = withCronBackend(cmd, manager, func(backend cronBackend) error {
+				return backend.DeleteCronTask(id)
+			})
+			if err != nil {
+				PrintErrorf("删除定时任务失败: %v", err)
+				return
+			}
+			PrintSuccessf("定时任务 %d 已删除", id)
+		},
+	})
+}
+
+// newCronEnableCmd 返回 enable 或 disable 子命令，任务已处于目标状态时不做修改
+func newCronEnableCmd(manager *managers.FullManager, enable bool) *cobra.Command {
+	use, short, action := "disable [id]", "禁用定时任务", "禁用"
+	if enable {
+		use, short, action = "enable [id]", "启用定时任务", "启用"
+	}
+
+	return NewCommand(CommandOptions{
+		Use:   use,
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := parseCronTaskID(args[0])
+			if err != nil {
+				PrintErrorf("%v", err)
+				return
+			}
+
+			err = withCronBackend(cmd, manager, func(backend cronBackend) error {
+				task, err := findCronTask(backend, id)
+				if err != nil {
+					return err
+				}
+				if task.Enabled != enable {
+					if _, err := backend.ToggleCronTask(id); err != nil {
+						return err
+					}
+				}
+				PrintSuccessf("定时任务 %s 已%s", task.Name, action)
+				return nil
+			})
+			if err != nil {
+				PrintErrorf("%s定时任务失败: %v", action, err)
+			}
+		},
+	})
+}
+
+// newCronRunCmd 返回 run 子命令，默认等待执行结束并输出结果
+func newCronRunCmd(manager *managers.FullManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "run [id]",
+		Short: "立即执行定时任务",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := parseCronTaskID(args[0])
+			if err != nil {
+				PrintErrorf("%v", err)
+				return
+			}
+			detach, _ := cmd.Flags().GetBool("detach")
+
+			err = withCronBackend(cmd, manager, func(backend cronBackend) error {
+				run, err := backend.RunCronTask(id)
+				if err != nil {
+					return err
+				}
+				// 服务器未运行时任务在当前进程中执行，必须等待执行结束
+				if _, local := backend.(*managers.CronManager); detach && !local {
+					PrintInfof("任务已开始执行，使用 servon cron logs %d 查看结果", id)
+					return nil
+				}
+
+				PrintInfof("任务开始执行，等待执行结束...")
+				for run.FinishedAt.IsZero() {
+					time.Sleep(cronRunPollInterval)
+					if run, err = findCronRun(backend, id, run.ID); err != nil {
+						return err
+					}
+				}
+				printCronRun(*run)
+				return nil
+			})
+			if err != nil {
+				PrintErrorf("执行定时任务失败: %v", err)
+			}
+		},
+	})
+
+	cmd.Flags().BoolP("detach", "d", false, "不等待执行结束，仅在服务器运行时有效")
+
+	return cmd
+}
+
+// newCronLogsCmd 返回 logs 子命令
+func newCronLogsCmd(manager *managers.FullManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "logs [id]",
+		Short: "查看定时任务的执行记录",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			id, err := parseCronTaskID(args[0])
+			if err != nil {
+				PrintErrorf("%v", err)
+				return
+			}
+			runID, _ := cmd.Flags().GetInt("run")
+
+			err = withCronBackend(cmd, manager, func(backend cronBackend) error {
+				runs, err := backend.GetCronTaskRuns(id)
+				if err != nil {
+					return err
+				}
+
+				if len(runs) == 0 {
+					PrintInfo("暂无执行记录，执行记录保存在服务器进程中，服务器重启后清空")
+					return nil
+				}
+
+				if runID > 0 {
+					for _, run := range runs {
+						if run.ID == runID {
+							printCronRun(run)
+							return nil
+						}
+					}
+					return fmt.Errorf("执行记录不存在: %d", runID)
+				}
+
+				fmt.Printf("%-6s %-10s %-8s %-10s %-6s %-20s %s\n", "RUN", "TRIGGER", "ATTEMPT", "STATUS", "EXIT", "STARTED", "DURATION")
+				for _, run := range runs {
+					fmt.Printf("%-6d %-10s %-8d %-10s %-6d %-20s %.1fs\n",
+						run.ID,
+						run.Trigger,
+						run.Attempt,
+						run.Status,
+						run.ExitCode,
+						run.StartedAt.Format("2006-01-02 15:04:05"),
+						run.Duration,
+					)
+				}
+
+				fmt.Println()
+				printCronRun(runs[0])
+				return nil
+			})
+			if err != nil {
+				PrintErrorf("获取执行记录失败: %v", err)
+			}
+		},
+	})
+
+	cmd.Flags().Int("run", 0, "只显示指定执行记录的输出，默认显示列表和最近一次的输出")
+
+	return cmd
+}
+
+// newCronImportCmd 返回 import 子命令，从 crontab 文件导入任务
+func newCronImportCmd(manager *managers.FullManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "import",
+		Short: "从 crontab 文件导入定时任务",
+		Run: func(cmd *cobra.Command, args []string) {
+			from, _ := cmd.Flags().GetString("from")
+			username, _ := cmd.Flags().GetString("user")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+
+			system := cron_util.IsSystemCrontab(from)
+			if cmd.Flags().Changed("system") {
+				system, _ = cmd.Flags().GetBool("system")
+			}
+
+			content, err := os.ReadFile(from)
+			if err != nil {
+				PrintErrorf("读取 %s 失败: %v", from, err)
+				return
+			}
+
+			tasks, warnings := cron_util.ParseCrontab(string(content), from, system)
+			for _, warning := range warnings {
+				logger.Warnf("跳过 %s", warning)
+			}
+
+			// 系统 crontab 只导入该用户的任务，用户 crontab 中的任务以该用户身份执行
+			if username != "" {
+				selected := tasks[:0]
+				for _, task := range tasks {
+					if system && task.User != username {
+						continue
+					}
+					task.User = username
+					selected = append(selected, task)
+				}
+				tasks = selected
+			}
+
+			if len(tasks) == 0 {
+				PrintInfo("没有可导入的任务")
+				return
+			}
+
+			if dryRun {
+				for _, task := range tasks {
+					fmt.Printf("%-22s %-10s %s\n", task.Schedule, valueOr(task.User, "-"), task.Command)
+				}
+				PrintInfof("共 %d 个任务，未导入", len(tasks))
+				return
+			}
+
+			err = withCronBackend(cmd, manager, func(backend cronBackend) error {
+				imported := 0
+				for _, task := range tasks {
+					if _, err := backend.CreateCronTask(task); err != nil {
+						PrintErrorf("导入 %s 失败: %v", task.Name, err)
+						continue
+					}
+					imported++
+				}
+				PrintSuccessf("已导入 %d/%d 个任务", imported, len(tasks))
+				return nil
+			})
+			if err != nil {
+				PrintErrorf("导入定时任务失败: %v", err)
+			}
+		},
+	})
+
+	cmd.Flags().String("from", "", "crontab 文件路径，如 /etc/crontab")
+	cmd.Flags().String("user", "", "系统 crontab 中只导入该用户的任务；用户 crontab 中的任务以该用户身份执行")
+	cmd.Flags().Bool("system", false, "按带用户列的系统 crontab 格式解析，默认根据路径判断")
+	cmd.Flags().Bool("dry-run", false, "只显示解析结果，不导入")
+	cmd.MarkFlagRequired("from")
+
+	return cmd
+}
+
+// newCronExportCmd 返回 export 子命令，把任务导出为 crontab 格式
+func newCronExportCmd(manager *managers.FullManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "export",
+		Short: "把定时任务导出为 crontab 格式",
+		Run: func(cmd *cobra.Command, args []string) {
+			output, _ := cmd.Flags().GetString("output")
+			system, _ := cmd.Flags().GetBool("system")
+
+			err := withCronBackend(cmd, manager, func(backend cronBackend) error {
+				tasks, err := backend.GetCronTasks()
+				if err != nil {
+					return err
+				}
+
+				content := cron_util.FormatCrontab(tasks, system)
+				if output == "" {
+					fmt.Print(content)
+					return nil
+				}
+
+				if err := os.WriteFile(output, []byte(content), 0644); err != nil {
+					return fmt.Errorf("写入 %s 失败: %v", output, err)
+				}
+				PrintSuccessf("已导出 %d 个任务到 %s", len(tasks), output)
+				return nil
+			})
+			if err != nil {
+				PrintErrorf("导出定时任务失败: %v", err)
+			}
+		},
+	})
+
+	cmd.Flags().StringP("output", "o", "", "输出文件路径，默认输出到终端")
+	cmd.Flags().Bool("system", false, "输出带用户列的系统 crontab 格式，可放入 /etc/cron.d")
+
+	return cmd
+}
+
+// addCronFlags 添加 add 和 edit 共用的任务字段
+func addCronFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("name", "n", "", "任务名称")
+	cmd.Flags().StringP("command", "c", "", "执行的命令，通过 sh -c 执行")
+	cmd.Flags().StringP("schedule", "s", "", "定时表达式，第一位为秒，如 \"0 30 3 * * *\" 或 @daily")
+	cmd.Flags().String("description", "", "任务描述")
+	cmd.Flags().String("work-dir", "", "工作目录，必须是绝对路径")
+	cmd.Flags().String("user", "", "以该用户身份执行")
+	cmd.Flags().StringArrayP("env", "e", nil, "环境变量，格式为 KEY=VALUE，可重复指定")
+	cmd.Flags().Int("timeout", 0, "最长执行时间（秒），0 表示不限制")
+	cmd.Flags().String("concurrency", "", "上一次执行未结束时的处理方式: allow、skip、queue")
+	cmd.Flags().String("timezone", "", "定时表达式使用的时区，如 Asia/Shanghai")
+	cmd.Flags().Int("jitter", 0, "触发后随机延迟的最长时间（秒）")
+	cmd.Flags().Int("retries", 0, "失败后的重试次数")
+	cmd.Flags().Int("retry-delay", 0, "第一次重试前等待的时间（秒），之后每次翻倍")
+}
+
+// applyCronFlags 把命令行中指定了的字段写入任务
+func applyCronFlags(cmd *cobra.Command, task *cron_util.CronTask) error {
+	flags := cmd.Flags()
+
+	texts := map[string]*string{
+		"name":        &task.Name,
+		"command":     &task.Command,
+		"schedule":    &task.Schedule,
+		"description": &task.Description,
+		"work-dir":    &task.WorkDir,
+		"user":        &task.User,
+		"concurrency": &task.Concurrency,
+		"timezone":    &task.Timezone,
+	}
+	for name, field := range texts {
+		if flags.Changed(name) {
+			*field, _ = flags.GetString(name)
+		}
+	}
+
+	ints := map[string]*int{
+		"timeout":     &task.Timeout,
+		"jitter":      &task.Jitter,
+		"retries":     &task.Retries,
+		"retry-delay": &task.RetryDelay,
+	}
+	for name, field := range ints {
+		if flags.Changed(name) {
+			*field, _ = flags.GetInt(name)
+		}
+	}
+
+	if flags.Changed("env") {
+		values, _ := flags.GetStringArray("env")
+		env, err := parseCronEnv(values)
+		if err != nil {
+			return err
+		}
+		task.Env = env
+	}
+	return nil
+}
+
+// parseCronEnv 解析 KEY=VALUE 格式的环境变量
+func parseCronEnv(values []string) (map[string]string, error) {
+	env := make(map[string]string, len(values))
+	for _, value := range values {
+		key, val, ok := strings.Cut(value, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("无效的环境变量: %s，格式应为 KEY=VALUE", value)
+		}
+		env[key] = val
+	}
+	return env, nil
+}
+
+// printNextRuns 校验任务的定时表达式并输出之后的 5 次执行时间，表达式无效时返回 false
+func printNextRuns(task cron_util.CronTask) bool {
+	times, err := cron_util.NextRuns(task.Schedule, task.Timezone, 5)
+	if err != nil {
+		PrintErrorf("%v", err)
+		return false
+	}
+
+	list := make([]string, 0, len(times))
+	for _, t := range times {
+		list = append(list, t.Format("2006-01-02 15:04:05 MST"))
+	}
+	PrintListWithTitle("接下来的执行时间", list)
+	return true
+}
+
+// findCronTask 按ID查找任务
+func findCronTask(backend cronBackend, id int) (*cron_util.CronTask, error) {
+	tasks, err := backend.GetCronTasks()
+	if err != nil {
+		return nil, err
+	}
+	for _, task := range tasks {
+		if task.ID == id {
+			return task, nil
+		}
+	}
+	return nil, fmt.Errorf("任务不存在: %d", id)
+}
+
+// findCronRun 按ID查找任务的执行记录
+func findCronRun(backend cronBackend, taskID, runID int) (*cron_util.CronRun, error) {
+	runs, err := backend.GetCronTaskRuns(taskID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range runs {
+		if runs[i].ID == runID {
+			return &runs[i], nil
+		}
+	}
+	return nil, fmt.Errorf("执行记录不存在: %d", runID)
+}
+
+// printCronRun 输出一次执行的状态和输出
+func printCronRun(run cron_util.CronRun) {
+	info := map[string]string{
+		"Run":      strconv.Itoa(run.ID),
+		"Status":   run.Status,
+		"Exit":     strconv.Itoa(run.ExitCode),
+		"Started":  run.StartedAt.Format("2006-01-02 15:04:05"),
+		"Duration": fmt.Sprintf("%.1fs", run.Duration),
+	}
+	if run.Error != "" {
+		info["Error"] = run.Error
+	}
+	PrintKeyValues(info)
+
+	for _, output := range []struct {
+		title   string
+		content string
+	}{
+		{"STDOUT", run.Stdout},
+		{"STDERR", run.Stderr},
+	} {
+		if strings.TrimSpace(output.content) == "" {
+			continue
+		}
+		fmt.Println()
+		fmt.Println(output.title + ":")
+		fmt.Print(output.content)
+		if !strings.HasSuffix(output.content, "\n") {
+			fmt.Println()
+		}
+	}
+}
+
+// nextRunText 返回任务下一次执行时间的显示文本
+// 服务器未运行时调度器没有启动，根据定时表达式计算
+func nextRunText(task *cron_util.CronTask) string {
+	if !task.Enabled {
+		return "-"
+	}
+	next := task.NextRun
+	if next.IsZero() {
+		if times, err := cron_util.NextRuns(task.Schedule, task.Timezone, 1); err == nil && len(times) > 0 {
+			next = times[0]
+		}
+	}
+	if next.IsZero() {
+		return "-"
+	}
+	return next.Format("2006-01-02 15:04:05")
+}
+
+// parseCronTaskID 解析命令行中的任务ID
+func parseCronTaskID(value string) (int, error) {
+	id, err := strconv.Atoi(value)
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("无效的任务ID: %s", value)
+	}
+	return id, nil
+}
+
+// valueOr 值为空时返回 fallback
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
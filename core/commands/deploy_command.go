@@ -1,15 +1,24 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"os/signal"
+	"os/user"
 	"servon/core/managers"
+	"servon/core/models"
+	"strconv"
+	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 // deployRootCommand 部署根命令
 func makeDeployCommand(manager *managers.FullManager) *cobra.Command {
-	return NewCommand(CommandOptions{
+	cmd := NewCommand(CommandOptions{
 		Use:     "deploy [repository]",
 		Short:   "部署项目",
 		Aliases: []string{"d"},
@@ -37,12 +46,357 @@ func makeDeployCommand(manager *managers.FullManager) *cobra.Command {
 				fmt.Println("\n示例:")
 				fmt.Println("  servon deploy username/project")
 				fmt.Println("  servon deploy https://github.com/username/project")
+				fmt.Println("  servon deploy history [project]")
+				fmt.Println("  servon deploy show <id>")
+				fmt.Println("  servon deploy cancel <id>")
+				fmt.Println("  servon deploy releases <project>")
+				fmt.Println("  servon deploy rollback <project> [release]")
+				return
+			}
+
+			branch, _ := cmd.Flags().GetString("branch")
+			triggeredBy := ""
+			if u, err := user.Current(); err == nil {
+				triggeredBy = u.Username
+			}
+
+			record, err := deployManager.EnqueueDeploy(managers.DeployRequest{
+				Repo:        args[0],
+				Branch:      branch,
+				Trigger:     models.DeployTriggerCLI,
+				TriggeredBy: triggeredBy,
+			})
+			if err != nil {
+				PrintErrorf("部署失败: %v", err)
+				return
+			}
+
+			PrintInfof("部署 %s 已加入队列，按 Ctrl+C 取消部署", record.ID)
+			record, err = followDeploy(deployManager, record.ID, true)
+			if err != nil {
+				PrintErrorf("读取部署输出失败: %v", err)
 				return
 			}
 
-			deployManager.DeployProject(args[0])
+			printDeployResult(record)
 		},
 	})
+
+	cmd.Flags().StringP("branch", "b", "", "部署的分支，默认使用 servon.yaml 中的 branch 或仓库默认分支")
+
+	cmd.AddCommand(newDeployHistoryCmd(manager.DeployManager))
+	cmd.AddCommand(newDeployShowCmd(manager.DeployManager))
+	cmd.AddCommand(newDeployReleasesCmd(manager.DeployManager))
+	cmd.AddCommand(newDeployRollbackCmd(manager.DeployManager))
+	cmd.AddCommand(newDeployKeepReleasesCmd(manager.DeployManager))
+	cmd.AddCommand(newDeployCancelCmd(manager.DeployManager))
+	cmd.AddCommand(newDeployMaxConcurrentCmd(manager.DeployManager))
+
+	return cmd
+}
+
+// newDeployHistoryCmd 返回 history 子命令
+func newDeployHistoryCmd(m *managers.DeployManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "history [project]",
+		Short: "查看部署历史",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			project := ""
+			if len(args) > 0 {
+				project = args[0]
+			}
+			limit, _ := cmd.Flags().GetInt("limit")
+
+			records, err := m.ListDeployLogs(project, limit)
+			if err != nil {
+				PrintErrorf("获取部署历史失败: %v", err)
+				return
+			}
+
+			if len(records) == 0 {
+				PrintInfo("暂无部署记录")
+				return
+			}
+
+			fmt.Printf("%-20s %-20s %-10s %-9s %-10s %-8s %-19s %s\n", "ID", "PROJECT", "BRANCH", "COMMIT", "DEPLOYER", "TRIGGER", "STARTED", "STATUS")
+			for _, record := range records {
+				fmt.Printf("%-20s %-20s %-10s %-9s %-10s %-8s %-19s %s\n",
+					record.ID,
+					record.Project,
+					record.Branch,
+					shortCommit(record.Commit),
+					record.Deployer,
+					record.Trigger,
+					record.Timestamp.Format("2006-01-02 15:04:05"),
+					record.Status,
+				)
+			}
+		},
+	})
+
+	cmd.Flags().IntP("limit", "n", 20, "显示的记录数量，0 表示全部")
+
+	return cmd
+}
+
+// newDeployShowCmd 返回 show 子命令
+func newDeployShowCmd(m *managers.DeployManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "show <id>",
+		Short: "查看部署详情和输出",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			follow, _ := cmd.Flags().GetBool("follow")
+			if follow {
+				record, err := followDeploy(m, args[0], false)
+				if err != nil && !errors.Is(err, context.Canceled) {
+					PrintErrorf("读取部署输出失败: %v", err)
+					return
+				}
+				if err == nil {
+					printDeployResult(record)
+				}
+				return
+			}
+
+			record, err := m.GetDeployLog(args[0])
+			if err != nil {
+				PrintErrorf("获取部署记录失败: %v", err)
+				return
+			}
+
+			duration := ""
+			if !record.FinishedAt.IsZero() {
+				duration = record.FinishedAt.Sub(record.Timestamp).Round(time.Second).String()
+			}
+
+			PrintKeyValues(map[string]string{
+				"ID":          record.ID,
+				"Project":     record.Project,
+				"Repo":        record.Repo,
+				"Branch":      record.Branch,
+				"Commit":      record.Commit,
+				"Deployer":    record.Deployer,
+				"Trigger":     record.Trigger,
+				"TriggeredBy": record.TriggeredBy,
+				"StartedAt":   record.Timestamp.Format("2006-01-02 15:04:05"),
+				"Duration":    duration,
+				"Status":      record.Status,
+				"Message":     record.Message,
+			})
+
+			if record.HealthCheck != "" {
+				fmt.Println()
+				PrintInfo("健康检查输出:")
+				fmt.Println(record.HealthCheck)
+			}
+
+			fmt.Println()
+			fmt.Print(record.Content)
+		},
+	})
+
+	cmd.Flags().BoolP("follow", "f", false, "持续输出部署日志直到部署结束")
+
+	return cmd
+}
+
+// followDeploy 逐行打印部署输出直到部署结束
+// cancelOnInterrupt 为 true 时按 Ctrl+C 会取消部署并等待其结束，否则只停止跟踪
+func followDeploy(m *managers.DeployManager, id string, cancelOnInterrupt bool) (*models.DeployLog, error) {
+	ctx, stop := context.WithCancel(context.Background())
+	defer stop()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	go func() {
+		select {
+		case <-interrupt:
+			if !cancelOnInterrupt {
+				stop()
+				return
+			}
+			PrintInfof("正在取消部署 %s ...", id)
+			if _, err := m.CancelDeploy(id); err != nil {
+				PrintErrorf("取消部署失败: %v", err)
+			}
+		case <-ctx.Done():
+		}
+	}()
+
+	return m.FollowDeployOutput(ctx, id, func(line string) {
+		fmt.Println(line)
+	})
+}
+
+// printDeployResult 打印部署的最终结果
+func printDeployResult(record *models.DeployLog) {
+	switch record.Status {
+	case models.DeployStatusSuccess:
+		PrintSuccessf("部署 %s 成功", record.ID)
+	case models.DeployStatusSkipped, models.DeployStatusCancelled:
+		PrintInfof("部署 %s %s: %s", record.ID, record.Status, record.Message)
+	case models.DeployStatusRolledBack:
+		PrintErrorf("部署 %s %s", record.ID, record.Message)
+	default:
+		PrintErrorf("部署 %s 失败: %s", record.ID, record.Message)
+	}
+}
+
+// newDeployReleasesCmd 返回 releases 子命令
+func newDeployReleasesCmd(m *managers.DeployManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:   "releases <project>",
+		Short: "查看项目的发布版本",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			releases, err := m.ListReleases(args[0])
+			if err != nil {
+				PrintErrorf("获取发布版本失败: %v", err)
+				return
+			}
+
+			if len(releases) == 0 {
+				PrintInfof("项目 %s 暂无发布版本", args[0])
+				return
+			}
+
+			fmt.Printf("%-2s %-20s %-9s %s\n", "", "RELEASE", "COMMIT", "CREATED")
+			for _, release := range releases {
+				marker := ""
+				if release.Current {
+					marker = "*"
+				}
+				fmt.Printf("%-2s %-20s %-9s %s\n",
+					marker,
+					release.ID,
+					shortCommit(release.Commit),
+					release.CreatedAt.Format("2006-01-02 15:04:05"),
+				)
+			}
+		},
+	})
+}
+
+// newDeployRollbackCmd 返回 rollback 子命令
+func newDeployRollbackCmd(m *managers.DeployManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:   "rollback <project> [release]",
+		Short: "回滚项目到指定版本，不指定版本时回滚到上一个版本",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			releaseID := ""
+			if len(args) > 1 {
+				releaseID = args[1]
+			}
+
+			triggeredBy := ""
+			if u, err := user.Current(); err == nil {
+				triggeredBy = u.Username
+			}
+
+			release, err := m.Rollback(managers.RollbackRequest{
+				Project:     args[0],
+				Release:     releaseID,
+				Trigger:     models.DeployTriggerCLI,
+				TriggeredBy: triggeredBy,
+			})
+			if err != nil {
+				PrintErrorf("回滚失败: %v", err)
+				return
+			}
+
+			PrintSuccessf("项目 %s 已回滚到版本 %s", args[0], release.ID)
+		},
+	})
+}
+
+// newDeployKeepReleasesCmd 返回 keep-releases 子命令
+func newDeployKeepReleasesCmd(m *managers.DeployManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:   "keep-releases [n]",
+		Short: "查看或设置每个项目保留的发布版本数量",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			settings := m.GetDeploySettings()
+			if len(args) == 0 {
+				PrintInfof("每个项目保留 %d 个发布版本", settings.KeepReleases)
+				return
+			}
+
+			keep, err := strconv.Atoi(args[0])
+			if err != nil {
+				PrintErrorf("无效的数量: %s", args[0])
+				return
+			}
+
+			settings.KeepReleases = keep
+			if err := m.SaveDeploySettings(settings); err != nil {
+				PrintErrorf("保存部署配置失败: %v", err)
+				return
+			}
+
+			PrintSuccessf("每个项目将保留 %d 个发布版本，多余的版本在下次部署时清理", keep)
+		},
+	})
+}
+
+// newDeployCancelCmd 返回 cancel 子命令
+func newDeployCancelCmd(m *managers.DeployManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:   "cancel <id>",
+		Short: "取消排队中或正在进行的部署",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if _, err := m.CancelDeploy(args[0]); err != nil {
+				PrintErrorf("取消部署失败: %v", err)
+				return
+			}
+
+			PrintSuccessf("已请求取消部署 %s，可通过 servon deploy show %s 查看结果", args[0], args[0])
+		},
+	})
+}
+
+// newDeployMaxConcurrentCmd 返回 max-concurrent 子命令
+func newDeployMaxConcurrentCmd(m *managers.DeployManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:   "max-concurrent [n]",
+		Short: "查看或设置同时进行的部署数量上限",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			settings := m.GetDeploySettings()
+			if len(args) == 0 {
+				PrintInfof("最多同时进行 %d 个部署", settings.MaxConcurrent)
+				return
+			}
+
+			n, err := strconv.Atoi(args[0])
+			if err != nil {
+				PrintErrorf("无效的数量: %s", args[0])
+				return
+			}
+
+			settings.MaxConcurrent = n
+			if err := m.SaveDeploySettings(settings); err != nil {
+				PrintErrorf("保存部署配置失败: %v", err)
+				return
+			}
+
+			PrintSuccessf("最多同时进行 %d 个部署，同一项目的部署始终按顺序执行", n)
+		},
+	})
+}
+
+// shortCommit 返回提交 SHA 的短格式
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
 }
 
 func GetDeployCommand(manager *managers.FullManager) *cobra.Command {
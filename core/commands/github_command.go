@@ -0,0 +1,41 @@
+package commands
+
+import (
+	"servon/components/github"
+
+	"github.com/spf13/cobra"
+)
+
+// GetGitHubCommand 返回 github 命令
+func GetGitHubCommand(g *github.GitHubIntegration) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "github",
+		Short: "GitHub App 集成管理",
+	})
+
+	webhookCmd := NewCommand(CommandOptions{
+		Use:   "webhook",
+		Short: "管理 GitHub webhook",
+	})
+	webhookCmd.AddCommand(newRotateWebhookSecretCmd(g))
+
+	cmd.AddCommand(webhookCmd)
+
+	return cmd
+}
+
+// newRotateWebhookSecretCmd 返回 rotate-secret 子命令
+func newRotateWebhookSecretCmd(g *github.GitHubIntegration) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:   "rotate-secret",
+		Short: "轮换 webhook 密钥并同步到 GitHub App",
+		Run: func(cmd *cobra.Command, args []string) {
+			if _, err := g.RotateWebhookSecret(); err != nil {
+				PrintErrorf("轮换 webhook 密钥失败: %v", err)
+				return
+			}
+
+			PrintSuccessf("webhook 密钥已轮换，旧密钥将在 1 小时后失效")
+		},
+	})
+}
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"servon/core/managers"
+
+	"github.com/spf13/cobra"
+)
+
+// MakePasswdCommand 创建设置管理员密码的命令
+func MakePasswdCommand(auth *managers.AuthManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "passwd",
+		Short: "设置 Web 管理面板的管理员账号和密码",
+		Run: func(cmd *cobra.Command, args []string) {
+			username, _ := cmd.Flags().GetString("username")
+			password, _ := cmd.Flags().GetString("password")
+
+			if password == "" {
+				first, err := readPassword("请输入新密码: ")
+				if err != nil {
+					PrintErrorf("读取密码失败: %v", err)
+					return
+				}
+				second, err := readPassword("请再次输入新密码: ")
+				if err != nil {
+					PrintErrorf("读取密码失败: %v", err)
+					return
+				}
+				if first != second {
+					PrintErrorf("两次输入的密码不一致")
+					return
+				}
+				password = first
+			}
+
+			if err := auth.SetAdminPassword(username, password); err != nil {
+				PrintErrorf("设置管理员密码失败: %v", err)
+				return
+			}
+
+			PrintSuccessf("管理员 %s 的密码已更新，已有的登录会话全部失效", username)
+		},
+	})
+
+	cmd.Flags().StringP("username", "u", "admin", "管理员用户名")
+	cmd.Flags().String("password", "", "管理员密码，不提供时交互式输入")
+
+	return cmd
+}
+
+// readPassword 从终端读取密码，读取期间关闭回显
+func readPassword(prompt string) (string, error) {
+	fmt.Print(prompt)
+
+	if err := setTerminalEcho(false); err == nil {
+		defer func() {
+			setTerminalEcho(true)
+			fmt.Println()
+		}()
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	input, err := reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(input, "\r\n"), nil
+}
+
+// setTerminalEcho 开启或关闭终端回显
+func setTerminalEcho(on bool) error {
+	mode := "-echo"
+	if on {
+		mode = "echo"
+	}
+
+	cmd := exec.Command("stty", mode)
+	cmd.Stdin = os.Stdin
+	return cmd.Run()
+}
@@ -27,7 +27,8 @@ func GetServerCommand(web *web_server.WebServer, manager *managers.FullManager)
 
 	cmd.AddCommand(MakeStartCommand(web, manager))
 	cmd.AddCommand(MakeStopCommand(web))
-	cmd.AddCommand(MakeRestartCommand(web))
+	cmd.AddCommand(MakeRestartCommand(web, manager))
+	cmd.AddCommand(MakePasswdCommand(manager.AuthManager))
 
 	return cmd
 }
@@ -54,6 +55,13 @@ func MakeStartCommand(web *web_server.WebServer, manager *managers.FullManager)
 				}
 			}
 
+			// 定时任务只在后台的服务器进程中调度，避免命令行进程重复执行
+			// 服务进程启动时，上次退出前未完成的部署不会再继续，记为失败
+			if web_server.IsDaemonProcess() {
+				manager.CronManager.StartCronScheduler()
+				manager.DeployManager.RecoverInterruptedDeploys()
+			}
+
 			// 使用 RunUntilSignal 来保持服务器运行
 			if err := web.RunInBackground(); err != nil {
 				logger.Error(err)
@@ -111,7 +119,7 @@ func MakeStopCommand(web *web_server.WebServer) *cobra.Command {
 }
 
 // MakeRestartCommand 创建重启命令
-func MakeRestartCommand(web *web_server.WebServer) *cobra.Command {
+func MakeRestartCommand(web *web_server.WebServer, manager *managers.FullManager) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "restart",
 		Short: "重启服务器",
@@ -132,6 +140,11 @@ func MakeRestartCommand(web *web_server.WebServer) *cobra.Command {
 			// 等待一小段时间确保端口释放
 			time.Sleep(time.Second)
 
+			// 定时任务只在后台的服务器进程中调度
+			if web_server.IsDaemonProcess() {
+				manager.CronManager.StartCronScheduler()
+			}
+
 			// 重新启动
 			if err := web.RunInBackground(); err != nil {
 				logger.Error(err)
@@ -2,10 +2,8 @@ package commands
 
 import (
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"servon/core/managers"
+	"strconv"
 
 	"github.com/spf13/cobra"
 )
@@ -34,8 +32,9 @@ func GetServiceListCommand(m *managers.ServiceManager) *cobra.Command {
 		Use:   "list",
 		Short: "列出所有服务",
 		Run: func(cmd *cobra.Command, args []string) {
-			// 检查 supervisor 是否正在运行
-			if err := m.CheckSupervisorRunning(); err != nil {
+			// 检查后端是否可用
+			if err := m.CheckAvailable(); err != nil {
+				PrintErrorf("%v", err)
 				return
 			}
 
@@ -48,7 +47,6 @@ func GetServiceListCommand(m *managers.ServiceManager) *cobra.Command {
 
 			if output == "" {
 				PrintInfo("当前没有运行中的服务")
-				PrintCommandOutput("当前没有运行中的服务")
 				return
 			}
 
@@ -119,19 +117,28 @@ func GetServiceStatusCommand(m *managers.ServiceManager) *cobra.Command {
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			serviceName := args[0]
-			if err := m.CheckSupervisorInstalled(); err != nil {
-				return
-			}
-
-			command := exec.Command("supervisorctl", "status", serviceName)
-			output, err := command.CombinedOutput()
+			status, err := m.GetServiceStatus(serviceName)
 			if err != nil {
 				PrintErrorf("获取服务状态失败: %v", err)
 				return
 			}
 
-			PrintInfof("服务状态:")
-			fmt.Println(string(output))
+			info := map[string]string{
+				"Name":    status.Name,
+				"Status":  status.Status,
+				"Backend": m.BackendName(),
+			}
+			if status.PID > 0 {
+				info["PID"] = strconv.Itoa(status.PID)
+				info["Uptime"] = status.Uptime
+			}
+			if status.ExitStatus != nil {
+				info["Exit"] = strconv.Itoa(*status.ExitStatus)
+			}
+			if status.Description != "" {
+				info["Description"] = status.Description
+			}
+			PrintKeyValues(info)
 		},
 	})
 }
@@ -145,40 +152,17 @@ func GetServiceLogsCommand(m *managers.ServiceManager) *cobra.Command {
 		Run: func(cmd *cobra.Command, args []string) {
 			serviceName := args[0]
 
-			// 构建日志文件路径
-			stdoutLog := filepath.Join(m.RootFolder, "logs", serviceName+".out.log")
-			stderrLog := filepath.Join(m.RootFolder, "logs", serviceName+".err.log")
-
-			// 读取标准输出日志
-			PrintInfof("标准输出日志 (最后 %d 行):", tail)
-			if err := tailLog(stdoutLog, tail); err != nil {
-				PrintErrorf("读取标准输出日志失败: %v", err)
+			logs, err := m.GetServiceLogs(serviceName, tail, "")
+			if err != nil {
+				PrintErrorf("读取服务日志失败: %v", err)
+				return
 			}
 
-			// 读取错误日志
-			PrintInfof("\n错误日志 (最后 %d 行):", tail)
-			if err := tailLog(stderrLog, tail); err != nil {
-				PrintErrorf("读取错误日志失败: %v", err)
-			}
+			PrintInfof("服务日志 (最后 %d 行):", tail)
+			fmt.Print(logs)
 		},
 	})
 
 	cmd.Flags().IntVarP(&tail, "tail", "n", 100, "显示最后几行日志")
 	return cmd
 }
-
-func tailLog(logFile string, lines int) error {
-	if _, err := os.Stat(logFile); os.IsNotExist(err) {
-		PrintInfo("日志文件不存在")
-		return nil
-	}
-
-	command := exec.Command("tail", "-n", fmt.Sprintf("%d", lines), logFile)
-	output, err := command.CombinedOutput()
-	if err != nil {
-		return err
-	}
-
-	fmt.Println(string(output))
-	return nil
-}
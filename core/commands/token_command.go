@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"servon/core/managers"
+
+	"github.com/spf13/cobra"
+)
+
+// GetTokenCommand 返回 token 命令
+func GetTokenCommand(m *managers.TokenManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "token",
+		Short: "管理 Web API 的访问令牌",
+	})
+
+	cmd.AddCommand(newTokenCreateCmd(m))
+	cmd.AddCommand(newTokenListCmd(m))
+	cmd.AddCommand(newTokenRevokeCmd(m))
+
+	return cmd
+}
+
+// newTokenCreateCmd 返回 create 子命令
+func newTokenCreateCmd(m *managers.TokenManager) *cobra.Command {
+	cmd := NewCommand(CommandOptions{
+		Use:   "create",
+		Short: "创建访问令牌",
+		Run: func(cmd *cobra.Command, args []string) {
+			name, _ := cmd.Flags().GetString("name")
+			scopes, _ := cmd.Flags().GetStringSlice("scope")
+			expires, _ := cmd.Flags().GetString("expires")
+
+			ttl, err := managers.ParseTokenTTL(expires)
+			if err != nil {
+				PrintErrorf("%v", err)
+				return
+			}
+
+			plain, token, err := m.CreateToken(name, scopes, ttl)
+			if err != nil {
+				PrintErrorf("创建令牌失败: %v", err)
+				return
+			}
+
+			PrintSuccessf("令牌 %s 已创建，请立即保存，之后将无法再次查看:", token.Name)
+			fmt.Println()
+			fmt.Println("  " + plain)
+			fmt.Println()
+			PrintKeyValues(map[string]string{
+				"ID":      token.ID,
+				"Scopes":  strings.Join(token.Scopes, ","),
+				"Expires": formatTokenTime(token.ExpiresAt, "永不过期"),
+			})
+		},
+	})
+
+	cmd.Flags().StringP("name", "n", "default", "令牌名称")
+	cmd.Flags().StringSliceP("scope", "s", nil, fmt.Sprintf("令牌作用域，多个用逗号分隔，追加 :read 表示只读，* 表示全部 (%s)", strings.Join(managers.TokenScopes, ", ")))
+	cmd.Flags().StringP("expires", "e", "", "有效期，如 30d、12h，不指定则永不过期")
+	cmd.MarkFlagRequired("scope")
+
+	return cmd
+}
+
+// newTokenListCmd 返回 list 子命令
+func newTokenListCmd(m *managers.TokenManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:     "list",
+		Short:   "列出所有访问令牌",
+		Aliases: []string{"l", "ls"},
+		Run: func(cmd *cobra.Command, args []string) {
+			tokens, err := m.ListTokens()
+			if err != nil {
+				PrintErrorf("获取令牌列表失败: %v", err)
+				return
+			}
+
+			if len(tokens) == 0 {
+				PrintInfo("暂无访问令牌，使用 servon token create 创建")
+				return
+			}
+
+			fmt.Printf("%-10s %-16s %-30s %-20s %-20s\n", "ID", "NAME", "SCOPES", "EXPIRES", "LAST USED")
+			for _, token := range tokens {
+				expires := formatTokenTime(token.ExpiresAt, "永不过期")
+				if token.IsExpired() {
+					expires += " (已过期)"
+				}
+				fmt.Printf("%-10s %-16s %-30s %-20s %-20s\n",
+					token.ID,
+					token.Name,
+					strings.Join(token.Scopes, ","),
+					expires,
+					formatTokenTime(token.LastUsedAt, "从未使用"),
+				)
+			}
+		},
+	})
+}
+
+// newTokenRevokeCmd 返回 revoke 子命令
+func newTokenRevokeCmd(m *managers.TokenManager) *cobra.Command {
+	return NewCommand(CommandOptions{
+		Use:   "revoke [id]",
+		Short: "吊销访问令牌",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := m.RevokeToken(args[0]); err != nil {
+				PrintErrorf("吊销令牌失败: %v", err)
+				return
+			}
+
+			PrintSuccessf("令牌 %s 已吊销", args[0])
+		},
+	})
+}
+
+// formatTokenTime 格式化令牌相关的时间，未设置时返回 fallback
+func formatTokenTime(t *time.Time, fallback string) string {
+	if t == nil {
+		return fallback
+	}
+	return t.Format("2006-01-02 15:04")
+}
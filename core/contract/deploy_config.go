@@ -0,0 +1,111 @@
+package contract
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DeployConfig 仓库根目录下 servon.yaml 的内容，所有字段均为可选
+type DeployConfig struct {
+	Type        string            `yaml:"type" json:"type"`                 // 项目类型，覆盖自动检测的结果
+	Branch      string            `yaml:"branch" json:"branch"`             // 部署的分支
+	Install     string            `yaml:"install" json:"install"`           // 安装依赖的命令
+	Build       string            `yaml:"build" json:"build"`               // 构建命令
+	Start       string            `yaml:"start" json:"start"`               // 启动命令，在 current 目录下执行
+	Output      string            `yaml:"output" json:"output"`             // 静态站点的构建输出目录，相对于仓库根目录
+	Env         map[string]string `yaml:"env" json:"env"`                   // 运行时环境变量
+	Port        int               `yaml:"port" json:"port"`                 // 服务监听的端口
+	Domain      string            `yaml:"domain" json:"domain"`             // 绑定的域名
+	User        string            `yaml:"user" json:"user"`                 // 运行服务的系统用户，默认为项目专用用户，设为 root 时以 root 运行
+	Sandbox     bool              `yaml:"sandbox" json:"sandbox"`           // 使用 systemd 后端时为服务开启 ProtectSystem、PrivateTmp、NoNewPrivileges
+	HealthCheck HealthCheckConfig `yaml:"health_check" json:"health_check"` // 健康检查配置
+	Hooks       DeployHooks       `yaml:"hooks" json:"hooks"`               // 部署钩子
+	Laravel     LaravelConfig     `yaml:"laravel" json:"laravel"`           // Laravel 项目的部署选项
+	Docker      DockerConfig      `yaml:"docker" json:"docker"`             // Docker 项目的部署选项
+}
+
+// 健康检查的类型
+const (
+	HealthCheckHTTP    = "http"    // 请求 HTTP 路径并检查状态码
+	HealthCheckTCP     = "tcp"     // 检查端口能否建立 TCP 连接
+	HealthCheckCommand = "command" // 执行自定义命令，退出码为 0 视为健康
+)
+
+// HealthCheckConfig 部署后的健康检查配置，未配置时不做检查
+type HealthCheckConfig struct {
+	Type           string `yaml:"type" json:"type"`                       // 检查类型，为空时根据 path 和 command 推断
+	Path           string `yaml:"path" json:"path"`                       // HTTP 健康检查路径，如 /health
+	ExpectedStatus int    `yaml:"expected_status" json:"expected_status"` // HTTP 期望的状态码，默认 200
+	Port           int    `yaml:"port" json:"port"`                       // 检查的端口，默认使用服务端口
+	Command        string `yaml:"command" json:"command"`                 // 自定义检查命令，在 current 目录中执行
+	Retries        int    `yaml:"retries" json:"retries"`                 // 最多尝试的次数
+	Interval       string `yaml:"interval" json:"interval"`               // 两次尝试之间的间隔，如 3s
+	Timeout        string `yaml:"timeout" json:"timeout"`                 // 单次检查的超时时间，如 5s
+}
+
+// CheckType 返回实际使用的检查类型，未配置健康检查时返回空字符串
+func (h *HealthCheckConfig) CheckType() string {
+	switch {
+	case h.Type != "":
+		return h.Type
+	case h.Command != "":
+		return HealthCheckCommand
+	case h.Path != "":
+		return HealthCheckHTTP
+	case h.Port != 0:
+		return HealthCheckTCP
+	}
+	return ""
+}
+
+// DeployHooks 部署过程中执行的钩子命令，命令通过 sh -c 执行
+type DeployHooks struct {
+	PreDeploy  []string `yaml:"pre_deploy" json:"pre_deploy"`   // 调用部署器之前在工作目录中执行
+	PostDeploy []string `yaml:"post_deploy" json:"post_deploy"` // 部署成功后在 current 目录中执行
+}
+
+// LaravelConfig Laravel 项目的部署选项
+type LaravelConfig struct {
+	Migrate bool            `yaml:"migrate" json:"migrate"` // 切换版本前执行 php artisan migrate --force
+	Workers []LaravelWorker `yaml:"workers" json:"workers"` // 队列 worker，每个作为一个后台服务运行
+}
+
+// LaravelWorker 一个 php artisan queue:work 进程
+type LaravelWorker struct {
+	Name       string `yaml:"name" json:"name"`             // worker 名称，服务名为 <项目>-worker-<名称>
+	Connection string `yaml:"connection" json:"connection"` // 队列连接，为空时使用默认连接
+	Queue      string `yaml:"queue" json:"queue"`           // 监听的队列，多个用逗号分隔
+	Tries      int    `yaml:"tries" json:"tries"`           // 任务失败后的最大尝试次数，默认 3
+	Timeout    int    `yaml:"timeout" json:"timeout"`       // 单个任务的超时秒数，默认 60
+}
+
+// DockerConfig Docker 项目的部署选项
+type DockerConfig struct {
+	Dockerfile    string   `yaml:"dockerfile" json:"dockerfile"`         // Dockerfile 路径，相对于仓库根目录，默认 Dockerfile
+	ContainerPort int      `yaml:"container_port" json:"container_port"` // 容器内服务监听的端口，默认取 Dockerfile 中的 EXPOSE，映射到 port
+	Ports         []string `yaml:"ports" json:"ports"`                   // 额外的端口映射，如 9000:9000
+	Volumes       []string `yaml:"volumes" json:"volumes"`               // 挂载的卷，相对路径基于项目的 shared 目录，如 data:/app/data
+}
+
+// EnvList 将环境变量转换为 KEY=VALUE 形式的列表，按键排序
+func (c *DeployConfig) EnvList() []string {
+	keys := make([]string, 0, len(c.Env))
+	for key := range c.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", key, c.Env[key]))
+	}
+	return env
+}
+
+// PortOrDefault 返回配置的端口，未配置时返回 port
+func (c *DeployConfig) PortOrDefault(port int) int {
+	if c.Port > 0 {
+		return c.Port
+	}
+	return port
+}
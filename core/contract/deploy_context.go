@@ -0,0 +1,63 @@
+package contract
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"syscall"
+	"time"
+)
+
+// commandWaitDelay 部署取消后等待命令退出的最长时间
+const commandWaitDelay = 5 * time.Second
+
+// DeployContext 传递给部署器的部署上下文
+type DeployContext struct {
+	Context     context.Context // 部署被取消时结束，通过 RunCommand 执行的命令会随之终止
+	Output      io.Writer       // 部署输出，写入的内容会记录到部署日志
+	ID          string          // 部署ID，同时作为发布版本ID
+	Commit      string          // 部署的提交 SHA，无法读取时为空
+	ProjectName string          // 项目名称
+	WorkDir     string          // 代码所在的临时工作目录
+	TargetDir   string          // 项目的部署目录
+	Config      *DeployConfig   // 解析后的 servon.yaml，仓库中没有该文件时为空配置
+	Port        int             // 服务实际监听的端口，由部署器设置，用于健康检查
+}
+
+// Printf 向部署输出写入格式化内容
+func (c *DeployContext) Printf(format string, args ...interface{}) {
+	fmt.Fprintf(c.Output, format, args...)
+}
+
+// Println 向部署输出写入一行内容
+func (c *DeployContext) Println(args ...interface{}) {
+	fmt.Fprintln(c.Output, args...)
+}
+
+// RunCommand 在 dir 中执行命令，标准输出和标准错误都写入部署输出
+// 命令在独立的进程组中运行，部署被取消时整个进程组都会被终止
+func (c *DeployContext) RunCommand(dir, name string, args ...string) error {
+	cmd := exec.CommandContext(c.Context, name, args...)
+	cmd.Dir = dir
+	cmd.Stdout = c.Output
+	cmd.Stderr = c.Output
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = commandWaitDelay
+
+	if err := cmd.Run(); err != nil {
+		if c.Context.Err() != nil {
+			return c.Context.Err()
+		}
+		return err
+	}
+	return nil
+}
+
+// RunShell 在 dir 中通过 sh -c 执行命令
+func (c *DeployContext) RunShell(dir, command string) error {
+	return c.RunCommand(dir, "sh", "-c", command)
+}
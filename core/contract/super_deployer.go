@@ -3,8 +3,14 @@ package contract
 // SuperDeployer 定义了部署器的接口
 type SuperDeployer interface {
 	// Deploy 部署项目
-	Deploy(projectName string, workDir string, targetDir string) error
+	Deploy(ctx *DeployContext) error
 
 	// GetName 获取部署器名称
 	GetName() string
 }
+
+// RollbackHook 部署器可选实现的回滚钩子
+// 回滚切换版本并重启项目的后台服务后调用，用于重新加载部署器额外使用的进程，如 php-fpm
+type RollbackHook interface {
+	AfterRollback(ctx *DeployContext) error
+}
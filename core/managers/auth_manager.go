@@ -0,0 +1,292 @@
+package managers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// SessionCookieName 会话 Cookie 的名称
+const SessionCookieName = "servon_session"
+
+// SessionTTL 会话有效期
+const SessionTTL = 24 * time.Hour
+
+// AuthConfig 保存在磁盘上的管理员认证配置
+type AuthConfig struct {
+	Username      string `json:"username"`       // 管理员用户名
+	PasswordHash  string `json:"password_hash"`  // bcrypt 哈希后的密码
+	SessionSecret string `json:"session_secret"` // 会话签名密钥，修改密码时会重新生成
+	UpdatedAt     string `json:"updated_at"`     // 最后更新时间
+}
+
+// Session 表示一个已验证的会话
+type Session struct {
+	Username  string    `json:"username"`
+	ExpiresAt time.Time `json:"expires_at"`
+	nonce     string
+}
+
+// AuthManager 负责管理员账号和会话的管理
+// 凭据以 bcrypt 哈希形式存储在配置目录下，会话使用 HMAC-SHA256 签名的 Cookie
+type AuthManager struct {
+	configPath string
+	mu         sync.RWMutex
+	revoked    map[string]time.Time // 已注销会话的 nonce -> 过期时间
+}
+
+// NewAuthManager 创建认证管理器
+func NewAuthManager(configDir string) *AuthManager {
+	return &AuthManager{
+		configPath: filepath.Join(configDir, "auth.json"),
+		revoked:    make(map[string]time.Time),
+	}
+}
+
+// HasAdminAccount 判断是否已经设置管理员账号
+func (a *AuthManager) HasAdminAccount() bool {
+	config, err := a.loadAuthConfig()
+	return err == nil && config != nil && config.PasswordHash != ""
+}
+
+// GetAdminUsername 获取管理员用户名
+func (a *AuthManager) GetAdminUsername() string {
+	config, err := a.loadAuthConfig()
+	if err != nil || config == nil {
+		return ""
+	}
+	return config.Username
+}
+
+// SetAdminPassword 设置管理员账号和密码
+// 每次设置都会重新生成会话密钥，使所有已签发的会话失效
+func (a *AuthManager) SetAdminPassword(username string, password string) error {
+	username = strings.TrimSpace(username)
+	if username == "" {
+		return fmt.Errorf("用户名不能为空")
+	}
+	if len(password) < 8 {
+		return fmt.Errorf("密码长度不能少于 8 位")
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return fmt.Errorf("生成密码哈希失败: %v", err)
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return fmt.Errorf("生成会话密钥失败: %v", err)
+	}
+
+	return a.saveAuthConfig(&AuthConfig{
+		Username:      username,
+		PasswordHash:  string(hash),
+		SessionSecret: secret,
+	})
+}
+
+// VerifyAdminPassword 校验管理员用户名和密码
+func (a *AuthManager) VerifyAdminPassword(username string, password string) bool {
+	config, err := a.loadAuthConfig()
+	if err != nil || config == nil || config.PasswordHash == "" {
+		return false
+	}
+
+	// 无论用户名是否匹配都执行一次 bcrypt 比较，避免通过耗时差异猜测用户名
+	passwordErr := bcrypt.CompareHashAndPassword([]byte(config.PasswordHash), []byte(password))
+	usernameOK := subtle.ConstantTimeCompare([]byte(config.Username), []byte(username)) == 1
+
+	return usernameOK && passwordErr == nil
+}
+
+// CreateSession 为指定用户签发会话令牌
+func (a *AuthManager) CreateSession(username string) (string, *Session, error) {
+	config, err := a.loadAuthConfig()
+	if err != nil {
+		return "", nil, err
+	}
+	if config == nil || config.SessionSecret == "" {
+		return "", nil, fmt.Errorf("尚未设置管理员账号")
+	}
+
+	nonce, err := randomHex(16)
+	if err != nil {
+		return "", nil, fmt.Errorf("生成会话标识失败: %v", err)
+	}
+
+	session := &Session{
+		Username:  username,
+		ExpiresAt: time.Now().Add(SessionTTL),
+		nonce:     nonce,
+	}
+
+	payload := strings.Join([]string{
+		session.Username,
+		strconv.FormatInt(session.ExpiresAt.Unix(), 10),
+		session.nonce,
+	}, "|")
+	encoded := base64.RawURLEncoding.EncodeToString([]byte(payload))
+	signature := signSession(config.SessionSecret, encoded)
+
+	return encoded + "." + signature, session, nil
+}
+
+// ValidateSession 校验会话令牌，返回会话信息
+func (a *AuthManager) ValidateSession(token string) (*Session, error) {
+	config, err := a.loadAuthConfig()
+	if err != nil {
+		return nil, err
+	}
+	if config == nil || config.SessionSecret == "" {
+		return nil, fmt.Errorf("尚未设置管理员账号")
+	}
+
+	session, err := parseSession(config.SessionSecret, token)
+	if err != nil {
+		return nil, err
+	}
+
+	if session.Username != config.Username {
+		return nil, fmt.Errorf("会话用户不存在")
+	}
+
+	a.mu.RLock()
+	_, revoked := a.revoked[session.nonce]
+	a.mu.RUnlock()
+	if revoked {
+		return nil, fmt.Errorf("会话已注销")
+	}
+
+	return session, nil
+}
+
+// RevokeSession 注销会话令牌
+func (a *AuthManager) RevokeSession(token string) {
+	config, err := a.loadAuthConfig()
+	if err != nil || config == nil {
+		return
+	}
+
+	session, err := parseSession(config.SessionSecret, token)
+	if err != nil {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// 顺便清理已经过期的注销记录
+	now := time.Now()
+	for nonce, expiresAt := range a.revoked {
+		if now.After(expiresAt) {
+			delete(a.revoked, nonce)
+		}
+	}
+	a.revoked[session.nonce] = session.ExpiresAt
+}
+
+// parseSession 解析并校验会话令牌的签名和有效期
+func parseSession(secret string, token string) (*Session, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("无效的会话格式")
+	}
+
+	expected := signSession(secret, parts[0])
+	if !hmac.Equal([]byte(expected), []byte(parts[1])) {
+		return nil, fmt.Errorf("会话签名无效")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("无效的会话内容")
+	}
+
+	fields := strings.Split(string(payload), "|")
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("无效的会话内容")
+	}
+
+	expiresAt, err := strconv.ParseInt(fields[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("无效的会话有效期")
+	}
+
+	session := &Session{
+		Username:  fields[0],
+		ExpiresAt: time.Unix(expiresAt, 0),
+		nonce:     fields[2],
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, fmt.Errorf("会话已过期")
+	}
+
+	return session, nil
+}
+
+// signSession 使用会话密钥对内容进行签名
+func signSession(secret string, content string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(content))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// randomHex 生成指定字节数的随机十六进制字符串
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// loadAuthConfig 从磁盘读取认证配置，文件不存在时返回 nil
+func (a *AuthManager) loadAuthConfig() (*AuthConfig, error) {
+	data, err := os.ReadFile(a.configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("读取认证配置失败: %v", err)
+	}
+
+	var config AuthConfig
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("解析认证配置失败: %v", err)
+	}
+
+	return &config, nil
+}
+
+// saveAuthConfig 将认证配置写入磁盘
+func (a *AuthManager) saveAuthConfig(config *AuthConfig) error {
+	if err := os.MkdirAll(filepath.Dir(a.configPath), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	config.UpdatedAt = time.Now().Format(time.RFC3339)
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化认证配置失败: %v", err)
+	}
+
+	if err := os.WriteFile(a.configPath, data, 0600); err != nil {
+		return fmt.Errorf("写入认证配置失败: %v", err)
+	}
+
+	return nil
+}
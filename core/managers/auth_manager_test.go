@@ -0,0 +1,96 @@
+package managers
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// newTestAuthManager 创建已设置管理员账号的认证管理器
+func newTestAuthManager(t *testing.T) *AuthManager {
+	auth := NewAuthManager(t.TempDir())
+	if err := auth.SetAdminPassword("admin", "password123"); err != nil {
+		t.Fatal(err)
+	}
+	return auth
+}
+
+// TestVerifyAdminPassword 测试管理员密码校验
+func TestVerifyAdminPassword(t *testing.T) {
+	auth := newTestAuthManager(t)
+
+	if !auth.VerifyAdminPassword("admin", "password123") {
+		t.Error("Expected correct credentials to be accepted")
+	}
+	if auth.VerifyAdminPassword("admin", "wrong-password") {
+		t.Error("Expected wrong password to be rejected")
+	}
+	if auth.VerifyAdminPassword("root", "password123") {
+		t.Error("Expected wrong username to be rejected")
+	}
+
+	config, err := auth.loadAuthConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(config.PasswordHash, "password123") {
+		t.Error("Expected password to be stored as bcrypt hash")
+	}
+
+	if err := auth.SetAdminPassword("admin", "short"); err == nil {
+		t.Error("Expected short password to be rejected")
+	}
+}
+
+// TestValidateSession 测试会话的签名、过期和注销
+func TestValidateSession(t *testing.T) {
+	auth := newTestAuthManager(t)
+
+	token, _, err := auth.CreateSession("admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.ValidateSession(token); err != nil {
+		t.Fatalf("Expected valid session, got %v", err)
+	}
+
+	config, _ := auth.loadAuthConfig()
+	encoded, signature, _ := strings.Cut(token, ".")
+
+	// 修改内容后签名不再匹配
+	payload, _ := base64.RawURLEncoding.DecodeString(encoded)
+	tampered := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(payload), "admin", "admim", 1)))
+	if _, err := auth.ValidateSession(tampered + "." + signature); err == nil {
+		t.Error("Expected tampered session to be rejected")
+	}
+
+	// 签名正确但已过期
+	expired := base64.RawURLEncoding.EncodeToString([]byte("admin|1|nonce"))
+	if _, err := auth.ValidateSession(expired + "." + signSession(config.SessionSecret, expired)); err == nil {
+		t.Error("Expected expired session to be rejected")
+	}
+
+	for _, invalid := range []string{"", "no-dot", encoded + ".", "." + signature} {
+		if _, err := auth.ValidateSession(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+
+	// 注销后不能再使用，其他会话不受影响
+	other, _, _ := auth.CreateSession("admin")
+	auth.RevokeSession(token)
+	if _, err := auth.ValidateSession(token); err == nil {
+		t.Error("Expected revoked session to be rejected")
+	}
+	if _, err := auth.ValidateSession(other); err != nil {
+		t.Errorf("Expected other session to stay valid, got %v", err)
+	}
+
+	// 修改密码会重新生成密钥，已签发的会话全部失效
+	if err := auth.SetAdminPassword("admin", "password456"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := auth.ValidateSession(other); err == nil {
+		t.Error("Expected session to be invalid after password change")
+	}
+}
@@ -1,6 +1,7 @@
 package managers
 
 import (
+	"path/filepath"
 	"servon/components/cron_util"
 )
 
@@ -11,14 +12,23 @@ type CronManager struct {
 }
 
 func newCronManager() *CronManager {
+	storePath := filepath.Join(DefaultDataManager.GetConfigRootFolder(), "cron_tasks.json")
+	taskManager, err := cron_util.NewCronTaskManager(storePath)
+	if err != nil {
+		PrintErrorf("加载定时任务失败: %v", err)
+	}
 	return &CronManager{
-		taskManager: cron_util.NewCronTaskManager(),
+		taskManager: taskManager,
 	}
 }
 
-// GetCronTasks 获取所有定时任务
+// StartCronScheduler 开始按定时表达式执行任务，只应在服务器进程中调用
+func (p *CronManager) StartCronScheduler() {
+	p.taskManager.Start()
+}
+
+// GetCronTasks 获取所有定时任务，不输出日志，以免混入 servon cron export 的输出
 func (p *CronManager) GetCronTasks() ([]*cron_util.CronTask, error) {
-	PrintInfo("获取所有定时任务...")
 	return p.taskManager.GetTasks(), nil
 }
 
@@ -45,3 +55,14 @@ func (p *CronManager) ToggleCronTask(id int) (*cron_util.CronTask, error) {
 	PrintInfo("启用/禁用定时任务...")
 	return p.taskManager.ToggleTask(id)
 }
+
+// RunCronTask 立即执行定时任务
+func (p *CronManager) RunCronTask(id int) (*cron_util.CronRun, error) {
+	PrintInfo("立即执行定时任务...")
+	return p.taskManager.RunTask(id)
+}
+
+// GetCronTaskRuns 获取定时任务的执行记录
+func (p *CronManager) GetCronTaskRuns(id int) ([]cron_util.CronRun, error) {
+	return p.taskManager.GetRuns(id)
+}
@@ -0,0 +1,250 @@
+package managers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"servon/core/contract"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DeployConfigFiles 仓库根目录下部署配置文件的候选名称
+var DeployConfigFiles = []string{"servon.yaml", "servon.yml"}
+
+var (
+	branchPattern = regexp.MustCompile(`^[A-Za-z0-9._/-]+$`)
+	envKeyPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	domainPattern = regexp.MustCompile(`^(\*\.)?[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+	// worker 的名称、队列连接和队列会出现在服务名和命令行参数中
+	workerNamePattern  = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	workerQueuePattern = regexp.MustCompile(`^[A-Za-z0-9_:.-]+(,[A-Za-z0-9_:.-]+)*$`)
+	// docker run 的端口映射和卷，如 127.0.0.1:9000:9000/udp、data:/app/data:ro
+	dockerPortPattern   = regexp.MustCompile(`^([0-9.]+:)?[0-9]+:[0-9]+(/(tcp|udp))?$`)
+	dockerVolumePattern = regexp.MustCompile(`^[^:,]+:/[^:,]*(:(ro|rw))?$`)
+)
+
+// DeployConfigErrors servon.yaml 的校验错误，包含所有不合法的字段
+type DeployConfigErrors []string
+
+func (e DeployConfigErrors) Error() string {
+	return "servon.yaml 校验失败: " + strings.Join(e, "; ")
+}
+
+// LoadDeployConfig 读取并解析工作目录中的 servon.yaml
+// 文件不存在时返回空配置，未知字段视为错误
+func LoadDeployConfig(workDir string) (*contract.DeployConfig, string, error) {
+	config := &contract.DeployConfig{}
+
+	for _, name := range DeployConfigFiles {
+		path := filepath.Join(workDir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, "", fmt.Errorf("读取 %s 失败: %v", name, err)
+		}
+
+		config, err := ParseDeployConfig(data)
+		if err != nil {
+			return nil, "", err
+		}
+		return config, name, nil
+	}
+
+	return config, "", nil
+}
+
+// ParseDeployConfig 解析 servon.yaml 的内容，未知字段视为错误
+func ParseDeployConfig(data []byte) (*contract.DeployConfig, error) {
+	config := &contract.DeployConfig{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return nil, DeployConfigErrors{err.Error()}
+	}
+	return config, nil
+}
+
+// ValidateDeployConfig 校验部署配置，deployers 为已注册部署器的名称
+func ValidateDeployConfig(config *contract.DeployConfig, deployers []string) error {
+	var errs DeployConfigErrors
+
+	if config.Type != "" {
+		known := false
+		for _, name := range deployers {
+			if name == config.Type {
+				known = true
+				break
+			}
+		}
+		if !known {
+			errs = append(errs, fmt.Sprintf("type: 不支持的项目类型 %q，可用的类型: %s", config.Type, strings.Join(deployers, ", ")))
+		}
+	}
+
+	if config.Branch != "" {
+		if !branchPattern.MatchString(config.Branch) || strings.HasPrefix(config.Branch, "-") || strings.Contains(config.Branch, "..") {
+			errs = append(errs, fmt.Sprintf("branch: 无效的分支名 %q", config.Branch))
+		}
+	}
+
+	if config.Output != "" && !isRelativeInside(config.Output) {
+		errs = append(errs, fmt.Sprintf("output: 输出目录必须位于仓库内: %q", config.Output))
+	}
+
+	if config.Port < 0 || config.Port > 65535 {
+		errs = append(errs, fmt.Sprintf("port: 端口 %d 超出范围 1-65535", config.Port))
+	}
+
+	for key := range config.Env {
+		if !envKeyPattern.MatchString(key) {
+			errs = append(errs, fmt.Sprintf("env: 无效的环境变量名 %q", key))
+		}
+	}
+
+	if config.Domain != "" && !domainPattern.MatchString(config.Domain) {
+		errs = append(errs, fmt.Sprintf("domain: 无效的域名 %q", config.Domain))
+	}
+
+	if config.User != "" && !userPattern.MatchString(config.User) {
+		errs = append(errs, fmt.Sprintf("user: 无效的用户名 %q", config.User))
+	}
+
+	errs = append(errs, validateHealthCheck(&config.HealthCheck)...)
+
+	for i, hook := range config.Hooks.PreDeploy {
+		if strings.TrimSpace(hook) == "" {
+			errs = append(errs, fmt.Sprintf("hooks.pre_deploy[%d]: 命令不能为空", i))
+		}
+	}
+	for i, hook := range config.Hooks.PostDeploy {
+		if strings.TrimSpace(hook) == "" {
+			errs = append(errs, fmt.Sprintf("hooks.post_deploy[%d]: 命令不能为空", i))
+		}
+	}
+
+	errs = append(errs, validateLaravel(&config.Laravel)...)
+	errs = append(errs, validateDocker(&config.Docker)...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// validateLaravel 校验 Laravel 队列 worker 配置，worker 名称会作为服务名的一部分
+func validateLaravel(laravel *contract.LaravelConfig) []string {
+	var errs []string
+
+	names := make(map[string]bool)
+	for i, worker := range laravel.Workers {
+		switch {
+		case !workerNamePattern.MatchString(worker.Name):
+			errs = append(errs, fmt.Sprintf("laravel.workers[%d].name: 无效的名称 %q，只能包含字母、数字、- 和 _", i, worker.Name))
+		case names[worker.Name]:
+			errs = append(errs, fmt.Sprintf("laravel.workers[%d].name: 名称 %q 重复", i, worker.Name))
+		}
+		names[worker.Name] = true
+
+		if worker.Connection != "" && !workerNamePattern.MatchString(worker.Connection) {
+			errs = append(errs, fmt.Sprintf("laravel.workers[%d].connection: 无效的队列连接 %q", i, worker.Connection))
+		}
+		if worker.Queue != "" && !workerQueuePattern.MatchString(worker.Queue) {
+			errs = append(errs, fmt.Sprintf("laravel.workers[%d].queue: 无效的队列 %q", i, worker.Queue))
+		}
+		if worker.Tries < 0 {
+			errs = append(errs, fmt.Sprintf("laravel.workers[%d].tries: 不能小于 0", i))
+		}
+		if worker.Timeout < 0 {
+			errs = append(errs, fmt.Sprintf("laravel.workers[%d].timeout: 不能小于 0", i))
+		}
+	}
+
+	return errs
+}
+
+// validateDocker 校验 Docker 部署选项，路径不能指向仓库或 shared 目录以外
+func validateDocker(docker *contract.DockerConfig) []string {
+	var errs []string
+
+	if docker.Dockerfile != "" && !isRelativeInside(docker.Dockerfile) {
+		errs = append(errs, fmt.Sprintf("docker.dockerfile: Dockerfile 必须位于仓库内: %q", docker.Dockerfile))
+	}
+	if docker.ContainerPort < 0 || docker.ContainerPort > 65535 {
+		errs = append(errs, fmt.Sprintf("docker.container_port: 端口 %d 超出范围 1-65535", docker.ContainerPort))
+	}
+	for i, port := range docker.Ports {
+		if !dockerPortPattern.MatchString(port) {
+			errs = append(errs, fmt.Sprintf("docker.ports[%d]: 无效的端口映射 %q，格式为 主机端口:容器端口", i, port))
+		}
+	}
+	for i, volume := range docker.Volumes {
+		host, _, _ := strings.Cut(volume, ":")
+		if !dockerVolumePattern.MatchString(volume) || (!filepath.IsAbs(host) && !isRelativeInside(host)) {
+			errs = append(errs, fmt.Sprintf("docker.volumes[%d]: 无效的卷 %q，格式为 主机路径:容器路径", i, volume))
+		}
+	}
+
+	return errs
+}
+
+// isRelativeInside 判断路径是否为不超出当前目录的相对路径
+func isRelativeInside(path string) bool {
+	return !filepath.IsAbs(path) && !strings.HasPrefix(filepath.Clean(path), "..")
+}
+
+// validateHealthCheck 校验健康检查配置
+func validateHealthCheck(hc *contract.HealthCheckConfig) []string {
+	var errs []string
+
+	switch hc.CheckType() {
+	case "", contract.HealthCheckHTTP, contract.HealthCheckTCP:
+	case contract.HealthCheckCommand:
+		if strings.TrimSpace(hc.Command) == "" {
+			errs = append(errs, "health_check.command: command 类型的健康检查需要配置命令")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("health_check.type: 不支持的类型 %q，可用的类型: http, tcp, command", hc.Type))
+	}
+
+	if hc.Path != "" && !strings.HasPrefix(hc.Path, "/") {
+		errs = append(errs, fmt.Sprintf("health_check.path: 路径必须以 / 开头: %q", hc.Path))
+	}
+	if hc.ExpectedStatus != 0 && (hc.ExpectedStatus < 100 || hc.ExpectedStatus > 599) {
+		errs = append(errs, fmt.Sprintf("health_check.expected_status: 无效的状态码 %d", hc.ExpectedStatus))
+	}
+	if hc.Port < 0 || hc.Port > 65535 {
+		errs = append(errs, fmt.Sprintf("health_check.port: 端口 %d 超出范围 1-65535", hc.Port))
+	}
+	if hc.Retries < 0 {
+		errs = append(errs, fmt.Sprintf("health_check.retries: 重试次数不能为负数: %d", hc.Retries))
+	}
+	if _, err := parseHealthCheckDuration(hc.Interval, DefaultHealthCheckInterval); err != nil {
+		errs = append(errs, fmt.Sprintf("health_check.interval: 无效的时间 %q: %v", hc.Interval, err))
+	}
+	if _, err := parseHealthCheckDuration(hc.Timeout, DefaultHealthCheckTimeout); err != nil {
+		errs = append(errs, fmt.Sprintf("health_check.timeout: 无效的时间 %q: %v", hc.Timeout, err))
+	}
+
+	return errs
+}
+
+// runHooks 在指定目录依次执行钩子命令，任一命令失败即停止
+func (m *DeployManager) runHooks(dctx *contract.DeployContext, stage, dir string, hooks []string) error {
+	for _, hook := range hooks {
+		dctx.Printf("执行 %s 钩子: %s\n", stage, hook)
+		if err := dctx.RunShell(dir, hook); err != nil {
+			return fmt.Errorf("%s 钩子执行失败 (%s): %v", stage, hook, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package managers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"servon/core/contract"
+)
+
+// 健康检查的默认参数
+const (
+	DefaultHealthCheckRetries  = 10
+	DefaultHealthCheckInterval = 3 * time.Second
+	DefaultHealthCheckTimeout  = 5 * time.Second
+	DefaultHealthCheckStatus   = http.StatusOK
+)
+
+// healthCheckBodyLimit 记录 HTTP 响应内容的最大长度
+const healthCheckBodyLimit = 512
+
+// ErrDeployRolledBack 部署后健康检查失败，项目已回滚到上一个版本
+var ErrDeployRolledBack = errors.New("健康检查失败，已回滚")
+
+// parseHealthCheckDuration 解析健康检查的时间配置，为空时返回默认值
+func parseHealthCheckDuration(value string, fallback time.Duration) (time.Duration, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("必须大于 0")
+	}
+	return d, nil
+}
+
+// runHealthCheck 按配置重复探测服务，直到成功或用完重试次数
+// 返回最后一次探测的输出，失败时作为部署记录的一部分保存
+func (m *DeployManager) runHealthCheck(dctx *contract.DeployContext) (string, error) {
+	hc := dctx.Config.HealthCheck
+
+	retries := hc.Retries
+	if retries <= 0 {
+		retries = DefaultHealthCheckRetries
+	}
+	interval, _ := parseHealthCheckDuration(hc.Interval, DefaultHealthCheckInterval)
+	timeout, _ := parseHealthCheckDuration(hc.Timeout, DefaultHealthCheckTimeout)
+
+	port := hc.Port
+	if port == 0 {
+		port = dctx.Config.PortOrDefault(dctx.Port)
+	}
+
+	var output string
+	var err error
+	for i := 1; i <= retries; i++ {
+		ctx, cancel := context.WithTimeout(dctx.Context, timeout)
+		switch hc.CheckType() {
+		case contract.HealthCheckHTTP:
+			output, err = probeHTTP(ctx, port, hc.Path, hc.ExpectedStatus)
+		case contract.HealthCheckTCP:
+			output, err = probeTCP(ctx, port)
+		case contract.HealthCheckCommand:
+			output, err = probeCommand(ctx, dctx, hc.Command)
+		default:
+			err = fmt.Errorf("不支持的健康检查类型: %s", hc.CheckType())
+		}
+		cancel()
+
+		if err == nil {
+			dctx.Printf("健康检查通过 (%d/%d): %s\n", i, retries, output)
+			return output, nil
+		}
+		if ctxErr := dctx.Context.Err(); ctxErr != nil {
+			return output, ctxErr
+		}
+
+		dctx.Printf("健康检查失败 (%d/%d): %v\n", i, retries, err)
+		if i == retries {
+			break
+		}
+
+		select {
+		case <-dctx.Context.Done():
+			return output, dctx.Context.Err()
+		case <-time.After(interval):
+		}
+	}
+
+	return output, err
+}
+
+// probeHTTP 请求本机服务的健康检查路径，状态码与期望值一致视为健康
+func probeHTTP(ctx context.Context, port int, path string, expected int) (string, error) {
+	if port == 0 {
+		return "", fmt.Errorf("无法确定健康检查的端口")
+	}
+	if expected == 0 {
+		expected = DefaultHealthCheckStatus
+	}
+	if path == "" {
+		path = "/"
+	}
+
+	url := "http://" + net.JoinHostPort("127.0.0.1", strconv.Itoa(port)) + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Sprintf("GET %s: %v", url, err), err
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, healthCheckBodyLimit))
+	output := fmt.Sprintf("GET %s -> %d\n%s", url, resp.StatusCode, body)
+	if resp.StatusCode != expected {
+		return output, fmt.Errorf("GET %s 返回状态码 %d，期望 %d", url, resp.StatusCode, expected)
+	}
+	return output, nil
+}
+
+// probeTCP 检查本机端口能否建立 TCP 连接
+func probeTCP(ctx context.Context, port int) (string, error) {
+	if port == 0 {
+		return "", fmt.Errorf("无法确定健康检查的端口")
+	}
+
+	addr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Sprintf("tcp %s: %v", addr, err), err
+	}
+	conn.Close()
+	return fmt.Sprintf("tcp %s 连接成功", addr), nil
+}
+
+// probeCommand 在 current 目录中执行检查命令，退出码为 0 视为健康
+func probeCommand(ctx context.Context, dctx *contract.DeployContext, command string) (string, error) {
+	var buf bytes.Buffer
+	probe := *dctx
+	probe.Context = ctx
+	probe.Output = &buf
+
+	err := probe.RunShell(currentLink(dctx.TargetDir), command)
+	if err == nil {
+		return buf.String(), nil
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return buf.String(), fmt.Errorf("检查命令超时: %s", command)
+	}
+	return buf.String(), fmt.Errorf("检查命令执行失败: %v", err)
+}
+
+// rollbackFailedDeploy 健康检查失败后将 current 切回部署前的版本，与手动回滚一样重启项目的所有后台服务
+// 部署前没有可用版本时无法回滚，部署记为失败
+func (m *DeployManager) rollbackFailedDeploy(dctx *contract.DeployContext, previous string, checkErr error) error {
+	if previous == "" || previous == dctx.ID {
+		dctx.Println("健康检查失败，没有可回滚的版本")
+		return fmt.Errorf("健康检查失败，没有可回滚的版本: %v", checkErr)
+	}
+
+	dctx.Printf("健康检查失败，回滚到版本 %s\n", previous)
+	if err := m.switchRelease(dctx, previous); err != nil {
+		dctx.Printf("回滚失败: %v\n", err)
+		return fmt.Errorf("健康检查失败，回滚到版本 %s 失败: %v", previous, err)
+	}
+
+	return fmt.Errorf("%w到版本 %s: %v", ErrDeployRolledBack, previous, checkErr)
+}
@@ -0,0 +1,168 @@
+package managers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"servon/core/models"
+)
+
+// ErrDeployNotFound 部署记录不存在
+var ErrDeployNotFound = errors.New("部署记录不存在")
+
+// deployIDPattern 部署ID的格式，用于防止通过ID拼接出任意路径
+var deployIDPattern = regexp.MustCompile(`^[0-9]{14}-[0-9a-f]{4}$`)
+
+// newDeployID 生成部署ID，格式为 时间-随机数，按字典序即按时间排序
+func newDeployID() string {
+	suffix, err := randomHex(2)
+	if err != nil {
+		suffix = "0000"
+	}
+	return time.Now().Format("20060102150405") + "-" + suffix
+}
+
+// deployHistoryDir 部署记录的存储目录
+func (m *DeployManager) deployHistoryDir() string {
+	return filepath.Join(m.logsDir, "deploy")
+}
+
+// deployRecordPath 部署记录文件路径
+func (m *DeployManager) deployRecordPath(id string) string {
+	return filepath.Join(m.deployHistoryDir(), id+".json")
+}
+
+// deployOutputPath 部署输出日志文件路径
+func (m *DeployManager) deployOutputPath(id string) string {
+	return filepath.Join(m.deployHistoryDir(), id+".log")
+}
+
+// saveDeployLog 将部署记录写入磁盘
+func (m *DeployManager) saveDeployLog(record *models.DeployLog) error {
+	if err := os.MkdirAll(m.deployHistoryDir(), 0755); err != nil {
+		return fmt.Errorf("创建部署记录目录失败: %v", err)
+	}
+
+	saved := *record
+	saved.Content = ""
+	data, err := json.MarshalIndent(&saved, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化部署记录失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免并发读取到写了一半的记录
+	tmpPath := m.deployRecordPath(record.ID) + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("写入部署记录失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, m.deployRecordPath(record.ID)); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入部署记录失败: %v", err)
+	}
+	return nil
+}
+
+// loadDeployLog 从磁盘读取部署记录，不包含输出内容
+func (m *DeployManager) loadDeployLog(id string) (*models.DeployLog, error) {
+	data, err := os.ReadFile(m.deployRecordPath(id))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s", ErrDeployNotFound, id)
+		}
+		return nil, fmt.Errorf("读取部署记录失败: %v", err)
+	}
+
+	var record models.DeployLog
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, fmt.Errorf("解析部署记录失败: %v", err)
+	}
+	return &record, nil
+}
+
+// GetDeployLog 获取单条部署记录，包含完整的部署输出
+func (m *DeployManager) GetDeployLog(id string) (*models.DeployLog, error) {
+	if !deployIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("无效的部署ID: %s", id)
+	}
+
+	record, err := m.loadDeployLog(id)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := os.ReadFile(m.deployOutputPath(id))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("读取部署输出失败: %v", err)
+	}
+	record.Content = string(output)
+
+	return record, nil
+}
+
+// GetDeployRecord 获取单条部署记录，不包含部署输出
+func (m *DeployManager) GetDeployRecord(id string) (*models.DeployLog, error) {
+	if !deployIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("%w: 无效的部署ID %s", ErrDeployNotFound, id)
+	}
+	return m.loadDeployLog(id)
+}
+
+// ListDeployLogs 获取部署历史，按开始时间倒序排列
+// project 为空时返回所有项目的记录，limit 小于等于 0 时不限制数量
+func (m *DeployManager) ListDeployLogs(project string, limit int) ([]*models.DeployLog, error) {
+	entries, err := os.ReadDir(m.deployHistoryDir())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*models.DeployLog{}, nil
+		}
+		return nil, fmt.Errorf("读取部署记录目录失败: %v", err)
+	}
+
+	ids := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".json")
+		if filepath.Ext(entry.Name()) == ".json" && deployIDPattern.MatchString(id) {
+			ids = append(ids, id)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.StringSlice(ids)))
+
+	records := make([]*models.DeployLog, 0)
+	for _, id := range ids {
+		record, err := m.loadDeployLog(id)
+		if err != nil {
+			continue
+		}
+		if project != "" && record.Project != project {
+			continue
+		}
+
+		records = append(records, record)
+		if limit > 0 && len(records) >= limit {
+			break
+		}
+	}
+
+	return records, nil
+}
+
+// syncWriter 串行化写入，部署器和命令输出可能在不同的 goroutine 中写入同一个部署日志
+type syncWriter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func (s *syncWriter) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.w.Write(p)
+}
@@ -0,0 +1,158 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"servon/core/models"
+)
+
+// lockPollInterval 等待其他进程释放文件锁时重试的间隔
+const lockPollInterval = 500 * time.Millisecond
+
+// projectLockFile 项目目录中的部署锁，服务进程和命令行进程的部署、回滚通过它互斥
+const projectLockFile = ".deploy.lock"
+
+// fileLock 基于 flock 的文件锁，进程退出（包括被 kill -9）时由内核自动释放
+type fileLock struct {
+	file *os.File
+}
+
+// lockFile 获取文件锁，锁被其他进程持有时每隔 lockPollInterval 重试，直到获得锁或 ctx 结束
+func lockFile(ctx context.Context, path string) (*fileLock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("打开锁文件失败: %v", err)
+	}
+
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return &fileLock{file: file}, nil
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			file.Close()
+			return nil, fmt.Errorf("获取锁 %s 失败: %v", path, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			file.Close()
+			return nil, ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+// Unlock 释放文件锁
+func (l *fileLock) Unlock() {
+	syscall.Flock(int(l.file.Fd()), syscall.LOCK_UN)
+	l.file.Close()
+}
+
+// isFileLocked 判断文件锁是否被某个进程持有，文件不存在时视为未持有
+func isFileLocked(path string) bool {
+	file, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return false
+	}
+	defer file.Close()
+
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_SH|syscall.LOCK_NB); err != nil {
+		return errors.Is(err, syscall.EWOULDBLOCK)
+	}
+	syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	return false
+}
+
+// lockProject 获取项目的部署锁，保证同一项目的部署和回滚不会同时进行，包括不同进程之间
+// 先获取进程内的锁，再获取项目目录中的文件锁，返回释放两者的函数
+func (m *DeployManager) lockProject(ctx context.Context, projectName string) (func(), error) {
+	mu := m.queue.projectLock(projectName)
+	mu.Lock()
+
+	targetDir := filepath.Join(m.projectsDir, projectName)
+	if err := os.MkdirAll(targetDir, 0755); err != nil {
+		mu.Unlock()
+		return nil, fmt.Errorf("创建项目目录失败: %v", err)
+	}
+
+	lock, err := lockFile(ctx, filepath.Join(targetDir, projectLockFile))
+	if err != nil {
+		mu.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		lock.Unlock()
+		mu.Unlock()
+	}, nil
+}
+
+// deployOwnerPath 部署的归属锁文件，执行部署的进程从入队到结束一直持有该锁
+func (m *DeployManager) deployOwnerPath(id string) string {
+	return filepath.Join(m.deployHistoryDir(), id+".lock")
+}
+
+// deployHasOwner 判断排队中或进行中的部署是否仍有进程负责执行
+func (m *DeployManager) deployHasOwner(id string) bool {
+	return isFileLocked(m.deployOwnerPath(id))
+}
+
+// finishOrphanedDeploy 执行部署的进程已退出（崩溃、重启或被 kill -9）时，将停留在排队中或进行中的部署记为 status
+// 部署仍有进程负责或已经结束时不做修改，返回最新的部署记录以及是否做了修改
+func (m *DeployManager) finishOrphanedDeploy(id, status, message string) (*models.DeployLog, bool, error) {
+	if m.deployHasOwner(id) {
+		record, err := m.loadDeployLog(id)
+		return record, false, err
+	}
+
+	// 检查归属后重新读取，避免使用进程退出前读取的旧状态
+	record, err := m.loadDeployLog(id)
+	if err != nil || isDeployFinished(record) {
+		return record, false, err
+	}
+
+	record.Status = status
+	record.Message = message
+	record.FinishedAt = time.Now()
+	if err := m.saveDeployLog(record); err != nil {
+		return record, false, err
+	}
+	os.Remove(m.deployOwnerPath(id))
+	os.Remove(m.deployCancelPath(id))
+	return record, true, nil
+}
+
+// RecoverInterruptedDeploys 将执行进程已退出的排队中或进行中的部署记为失败，返回处理的部署数量
+// 服务进程启动时调用，否则这些部署会一直停留在排队中或进行中，跟踪输出时永远不会结束
+func (m *DeployManager) RecoverInterruptedDeploys() int {
+	records, err := m.ListDeployLogs("", 0)
+	if err != nil {
+		fmt.Printf("读取部署记录失败: %v\n", err)
+		return 0
+	}
+
+	recovered := 0
+	for _, record := range records {
+		if isDeployFinished(record) {
+			continue
+		}
+
+		_, changed, err := m.finishOrphanedDeploy(record.ID, models.DeployStatusFailed, ErrDeployInterrupted.Error())
+		if err != nil {
+			fmt.Printf("更新部署记录 %s 失败: %v\n", record.ID, err)
+			continue
+		}
+		if changed {
+			fmt.Printf("部署 %s 的执行进程已退出，记为失败\n", record.ID)
+			recovered++
+		}
+	}
+	return recovered
+}
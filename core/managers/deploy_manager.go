@@ -1,6 +1,8 @@
 package managers
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,8 +12,10 @@ import (
 	"servon/components/events"
 	"servon/components/git"
 	"servon/components/github"
+	"servon/components/user"
 	"servon/components/utils"
 	"servon/core/contract"
+	"servon/core/models"
 
 	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
 )
@@ -30,23 +34,34 @@ type DeployManager struct {
 	fileUtil    *utils.FileUtil
 	stringUtil  *utils.StringUtil
 	github      *github.GitHubIntegration
+	services    *ServiceManager
+	users       *user.UserManager
 	logsDir     string
 	tempDir     string
 	projectsDir string
+	configDir   string
 	deployers   []contract.SuperDeployer
+	queue       *deployQueue
+	// runner 执行一次部署，默认为 runDeploy，测试时替换以避免拉取代码
+	runner func(ctx context.Context, record *models.DeployLog, req DeployRequest) error
 }
 
-func NewDeployManager(eventBus events.IEventBus, github *github.GitHubIntegration, logsDir string, tempDir string, projectsDir string) (*DeployManager, error) {
+func NewDeployManager(eventBus events.IEventBus, github *github.GitHubIntegration, logsDir string, tempDir string, projectsDir string, configDir string) (*DeployManager, error) {
 	dm := &DeployManager{
 		eventBus:    eventBus,
 		gitUtil:     git.NewGitUtil(),
 		fileUtil:    utils.DefaultFileUtil,
 		github:      github,
+		services:    DefaultServiceManager,
+		users:       user.NewUserManager(),
 		logsDir:     logsDir,
 		tempDir:     tempDir,
 		projectsDir: projectsDir,
+		configDir:   configDir,
 		deployers:   []contract.SuperDeployer{},
+		queue:       newDeployQueue(),
 	}
+	dm.runner = dm.runDeploy
 
 	// 订阅Git Push事件
 	eventBus.Subscribe(events.GitPush, dm.handleGitPushEvent)
@@ -54,6 +69,18 @@ func NewDeployManager(eventBus events.IEventBus, github *github.GitHubIntegratio
 	return dm, nil
 }
 
+// ErrDeploySkipped 推送的分支不是项目的部署分支，部署被跳过
+var ErrDeploySkipped = errors.New("推送的分支不是部署分支，跳过部署")
+
+// DeployRequest 描述一次部署请求
+type DeployRequest struct {
+	Repo          string // 仓库地址，支持完整URL和 owner/repo 格式
+	Branch        string // 部署的分支，为空时使用 servon.yaml 中的分支或仓库默认分支
+	DefaultBranch string // 仓库的默认分支，由 webhook 提供
+	Trigger       string // 触发来源，见 models.DeployTrigger*
+	TriggeredBy   string // 触发者
+}
+
 // handleGitPushEvent 处理Git Push事件
 func (m *DeployManager) handleGitPushEvent(event events.Event) {
 	deployData, ok := event.Data.(map[string]interface{})
@@ -68,8 +95,27 @@ func (m *DeployManager) handleGitPushEvent(event events.Event) {
 		return
 	}
 
-	// 执行部署操作
-	if err := m.DeployProject(repo); err != nil {
+	branch, _ := deployData["branch"].(string)
+	defaultBranch, _ := deployData["default_branch"].(string)
+	pusher, _ := deployData["pusher"].(string)
+
+	// 执行部署操作，是否为部署分支在读取 servon.yaml 后判断
+	record, err := m.DeployProject(DeployRequest{
+		Repo:          repo,
+		Branch:        branch,
+		DefaultBranch: defaultBranch,
+		Trigger:       models.DeployTriggerWebhook,
+		TriggeredBy:   pusher,
+	})
+	if errors.Is(err, ErrDeploySkipped) {
+		fmt.Printf("仓库 %s 推送到分支 %s，非部署分支，跳过部署\n", repo, branch)
+		return
+	}
+	if errors.Is(err, ErrDeployCancelled) || errors.Is(err, ErrDeploySuperseded) {
+		fmt.Printf("仓库 %s 的部署 %s 未执行完成: %v\n", repo, record.ID, err)
+		return
+	}
+	if err != nil {
 		fmt.Printf("错误: 仓库 %s 部署失败: %v\n", repo, err)
 
 		// 发布部署失败事件
@@ -77,6 +123,7 @@ func (m *DeployManager) handleGitPushEvent(event events.Event) {
 			Type: events.DeployFailed,
 			Data: map[string]interface{}{
 				"repository": repo,
+				"deploy_id":  record.ID,
 				"error":      err.Error(),
 			},
 		})
@@ -89,167 +136,381 @@ func (m *DeployManager) handleGitPushEvent(event events.Event) {
 		Type: events.DeployComplete,
 		Data: map[string]interface{}{
 			"repository": repo,
+			"deploy_id":  record.ID,
 			"status":     "success",
 		},
 	})
 }
 
-// DeployProject 执行实际的部署操作
-func (m *DeployManager) DeployProject(repoURL string) error {
-	// 生成唯一的部署ID，根据当前日期和时间
-	deployID := time.Now().Format("20060102150405")
-
-	// 获取项目名称
-	projectName := m.stringUtil.GetProjectNameFromString(repoURL)
-
-	// 部署的目标目录
-	targetDir := filepath.Join(m.projectsDir, projectName)
+// runDeploy 执行实际的部署操作，ctx 取消时中止部署
+// 输出只写入部署日志文件，通过 FollowDeployOutput 实时读取
+func (m *DeployManager) runDeploy(ctx context.Context, record *models.DeployLog, req DeployRequest) error {
+	logFile, err := os.OpenFile(m.deployOutputPath(record.ID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("创建部署日志失败: %v", err)
+	}
+	defer logFile.Close()
+
+	projectName := record.Project
+
+	dctx := &contract.DeployContext{
+		Context:     ctx,
+		Output:      &syncWriter{w: logFile},
+		ID:          record.ID,
+		ProjectName: projectName,
+		// 部署的目标目录
+		TargetDir: filepath.Join(m.projectsDir, projectName),
+		// 临时工作目录，目录名即部署ID，部署器据此创建发布版本
+		WorkDir: filepath.Join(m.tempDir, "deploy", projectName, record.ID),
+	}
+	workDir, targetDir := dctx.WorkDir, dctx.TargetDir
 
-	// 创建临时工作目录
-	workDir := filepath.Join(m.tempDir, "deploy", fmt.Sprintf("%s_%s", projectName, deployID))
-	fmt.Printf("创建临时工作目录: %s\n", workDir)
+	dctx.Printf("部署ID: %s\n", record.ID)
+	dctx.Printf("创建临时工作目录: %s\n", workDir)
 
 	if err := os.MkdirAll(workDir, 0755); err != nil {
-		fmt.Printf("创建工作目录失败: %v\n", err)
+		dctx.Printf("创建工作目录失败: %v\n", err)
 		return fmt.Errorf("创建工作目录失败: %v", err)
 	}
 	defer func() {
-		fmt.Printf("清理临时工作目录: %s\n", workDir)
+		dctx.Printf("清理临时工作目录: %s\n", workDir)
 		os.RemoveAll(workDir)
 	}()
 
-	// 拉取代码
-	fmt.Printf("开始从仓库拉取代码: %s\n", repoURL)
-	if err := m.gitClone(repoURL, workDir); err != nil {
-		fmt.Printf("拉取代码失败: %v\n", err)
-		return fmt.Errorf("拉取代码失败: %v", err)
+	// 拉取代码并读取 servon.yaml，必要时切换到配置中的分支
+	config, err := m.checkoutProject(dctx, record, req)
+	if err != nil {
+		return err
 	}
+	dctx.Config = config
 
-	// 检测项目类型
-	projectType := utils.DefaultProjectUtil.DetectProjectType(workDir)
-	fmt.Printf("检测到项目类型: %s\n", projectType)
+	// 检测项目类型，servon.yaml 中的 type 优先
+	projectType := config.Type
+	if projectType == "" {
+		projectType = utils.DefaultProjectUtil.DetectProjectType(workDir)
+	}
+	dctx.Printf("检测到项目类型: %s\n", projectType)
 
 	if projectType == "unknown" {
-		fmt.Printf("未检测到项目类型，部署失败\n")
+		dctx.Printf("未检测到项目类型，部署失败\n")
 		return fmt.Errorf("未检测到项目类型，部署失败")
 	}
 
 	// 根据项目类型选择合适的部署器
 	deployer := m.getDeployer(projectType)
 	if deployer == nil {
-		fmt.Printf("未找到合适的部署器\n")
+		dctx.Printf("未找到合适的部署器\n")
 		return fmt.Errorf("未找到合适的部署器")
 	}
 
-	fmt.Printf("使用部署器: %s\n", deployer.GetName())
+	record.Deployer = deployer.GetName()
+	m.saveDeployLog(record)
+	dctx.Printf("使用部署器: %s\n", deployer.GetName())
+
+	if err := m.runHooks(dctx, "pre_deploy", workDir, config.Hooks.PreDeploy); err != nil {
+		dctx.Println(err)
+		return err
+	}
+
+	// 记录部署前的版本，健康检查失败时回滚
+	previous := m.GetCurrentRelease(projectName)
 
 	// 执行部署
-	if err := deployer.Deploy(projectName, workDir, targetDir); err != nil {
-		fmt.Printf("部署失败: %v\n", err)
+	if err := deployer.Deploy(dctx); err != nil {
+		dctx.Printf("部署失败: %v\n", err)
 		return fmt.Errorf("部署失败: %v", err)
 	}
 
-	fmt.Println("部署成功")
+	if config.HealthCheck.CheckType() != "" {
+		dctx.Printf("开始健康检查: %s\n", config.HealthCheck.CheckType())
+		output, err := m.runHealthCheck(dctx)
+		if err != nil {
+			if dctx.Context.Err() != nil {
+				return err
+			}
+			record.HealthCheck = output
+			return m.rollbackFailedDeploy(dctx, previous, err)
+		}
+	}
+
+	if err := m.runHooks(dctx, "post_deploy", currentLink(targetDir), config.Hooks.PostDeploy); err != nil {
+		dctx.Println(err)
+		return err
+	}
+
+	dctx.Println("部署成功")
+
+	removed, err := m.PruneReleases(projectName)
+	if err != nil {
+		dctx.Printf("清理旧版本失败: %v\n", err)
+	}
+	for _, release := range removed {
+		dctx.Printf("清理旧版本: %s\n", release.Path)
+	}
 
 	return nil
 }
 
+// checkoutProject 拉取代码并读取校验 servon.yaml，返回解析后的配置
+// 未指定分支时先拉取仓库默认分支，如果配置中的分支不同则重新拉取配置的分支；
+// webhook 触发时先确定部署分支，只有推送到部署分支才拉取代码，否则返回 ErrDeploySkipped
+func (m *DeployManager) checkoutProject(dctx *contract.DeployContext, record *models.DeployLog, req DeployRequest) (*contract.DeployConfig, error) {
+	if req.Trigger == models.DeployTriggerWebhook {
+		return m.checkoutPushedBranch(dctx, record, req)
+	}
+
+	workDir := dctx.WorkDir
+	if err := m.cloneInto(dctx, record); err != nil {
+		return nil, err
+	}
+
+	config, err := m.loadDeployConfig(dctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deployBranch := config.Branch
+	if deployBranch == "" || deployBranch == record.Branch {
+		return config, nil
+	}
+
+	// 手动指定的分支优先于配置文件
+	if req.Branch != "" {
+		dctx.Printf("使用指定的分支 %s 部署，忽略 servon.yaml 中的分支 %s\n", req.Branch, deployBranch)
+		return config, nil
+	}
+
+	dctx.Printf("servon.yaml 指定部署分支 %s，重新拉取代码\n", deployBranch)
+	if err := os.RemoveAll(workDir); err != nil {
+		return nil, fmt.Errorf("清理工作目录失败: %v", err)
+	}
+
+	record.Branch = deployBranch
+	if err := m.cloneInto(dctx, record); err != nil {
+		return nil, err
+	}
+
+	return m.loadDeployConfig(dctx)
+}
+
+// checkoutPushedBranch 处理 webhook 推送：推送的分支是部署分支时才拉取代码
+// 部署分支只以默认分支中的 servon.yaml 为准，推送的分支无法通过修改自己的 servon.yaml 使自己被部署
+func (m *DeployManager) checkoutPushedBranch(dctx *contract.DeployContext, record *models.DeployLog, req DeployRequest) (*contract.DeployConfig, error) {
+	deployBranch, err := m.resolveDeployBranch(dctx, record.Repo, req.DefaultBranch)
+	if err != nil {
+		dctx.Printf("确定部署分支失败: %v\n", err)
+		return nil, fmt.Errorf("确定部署分支失败: %v", err)
+	}
+	if deployBranch != record.Branch {
+		dctx.Printf("推送的分支 %s 不是部署分支 %s\n", record.Branch, deployBranch)
+		return nil, ErrDeploySkipped
+	}
+
+	if err := m.cloneInto(dctx, record); err != nil {
+		return nil, err
+	}
+
+	config, err := m.loadDeployConfig(dctx)
+	if err != nil {
+		return nil, err
+	}
+	if config.Branch != "" && config.Branch != deployBranch {
+		dctx.Printf("忽略分支 %s 的 servon.yaml 中的 branch: %s，部署分支以默认分支中的配置为准\n", record.Branch, config.Branch)
+	}
+	return config, nil
+}
+
+// resolveDeployBranch 读取默认分支中 servon.yaml 的 branch 确定部署分支，未配置时部署默认分支
+// 只浅拉取默认分支的最新提交，推送到其他分支时不需要克隆仓库；defaultBranch 为空时使用远程仓库的默认分支
+func (m *DeployManager) resolveDeployBranch(dctx *contract.DeployContext, repo, defaultBranch string) (string, error) {
+	repo = normalizeRepoURL(repo)
+	auth, err := m.getGitHubAuth(dctx, repo)
+	if err != nil {
+		return "", fmt.Errorf("获取GitHub认证信息失败: %v", err)
+	}
+
+	file, err := m.gitUtil.ReadRemoteFile(dctx.Context, repo, defaultBranch, auth, DeployConfigFiles...)
+	if err != nil {
+		return "", err
+	}
+	if file.Name == "" {
+		dctx.Printf("默认分支 %s 中没有 servon.yaml，部署分支为 %s\n", file.Branch, file.Branch)
+		return file.Branch, nil
+	}
+
+	config, err := ParseDeployConfig(file.Content)
+	if err == nil {
+		err = ValidateDeployConfig(config, m.deployerNames())
+	}
+	if err != nil {
+		return "", fmt.Errorf("默认分支 %s 中的 %s 无效: %v", file.Branch, file.Name, err)
+	}
+
+	if config.Branch == "" {
+		dctx.Printf("部署分支为默认分支 %s\n", file.Branch)
+		return file.Branch, nil
+	}
+	dctx.Printf("默认分支 %s 中的 %s 指定部署分支 %s\n", file.Branch, file.Name, config.Branch)
+	return config.Branch, nil
+}
+
+// cloneInto 拉取 record 中指定分支的代码，并回填实际的分支和提交
+func (m *DeployManager) cloneInto(dctx *contract.DeployContext, record *models.DeployLog) error {
+	workDir := dctx.WorkDir
+	branchName := record.Branch
+	if branchName == "" {
+		branchName = "默认分支"
+	}
+
+	dctx.Printf("开始从仓库拉取代码: %s (分支: %s)\n", record.Repo, branchName)
+	if err := m.gitClone(dctx, record.Repo, record.Branch); err != nil {
+		dctx.Printf("拉取代码失败: %v\n", err)
+		return fmt.Errorf("拉取代码失败: %v", err)
+	}
+
+	if record.Branch == "" {
+		if branch, err := m.gitUtil.GetCurrentBranch(workDir); err == nil {
+			record.Branch = branch
+		}
+	}
+
+	if commit, err := m.gitUtil.GetCommitInfo(workDir); err == nil {
+		record.Commit = commit.Hash.String()
+		dctx.Commit = record.Commit
+		dctx.Printf("部署提交: %s\n", record.Commit)
+	}
+
+	m.saveDeployLog(record)
+	return nil
+}
+
+// loadDeployConfig 读取并校验工作目录中的 servon.yaml
+func (m *DeployManager) loadDeployConfig(dctx *contract.DeployContext) (*contract.DeployConfig, error) {
+	config, file, err := LoadDeployConfig(dctx.WorkDir)
+	if err == nil {
+		err = ValidateDeployConfig(config, m.deployerNames())
+	}
+	if err != nil {
+		dctx.Println(err)
+		return nil, err
+	}
+
+	if file != "" {
+		dctx.Printf("已读取部署配置: %s\n", file)
+	}
+	return config, nil
+}
+
 // gitClone 从仓库拉取代码（带重试机制）
-func (m *DeployManager) gitClone(repo, workDir string) error {
+func (m *DeployManager) gitClone(dctx *contract.DeployContext, repo, branch string) error {
+	workDir := dctx.WorkDir
 	const maxRetries = 3
 	var lastErr error
 
 	// 规范化仓库地址
 	originalRepo := repo
-	if !strings.HasPrefix(repo, "https://") && !strings.HasPrefix(repo, "git@") {
-		repo = "https://github.com/" + repo
-	}
-	fmt.Printf("规范化仓库地址: %s -> %s\n", originalRepo, repo)
+	repo = normalizeRepoURL(repo)
+	dctx.Printf("规范化仓库地址: %s -> %s\n", originalRepo, repo)
 
 	// 检查工作目录
 	if _, err := os.Stat(workDir); os.IsNotExist(err) {
-		fmt.Printf("工作目录不存在，创建: %s\n", workDir)
+		dctx.Printf("工作目录不存在，创建: %s\n", workDir)
 		if err := os.MkdirAll(workDir, 0755); err != nil {
-			fmt.Printf("创建工作目录失败: %v\n", err)
+			dctx.Printf("创建工作目录失败: %v\n", err)
 			return fmt.Errorf("创建工作目录失败: %v", err)
 		}
 	}
 
 	for i := 0; i < maxRetries; i++ {
+		if err := dctx.Context.Err(); err != nil {
+			return err
+		}
 		if i > 0 {
-			fmt.Printf("第 %d 次重试克隆仓库...\n", i+1)
-			time.Sleep(time.Second * time.Duration(i+1))
+			dctx.Printf("第 %d 次重试克隆仓库...\n", i+1)
+			select {
+			case <-dctx.Context.Done():
+				return dctx.Context.Err()
+			case <-time.After(time.Second * time.Duration(i+1)):
+			}
 		}
 
-		fmt.Println("开始获取 GitHub 认证信息...")
-		auth, err := m.getGitHubAuth(repo)
+		dctx.Println("开始获取 GitHub 认证信息...")
+		auth, err := m.getGitHubAuth(dctx, repo)
 		if err != nil {
 			lastErr = fmt.Errorf("获取GitHub认证信息失败: %v", err)
-			fmt.Printf("认证失败详情: %v\n", lastErr)
+			dctx.Printf("认证失败详情: %v\n", lastErr)
 			continue
 		}
 
 		if auth == nil {
-			fmt.Println("获取到的认证信息为空，将尝试无认证克隆")
+			dctx.Println("获取到的认证信息为空，将尝试无认证克隆")
 		} else {
-			fmt.Printf("成功获取认证信息 - 用户名: %s, Token长度: %d\n",
+			dctx.Printf("成功获取认证信息 - 用户名: %s, Token长度: %d\n",
 				auth.Username, len(auth.Password))
 		}
 
-		fmt.Printf("开始克隆仓库 %s 到 %s\n", repo, workDir)
-		err = m.gitUtil.CloneRepo(repo, "main", workDir, auth)
+		dctx.Printf("开始克隆仓库 %s 到 %s\n", repo, workDir)
+		err = m.gitUtil.CloneRepoContext(dctx.Context, repo, branch, workDir, auth)
 		if err == nil {
-			fmt.Printf("仓库克隆成功: %s\n", repo)
+			dctx.Printf("仓库克隆成功: %s\n", repo)
 			// 验证克隆结果
 			if files, err := os.ReadDir(workDir); err == nil {
-				fmt.Printf("克隆目录内容: %d 个文件/目录\n", len(files))
+				dctx.Printf("克隆目录内容: %d 个文件/目录\n", len(files))
 			}
 			return nil
 		}
 
 		lastErr = err
-		fmt.Printf("克隆失败 (尝试 %d/%d): %v\n", i+1, maxRetries, err)
+		dctx.Printf("克隆失败 (尝试 %d/%d): %v\n", i+1, maxRetries, err)
 	}
 
-	fmt.Printf("克隆仓库失败（已重试%d次）- 最后错误: %v\n", maxRetries, lastErr)
+	dctx.Printf("克隆仓库失败（已重试%d次）- 最后错误: %v\n", maxRetries, lastErr)
 	return fmt.Errorf("克隆仓库失败（已重试%d次）- 最后错误: %v", maxRetries, lastErr)
 }
 
+// normalizeRepoURL 将 owner/repo 格式的仓库补全为 GitHub 地址
+func normalizeRepoURL(repo string) string {
+	if !strings.HasPrefix(repo, "https://") && !strings.HasPrefix(repo, "git@") {
+		return "https://github.com/" + repo
+	}
+	return repo
+}
+
 // getGitHubAuth 获取GitHub认证信息
-func (m *DeployManager) getGitHubAuth(repo string) (*githttp.BasicAuth, error) {
+func (m *DeployManager) getGitHubAuth(dctx *contract.DeployContext, repo string) (*githttp.BasicAuth, error) {
 	if m.github == nil {
-		fmt.Println("GitHub集成未初始化")
+		dctx.Println("GitHub集成未初始化")
 		return nil, fmt.Errorf("GitHub集成未初始化")
 	}
 
-	fmt.Printf("准备获取仓库认证令牌: %s\n", repo)
+	dctx.Printf("准备获取仓库认证令牌: %s\n", repo)
 
 	// 检查仓库格式
 	repoName := repo
 	if strings.HasPrefix(repo, "https://github.com/") {
 		repoName = strings.TrimPrefix(repo, "https://github.com/")
 	}
-	fmt.Printf("处理后的仓库名称: %s\n", repoName)
+	dctx.Printf("处理后的仓库名称: %s\n", repoName)
 
 	// 验证仓库名称格式
 	parts := strings.Split(repoName, "/")
 	if len(parts) != 2 {
-		fmt.Printf("无效的仓库名称格式: %s，应为 'owner/repo' 格式\n", repoName)
+		dctx.Printf("无效的仓库名称格式: %s，应为 'owner/repo' 格式\n", repoName)
 		return nil, fmt.Errorf("无效的仓库名称格式: %s，应为 'owner/repo' 格式", repoName)
 	}
-	fmt.Printf("仓库所有者: %s, 仓库名称: %s\n", parts[0], parts[1])
+	dctx.Printf("仓库所有者: %s, 仓库名称: %s\n", parts[0], parts[1])
 
 	token, err := m.github.GetInstallationToken(repoName)
 	if err != nil {
-		fmt.Printf("获取安装令牌失败: %v\n", err)
+		dctx.Printf("获取安装令牌失败: %v\n", err)
 		return nil, fmt.Errorf("获取安装令牌失败: %v", err)
 	}
 
 	if token == "" {
-		fmt.Println("获取到的token为空")
+		dctx.Println("获取到的token为空")
 		return nil, fmt.Errorf("获取到的token为空")
 	}
-	fmt.Printf("成功获取安装令牌 (长度: %d)\n", len(token))
+	dctx.Printf("成功获取安装令牌 (长度: %d)\n", len(token))
 
 	auth := &githttp.BasicAuth{
 		Username: "x-access-token",
@@ -258,13 +519,13 @@ func (m *DeployManager) getGitHubAuth(repo string) (*githttp.BasicAuth, error) {
 
 	// 验证认证信息完整性
 	if auth.Username == "" || auth.Password == "" {
-		fmt.Printf("认证信息不完整: username=%v, token_length=%d\n",
+		dctx.Printf("认证信息不完整: username=%v, token_length=%d\n",
 			auth.Username != "", len(auth.Password))
 		return nil, fmt.Errorf("认证信息不完整: username=%v, token_length=%d",
 			auth.Username != "", len(auth.Password))
 	}
 
-	fmt.Println("认证信息构建成功")
+	dctx.Println("认证信息构建成功")
 	return auth, nil
 }
 
@@ -296,6 +557,15 @@ func (m *DeployManager) ClearDeployers() {
 	m.deployers = make([]contract.SuperDeployer, 0)
 }
 
+// deployerNames 已注册部署器的名称
+func (m *DeployManager) deployerNames() []string {
+	names := make([]string, 0, len(m.deployers))
+	for _, deployer := range m.deployers {
+		names = append(names, deployer.GetName())
+	}
+	return names
+}
+
 // getDeployer 根据项目类型选择合适的部署器
 func (m *DeployManager) getDeployer(projectType string) contract.SuperDeployer {
 	for _, deployer := range m.deployers {
@@ -0,0 +1,410 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"servon/core/models"
+)
+
+// DefaultMaxConcurrentDeploys 默认同时进行的部署数量
+const DefaultMaxConcurrentDeploys = 2
+
+// cancelPollInterval 检查其他进程发出的取消请求的间隔
+const cancelPollInterval = time.Second
+
+var (
+	// ErrDeployCancelled 部署被手动取消
+	ErrDeployCancelled = errors.New("部署已取消")
+	// ErrDeploySuperseded 排队中的部署被同一项目更新的推送取代
+	ErrDeploySuperseded = errors.New("部署已被新的推送取代")
+	// ErrDeployInterrupted 执行部署的进程在部署结束前退出
+	ErrDeployInterrupted = errors.New("执行部署的进程已退出，部署被中断")
+)
+
+// DeployQueueState 部署队列的当前状态
+type DeployQueueState struct {
+	MaxConcurrent int                 `json:"max_concurrent"` // 同时进行的部署数量上限
+	Running       []*models.DeployLog `json:"running"`        // 正在进行的部署
+	Queued        []*models.DeployLog `json:"queued"`         // 排队中的部署，按项目和入队顺序排列
+}
+
+// deployJob 队列中的一次部署
+type deployJob struct {
+	record *models.DeployLog
+	req    DeployRequest
+	ctx    context.Context
+	cancel context.CancelFunc
+	owner  *fileLock // 部署的归属锁，其他进程据此判断部署是否仍在执行
+	done   chan struct{}
+	err    error
+}
+
+// deployQueue 部署队列
+// 每个项目同一时间只有一个 worker 按顺序执行部署，不同项目之间并行，
+// 并行的部署总数受 DeploySettings.MaxConcurrent 限制
+type deployQueue struct {
+	mu      sync.Mutex
+	cond    *sync.Cond
+	pending map[string][]*deployJob // 项目 -> 等待执行的部署
+	running map[string]*deployJob   // 项目 -> 正在执行的部署
+	workers map[string]bool         // 已启动 worker 的项目
+	locks   map[string]*sync.Mutex  // 项目 -> 部署和回滚共用的锁
+	active  int                     // 正在执行的部署数量
+}
+
+func newDeployQueue() *deployQueue {
+	q := &deployQueue{
+		pending: make(map[string][]*deployJob),
+		running: make(map[string]*deployJob),
+		workers: make(map[string]bool),
+		locks:   make(map[string]*sync.Mutex),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// projectLock 获取项目在当前进程中的锁，与项目目录中的文件锁一起由 lockProject 使用
+func (q *deployQueue) projectLock(projectName string) *sync.Mutex {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	lock, ok := q.locks[projectName]
+	if !ok {
+		lock = &sync.Mutex{}
+		q.locks[projectName] = lock
+	}
+	return lock
+}
+
+// EnqueueDeploy 将部署请求加入队列并立即返回排队中的部署记录
+// 同一项目还有排队中的 webhook 部署时，新的推送会取代它
+func (m *DeployManager) EnqueueDeploy(req DeployRequest) (*models.DeployLog, error) {
+	job, err := m.enqueue(req)
+	if err != nil {
+		return nil, err
+	}
+	return m.loadDeployLog(job.record.ID)
+}
+
+// DeployProject 将部署请求加入队列并等待部署结束
+// 返回的部署记录在部署失败时同样有效
+func (m *DeployManager) DeployProject(req DeployRequest) (*models.DeployLog, error) {
+	job, err := m.enqueue(req)
+	if err != nil {
+		return &models.DeployLog{}, err
+	}
+
+	<-job.done
+	return job.record, job.err
+}
+
+// enqueue 创建部署记录并加入项目的队列，必要时启动该项目的 worker
+func (m *DeployManager) enqueue(req DeployRequest) (*deployJob, error) {
+	now := time.Now()
+	record := &models.DeployLog{
+		ID:          newDeployID(),
+		QueuedAt:    now,
+		Timestamp:   now,
+		Project:     m.stringUtil.GetProjectNameFromString(req.Repo),
+		Repo:        req.Repo,
+		Branch:      req.Branch,
+		Action:      models.DeployActionDeploy,
+		Trigger:     req.Trigger,
+		TriggeredBy: req.TriggeredBy,
+		Status:      models.DeployStatusQueued,
+	}
+	if err := checkProjectName(record.Project); err != nil {
+		return nil, err
+	}
+
+	// 先持有归属锁再保存记录，其他进程看到排队中的记录时总能判断它是否仍有进程负责
+	if err := os.MkdirAll(m.deployHistoryDir(), 0755); err != nil {
+		return nil, fmt.Errorf("创建部署记录目录失败: %v", err)
+	}
+	owner, err := lockFile(context.Background(), m.deployOwnerPath(record.ID))
+	if err != nil {
+		return nil, err
+	}
+	if err := m.saveDeployLog(record); err != nil {
+		owner.Unlock()
+		os.Remove(m.deployOwnerPath(record.ID))
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &deployJob{
+		record: record,
+		req:    req,
+		ctx:    ctx,
+		cancel: cancel,
+		owner:  owner,
+		done:   make(chan struct{}),
+	}
+
+	q := m.queue
+	q.mu.Lock()
+	jobs := q.pending[record.Project]
+	if n := len(jobs); n > 0 && jobs[n-1].req.Trigger == models.DeployTriggerWebhook && req.Trigger == models.DeployTriggerWebhook {
+		superseded := jobs[n-1]
+		jobs = jobs[:n-1]
+		m.finishJob(superseded, fmt.Errorf("%w: %s", ErrDeploySuperseded, record.ID))
+	}
+	q.pending[record.Project] = append(jobs, job)
+	if !q.workers[record.Project] {
+		q.workers[record.Project] = true
+		go m.projectWorker(record.Project)
+	}
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	go m.watchCancelRequest(job)
+
+	fmt.Printf("部署 %s 已加入队列（项目: %s）\n", record.ID, record.Project)
+	return job, nil
+}
+
+// projectWorker 按顺序执行项目队列中的部署，队列为空时退出
+func (m *DeployManager) projectWorker(projectName string) {
+	q := m.queue
+
+	for {
+		q.mu.Lock()
+		if len(q.pending[projectName]) == 0 {
+			delete(q.pending, projectName)
+			delete(q.workers, projectName)
+			q.mu.Unlock()
+			return
+		}
+
+		// 等待全局的并发名额，等待期间队首的部署可能被取消或取代
+		job := q.pending[projectName][0]
+		for q.active >= m.maxConcurrentDeploys() && m.isQueueHead(projectName, job) {
+			q.cond.Wait()
+		}
+		if !m.isQueueHead(projectName, job) {
+			q.mu.Unlock()
+			continue
+		}
+
+		q.pending[projectName] = q.pending[projectName][1:]
+		q.running[projectName] = job
+		q.active++
+		q.mu.Unlock()
+
+		m.runJob(job)
+
+		q.mu.Lock()
+		delete(q.running, projectName)
+		q.active--
+		q.cond.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// isQueueHead 判断 job 是否仍在项目队列的最前面，调用方需持有队列锁
+func (m *DeployManager) isQueueHead(projectName string, job *deployJob) bool {
+	jobs := m.queue.pending[projectName]
+	return len(jobs) > 0 && jobs[0] == job
+}
+
+// maxConcurrentDeploys 当前允许同时进行的部署数量
+func (m *DeployManager) maxConcurrentDeploys() int {
+	if n := m.GetDeploySettings().MaxConcurrent; n > 0 {
+		return n
+	}
+	return DefaultMaxConcurrentDeploys
+}
+
+// runJob 执行一次部署，执行期间持有项目锁，项目正在其他进程中部署时等待其结束
+func (m *DeployManager) runJob(job *deployJob) {
+	unlock, err := m.lockProject(job.ctx, job.record.Project)
+	if err != nil {
+		if job.ctx.Err() != nil {
+			err = ErrDeployCancelled
+		}
+		m.finishJob(job, err)
+		return
+	}
+	defer unlock()
+
+	if job.ctx.Err() != nil {
+		m.finishJob(job, ErrDeployCancelled)
+		return
+	}
+
+	job.record.Status = models.DeployStatusRunning
+	job.record.Timestamp = time.Now()
+	if err := m.saveDeployLog(job.record); err != nil {
+		m.finishJob(job, err)
+		return
+	}
+
+	m.finishJob(job, m.runner(job.ctx, job.record, job.req))
+}
+
+// finishJob 根据部署结果更新部署记录并通知等待者
+func (m *DeployManager) finishJob(job *deployJob, deployErr error) {
+	record := job.record
+
+	// 取消后部署器返回的错误多为进程被终止，统一记为取消
+	if deployErr != nil && !errors.Is(deployErr, ErrDeploySuperseded) && job.ctx.Err() != nil {
+		deployErr = ErrDeployCancelled
+	}
+	job.cancel()
+
+	record.FinishedAt = time.Now()
+	switch {
+	case deployErr == nil:
+		record.Status = models.DeployStatusSuccess
+		record.Message = "部署成功"
+	case errors.Is(deployErr, ErrDeploySkipped):
+		record.Status = models.DeployStatusSkipped
+		record.Message = deployErr.Error()
+	case errors.Is(deployErr, ErrDeployRolledBack):
+		record.Status = models.DeployStatusRolledBack
+		record.Message = deployErr.Error()
+	case errors.Is(deployErr, ErrDeployCancelled), errors.Is(deployErr, ErrDeploySuperseded):
+		record.Status = models.DeployStatusCancelled
+		record.Message = deployErr.Error()
+	default:
+		record.Status = models.DeployStatusFailed
+		record.Message = deployErr.Error()
+	}
+	if err := m.saveDeployLog(record); err != nil {
+		fmt.Printf("保存部署记录失败: %v\n", err)
+	}
+	os.Remove(m.deployCancelPath(record.ID))
+	os.Remove(m.deployOwnerPath(record.ID))
+	job.owner.Unlock()
+
+	job.err = deployErr
+	close(job.done)
+}
+
+// CancelDeploy 取消排队中或正在进行的部署
+// 部署不在当前进程的队列中时（例如由服务进程执行，在命令行取消），
+// 写入取消标记，由执行部署的进程检测到后取消；执行部署的进程已退出时直接记为取消
+func (m *DeployManager) CancelDeploy(id string) (*models.DeployLog, error) {
+	if !deployIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("无效的部署ID: %s", id)
+	}
+
+	if m.cancelQueuedJob(id) {
+		return m.loadDeployLog(id)
+	}
+
+	record, err := m.loadDeployLog(id)
+	if err != nil {
+		return nil, err
+	}
+	if record.Status != models.DeployStatusQueued && record.Status != models.DeployStatusRunning {
+		return record, fmt.Errorf("部署 %s 已结束（状态: %s）", id, record.Status)
+	}
+
+	if !m.deployHasOwner(id) {
+		record, _, err := m.finishOrphanedDeploy(id, models.DeployStatusCancelled, ErrDeployCancelled.Error())
+		return record, err
+	}
+
+	if err := os.WriteFile(m.deployCancelPath(id), []byte(time.Now().Format(time.RFC3339)), 0644); err != nil {
+		return record, fmt.Errorf("写入取消请求失败: %v", err)
+	}
+	return record, nil
+}
+
+// cancelQueuedJob 在当前进程的队列中取消部署，找到对应的部署时返回 true
+func (m *DeployManager) cancelQueuedJob(id string) bool {
+	q := m.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, job := range q.running {
+		if job.record.ID == id {
+			fmt.Printf("取消正在进行的部署: %s\n", id)
+			job.cancel()
+			return true
+		}
+	}
+
+	for project, jobs := range q.pending {
+		for i, job := range jobs {
+			if job.record.ID != id {
+				continue
+			}
+
+			fmt.Printf("取消排队中的部署: %s\n", id)
+			q.pending[project] = append(jobs[:i:i], jobs[i+1:]...)
+			m.finishJob(job, ErrDeployCancelled)
+			q.cond.Broadcast()
+			return true
+		}
+	}
+
+	return false
+}
+
+// deployCancelPath 取消标记文件路径
+func (m *DeployManager) deployCancelPath(id string) string {
+	return filepath.Join(m.deployHistoryDir(), id+".cancel")
+}
+
+// watchCancelRequest 在部署结束前轮询取消标记
+func (m *DeployManager) watchCancelRequest(job *deployJob) {
+	ticker := time.NewTicker(cancelPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-job.done:
+			return
+		case <-ticker.C:
+			if _, err := os.Stat(m.deployCancelPath(job.record.ID)); err == nil {
+				m.cancelQueuedJob(job.record.ID)
+				return
+			}
+		}
+	}
+}
+
+// GetDeployQueue 获取当前进程中部署队列的状态
+func (m *DeployManager) GetDeployQueue() DeployQueueState {
+	q := m.queue
+	q.mu.Lock()
+	var runningIDs, queuedIDs []string
+	for _, job := range q.running {
+		runningIDs = append(runningIDs, job.record.ID)
+	}
+	for _, jobs := range q.pending {
+		for _, job := range jobs {
+			queuedIDs = append(queuedIDs, job.record.ID)
+		}
+	}
+	q.mu.Unlock()
+
+	state := DeployQueueState{
+		MaxConcurrent: m.maxConcurrentDeploys(),
+		Running:       m.loadDeployLogs(runningIDs),
+		Queued:        m.loadDeployLogs(queuedIDs),
+	}
+	return state
+}
+
+// loadDeployLogs 批量读取部署记录，按ID即入队时间排序，读取失败的记录被忽略
+func (m *DeployManager) loadDeployLogs(ids []string) []*models.DeployLog {
+	sort.Strings(ids)
+
+	records := make([]*models.DeployLog, 0, len(ids))
+	for _, id := range ids {
+		if record, err := m.loadDeployLog(id); err == nil {
+			records = append(records, record)
+		}
+	}
+	return records
+}
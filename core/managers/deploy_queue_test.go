@@ -0,0 +1,260 @@
+package managers
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"servon/core/models"
+)
+
+// blockingRunner 记录开始执行的部署，收到 release 或部署被取消后才返回
+type blockingRunner struct {
+	started chan string
+	release chan struct{}
+}
+
+func newBlockingRunner() *blockingRunner {
+	return &blockingRunner{
+		started: make(chan string, 16),
+		release: make(chan struct{}),
+	}
+}
+
+func (r *blockingRunner) run(ctx context.Context, record *models.DeployLog, req DeployRequest) error {
+	r.started <- record.ID
+	select {
+	case <-r.release:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// next 等待下一个开始执行的部署
+func (r *blockingRunner) next(t *testing.T) string {
+	t.Helper()
+	select {
+	case id := <-r.started:
+		return id
+	case <-time.After(5 * time.Second):
+		t.Fatal("Timed out waiting for deploy to start")
+		return ""
+	}
+}
+
+// expectIdle 确认一段时间内没有新的部署开始执行
+func (r *blockingRunner) expectIdle(t *testing.T) {
+	t.Helper()
+	select {
+	case id := <-r.started:
+		t.Fatalf("Expected no deploy to start, got %s", id)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// newTestDeployManager 创建只包含部署队列的管理器，部署由 runner 执行
+func newTestDeployManager(t *testing.T, maxConcurrent int, runner *blockingRunner) *DeployManager {
+	dir := t.TempDir()
+	m := &DeployManager{
+		logsDir:     filepath.Join(dir, "logs"),
+		tempDir:     filepath.Join(dir, "temp"),
+		projectsDir: filepath.Join(dir, "projects"),
+		configDir:   filepath.Join(dir, "config"),
+		queue:       newDeployQueue(),
+		runner:      runner.run,
+	}
+	if err := m.SaveDeploySettings(DeploySettings{KeepReleases: 1, MaxConcurrent: maxConcurrent}); err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
+
+func enqueueTest(t *testing.T, m *DeployManager, repo, trigger string) *deployJob {
+	t.Helper()
+	job, err := m.enqueue(DeployRequest{Repo: repo, Trigger: trigger})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return job
+}
+
+func waitJob(t *testing.T, job *deployJob) error {
+	t.Helper()
+	select {
+	case <-job.done:
+		return job.err
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Timed out waiting for deploy %s", job.record.ID)
+		return nil
+	}
+}
+
+func expectStatus(t *testing.T, m *DeployManager, job *deployJob, status string) {
+	t.Helper()
+	record, err := m.loadDeployLog(job.record.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Status != status {
+		t.Errorf("Deploy %s: expected status %s, got %s", job.record.ID, status, record.Status)
+	}
+}
+
+// TestDeployQueueCoalescesWebhooks 测试排队中的 webhook 部署被同一项目新的推送取代，手动部署不会被取代
+func TestDeployQueueCoalescesWebhooks(t *testing.T) {
+	runner := newBlockingRunner()
+	m := newTestDeployManager(t, 2, runner)
+
+	running := enqueueTest(t, m, "owner/app", models.DeployTriggerWebhook)
+	runner.next(t)
+
+	superseded := enqueueTest(t, m, "owner/app", models.DeployTriggerWebhook)
+	latest := enqueueTest(t, m, "owner/app", models.DeployTriggerWebhook)
+	if err := waitJob(t, superseded); !errors.Is(err, ErrDeploySuperseded) {
+		t.Errorf("Expected ErrDeploySuperseded, got %v", err)
+	}
+	expectStatus(t, m, superseded, models.DeployStatusCancelled)
+
+	manual1 := enqueueTest(t, m, "owner/app", models.DeployTriggerCLI)
+	manual2 := enqueueTest(t, m, "owner/app", models.DeployTriggerCLI)
+
+	close(runner.release)
+	for _, job := range []*deployJob{running, latest, manual1, manual2} {
+		if err := waitJob(t, job); err != nil {
+			t.Errorf("Deploy %s: expected success, got %v", job.record.ID, err)
+		}
+		expectStatus(t, m, job, models.DeployStatusSuccess)
+	}
+
+	// 同一项目的部署按入队顺序依次执行
+	for _, want := range []*deployJob{latest, manual1, manual2} {
+		if got := runner.next(t); got != want.record.ID {
+			t.Errorf("Expected %s to run next, got %s", want.record.ID, got)
+		}
+	}
+}
+
+// TestDeployQueueGlobalLimit 测试不同项目并行部署的数量受全局上限限制
+func TestDeployQueueGlobalLimit(t *testing.T) {
+	runner := newBlockingRunner()
+	m := newTestDeployManager(t, 2, runner)
+
+	jobs := []*deployJob{
+		enqueueTest(t, m, "owner/a", models.DeployTriggerCLI),
+		enqueueTest(t, m, "owner/b", models.DeployTriggerCLI),
+		enqueueTest(t, m, "owner/c", models.DeployTriggerCLI),
+	}
+	runner.next(t)
+	runner.next(t)
+	runner.expectIdle(t)
+
+	if state := m.GetDeployQueue(); len(state.Running) != 2 || len(state.Queued) != 1 {
+		t.Errorf("Expected 2 running and 1 queued, got %d and %d", len(state.Running), len(state.Queued))
+	}
+
+	close(runner.release)
+	runner.next(t)
+	for _, job := range jobs {
+		if err := waitJob(t, job); err != nil {
+			t.Errorf("Deploy %s: expected success, got %v", job.record.ID, err)
+		}
+	}
+}
+
+// TestCancelDeploy 测试取消排队中和正在进行的部署
+func TestCancelDeploy(t *testing.T) {
+	runner := newBlockingRunner()
+	m := newTestDeployManager(t, 2, runner)
+
+	running := enqueueTest(t, m, "owner/app", models.DeployTriggerCLI)
+	runner.next(t)
+	pending := enqueueTest(t, m, "owner/app", models.DeployTriggerCLI)
+
+	if _, err := m.CancelDeploy(pending.record.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitJob(t, pending); !errors.Is(err, ErrDeployCancelled) {
+		t.Errorf("Expected ErrDeployCancelled, got %v", err)
+	}
+	expectStatus(t, m, pending, models.DeployStatusCancelled)
+
+	if _, err := m.CancelDeploy(running.record.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := waitJob(t, running); !errors.Is(err, ErrDeployCancelled) {
+		t.Errorf("Expected ErrDeployCancelled, got %v", err)
+	}
+	expectStatus(t, m, running, models.DeployStatusCancelled)
+
+	// 取消的排队部署不会再执行
+	runner.expectIdle(t)
+
+	if _, err := m.CancelDeploy(running.record.ID); err == nil {
+		t.Error("Expected cancelling a finished deploy to fail")
+	}
+}
+
+// TestRecoverInterruptedDeploys 测试执行进程已退出的部署被记为失败，仍在执行的部署不受影响
+func TestRecoverInterruptedDeploys(t *testing.T) {
+	runner := newBlockingRunner()
+	m := newTestDeployManager(t, 2, runner)
+
+	running := enqueueTest(t, m, "owner/app", models.DeployTriggerCLI)
+	runner.next(t)
+
+	// 模拟被 kill -9 的进程留下的记录：状态为进行中，没有进程持有归属锁
+	orphan := &models.DeployLog{ID: "20240101000000-abcd", Project: "old", Status: models.DeployStatusRunning}
+	if err := m.saveDeployLog(orphan); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := m.RecoverInterruptedDeploys(); n != 1 {
+		t.Errorf("Expected 1 recovered deploy, got %d", n)
+	}
+	record, _ := m.loadDeployLog(orphan.ID)
+	if record.Status != models.DeployStatusFailed {
+		t.Errorf("Expected orphaned deploy to be failed, got %s", record.Status)
+	}
+	expectStatus(t, m, running, models.DeployStatusRunning)
+
+	// 跟踪已中断的部署时能够结束
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	orphan.ID, orphan.Status = "20240101000001-abcd", models.DeployStatusQueued
+	m.saveDeployLog(orphan)
+	record, err := m.FollowDeployOutput(ctx, orphan.ID, func(string) {})
+	if err != nil || record.Status != models.DeployStatusFailed {
+		t.Errorf("Expected follow to end with failed status, got %v, %v", record.Status, err)
+	}
+
+	close(runner.release)
+	waitJob(t, running)
+}
+
+// TestLockProject 测试项目锁在其他持有者释放前无法获取
+func TestLockProject(t *testing.T) {
+	m := newTestDeployManager(t, 1, newBlockingRunner())
+
+	unlock, err := m.lockProject(context.Background(), "app")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 直接获取文件锁，模拟另一个进程中的部署
+	path := filepath.Join(m.projectsDir, "app", projectLockFile)
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if _, err := lockFile(ctx, path); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected lock to be held, got %v", err)
+	}
+
+	unlock()
+	lock, err := lockFile(context.Background(), path)
+	if err != nil {
+		t.Fatalf("Expected lock to be released, got %v", err)
+	}
+	lock.Unlock()
+}
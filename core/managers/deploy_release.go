@@ -0,0 +1,415 @@
+package managers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"servon/core/contract"
+	"servon/core/models"
+)
+
+// DefaultKeepReleases 默认保留的发布版本数量
+const DefaultKeepReleases = 5
+
+// Release 表示项目的一个发布版本，版本ID与部署ID一致
+type Release struct {
+	ID        string    `json:"id"`         // 发布版本ID，即部署ID
+	Path      string    `json:"path"`       // 发布目录
+	Commit    string    `json:"commit"`     // 对应部署的提交 SHA
+	CreatedAt time.Time `json:"created_at"` // 创建时间
+	Current   bool      `json:"current"`    // 是否为当前版本
+}
+
+// DeploySettings 部署相关的全局配置
+type DeploySettings struct {
+	KeepReleases  int `json:"keep_releases"`  // 每个项目保留的发布版本数量
+	MaxConcurrent int `json:"max_concurrent"` // 同时进行的部署数量上限
+}
+
+// checkProjectName 校验项目名称，防止拼接出项目目录以外的路径
+func checkProjectName(projectName string) error {
+	if projectName == "" || projectName == "." || projectName == ".." || strings.ContainsAny(projectName, `/\`) {
+		return fmt.Errorf("无效的项目名称: %s", projectName)
+	}
+	return nil
+}
+
+// releasesDir 项目的发布目录，每个版本一个子目录
+func releasesDir(targetDir string) string {
+	return filepath.Join(targetDir, "releases")
+}
+
+// currentLink 指向当前版本的软链接
+func currentLink(targetDir string) string {
+	return filepath.Join(targetDir, "current")
+}
+
+// CreateRelease 将构建好的工作目录复制为新的发布版本，返回发布目录
+// 工作目录名即为部署ID，同时作为发布版本ID
+func (m *DeployManager) CreateRelease(targetDir, workDir string) (string, error) {
+	return m.PublishRelease(targetDir, filepath.Base(workDir), workDir)
+}
+
+// PublishRelease 将 srcDir 复制为指定ID的发布版本，返回发布目录
+// 用于只发布构建产物的部署器，如静态站点只发布输出目录
+func (m *DeployManager) PublishRelease(targetDir, releaseID, srcDir string) (string, error) {
+	releaseDir := filepath.Join(releasesDir(targetDir), releaseID)
+
+	if err := os.MkdirAll(releasesDir(targetDir), 0755); err != nil {
+		return "", fmt.Errorf("创建发布目录失败: %v", err)
+	}
+
+	// 保留 node_modules 中的相对软链接，.bin 中的命令才能找到所在包的其他文件
+	if err := m.fileUtil.CopyTree(srcDir, releaseDir); err != nil {
+		return "", fmt.Errorf("复制发布版本失败: %v", err)
+	}
+
+	return releaseDir, nil
+}
+
+// ActivateRelease 将 current 软链接原子地切换到指定版本
+// 先创建临时软链接再重命名覆盖，切换过程中 current 始终可用
+func (m *DeployManager) ActivateRelease(targetDir, releaseID string) error {
+	releaseDir := filepath.Join(releasesDir(targetDir), releaseID)
+	if info, err := os.Stat(releaseDir); err != nil || !info.IsDir() {
+		return fmt.Errorf("发布版本不存在: %s", releaseID)
+	}
+
+	link := currentLink(targetDir)
+	if info, err := os.Lstat(link); err == nil && info.Mode()&os.ModeSymlink == 0 {
+		return fmt.Errorf("%s 不是软链接，无法切换版本", link)
+	}
+
+	tmpLink := link + ".tmp-" + releaseID
+	os.Remove(tmpLink)
+	if err := os.Symlink(releaseDir, tmpLink); err != nil {
+		return fmt.Errorf("创建软链接失败: %v", err)
+	}
+
+	if err := os.Rename(tmpLink, link); err != nil {
+		os.Remove(tmpLink)
+		return fmt.Errorf("切换 current 软链接失败: %v", err)
+	}
+
+	return nil
+}
+
+// GetCurrentRelease 获取项目当前版本的ID，未发布时返回空字符串
+func (m *DeployManager) GetCurrentRelease(projectName string) string {
+	dest, err := os.Readlink(currentLink(filepath.Join(m.projectsDir, projectName)))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(dest)
+}
+
+// ListReleases 获取项目的所有发布版本，按时间倒序排列
+func (m *DeployManager) ListReleases(projectName string) ([]Release, error) {
+	if err := checkProjectName(projectName); err != nil {
+		return nil, err
+	}
+
+	targetDir := filepath.Join(m.projectsDir, projectName)
+
+	entries, err := os.ReadDir(releasesDir(targetDir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Release{}, nil
+		}
+		return nil, fmt.Errorf("读取发布目录失败: %v", err)
+	}
+
+	current := m.GetCurrentRelease(projectName)
+	releases := make([]Release, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		release := Release{
+			ID:      entry.Name(),
+			Path:    filepath.Join(releasesDir(targetDir), entry.Name()),
+			Current: entry.Name() == current,
+		}
+		if info, err := entry.Info(); err == nil {
+			release.CreatedAt = info.ModTime()
+		}
+		if record, err := m.loadDeployLog(entry.Name()); err == nil {
+			release.Commit = record.Commit
+			release.CreatedAt = record.Timestamp
+		}
+
+		releases = append(releases, release)
+	}
+
+	sort.Slice(releases, func(i, j int) bool {
+		return releases[i].ID > releases[j].ID
+	})
+
+	return releases, nil
+}
+
+// RollbackRequest 描述一次手动回滚
+type RollbackRequest struct {
+	Project     string // 项目名称
+	Release     string // 回滚到的版本，为空时回滚到当前版本的上一个版本
+	Trigger     string // 触发来源，见 models.DeployTrigger*
+	TriggeredBy string // 触发者
+}
+
+// Rollback 将项目回滚到指定版本并重启服务，回滚过程作为一条部署记录保存
+func (m *DeployManager) Rollback(req RollbackRequest) (*Release, error) {
+	projectName := req.Project
+	if err := checkProjectName(projectName); err != nil {
+		return nil, err
+	}
+
+	unlock, err := m.lockProject(context.Background(), projectName)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	releases, err := m.ListReleases(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	var target *Release
+	if req.Release == "" {
+		for i, release := range releases {
+			if release.Current && i+1 < len(releases) {
+				target = &releases[i+1]
+				break
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("项目 %s 没有可回滚的上一个版本", projectName)
+		}
+	} else {
+		for i, release := range releases {
+			if release.ID == req.Release {
+				target = &releases[i]
+				break
+			}
+		}
+		if target == nil {
+			return nil, fmt.Errorf("项目 %s 不存在版本 %s", projectName, req.Release)
+		}
+	}
+
+	now := time.Now()
+	record := &models.DeployLog{
+		ID:          newDeployID(),
+		QueuedAt:    now,
+		Timestamp:   now,
+		Project:     projectName,
+		Commit:      target.Commit,
+		Action:      models.DeployActionRollback,
+		Trigger:     req.Trigger,
+		TriggeredBy: req.TriggeredBy,
+		Status:      models.DeployStatusRunning,
+	}
+	if deployed, err := m.loadDeployLog(target.ID); err == nil {
+		record.Repo, record.Branch, record.Deployer = deployed.Repo, deployed.Branch, deployed.Deployer
+	}
+
+	// 与部署一样持有归属锁，回滚进程退出后记录不会一直停留在进行中
+	if err := os.MkdirAll(m.deployHistoryDir(), 0755); err != nil {
+		return nil, fmt.Errorf("创建部署记录目录失败: %v", err)
+	}
+	owner, err := lockFile(context.Background(), m.deployOwnerPath(record.ID))
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		os.Remove(m.deployOwnerPath(record.ID))
+		owner.Unlock()
+	}()
+	if err := m.saveDeployLog(record); err != nil {
+		return nil, err
+	}
+
+	logFile, err := os.OpenFile(m.deployOutputPath(record.ID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("创建部署日志失败: %v", err)
+	}
+	defer logFile.Close()
+
+	dctx := &contract.DeployContext{
+		Context:     context.Background(),
+		Output:      &syncWriter{w: logFile},
+		ID:          record.ID,
+		Commit:      target.Commit,
+		ProjectName: projectName,
+		TargetDir:   filepath.Join(m.projectsDir, projectName),
+	}
+	dctx.Printf("回滚项目 %s 到版本 %s\n", projectName, target.ID)
+
+	err = m.switchRelease(dctx, target.ID)
+	record.FinishedAt = time.Now()
+	if err != nil {
+		dctx.Println(err)
+		record.Status = models.DeployStatusFailed
+		record.Message = err.Error()
+	} else {
+		record.Status = models.DeployStatusSuccess
+		record.Message = "已回滚到版本 " + target.ID
+	}
+	if err := m.saveDeployLog(record); err != nil {
+		fmt.Printf("保存部署记录失败: %v\n", err)
+	}
+
+	target.Current = m.GetCurrentRelease(projectName) == target.ID
+	return target, err
+}
+
+// switchRelease 将 current 切换到指定版本，重启项目的所有后台服务，并调用部署该版本的部署器的回滚钩子
+// 手动回滚和健康检查失败后的回滚都通过这里切换版本
+func (m *DeployManager) switchRelease(dctx *contract.DeployContext, releaseID string) error {
+	if err := m.ActivateRelease(dctx.TargetDir, releaseID); err != nil {
+		return err
+	}
+	dctx.Printf("已切换到版本 %s\n", releaseID)
+
+	if err := m.restartProjectServices(dctx); err != nil {
+		return fmt.Errorf("版本已切换，但%v", err)
+	}
+
+	deployed, err := m.loadDeployLog(releaseID)
+	if err != nil {
+		return nil
+	}
+	if hook, ok := m.getDeployer(deployed.Deployer).(contract.RollbackHook); ok {
+		if err := hook.AfterRollback(dctx); err != nil {
+			return fmt.Errorf("版本已切换，但执行 %s 的回滚钩子失败: %v", deployed.Deployer, err)
+		}
+	}
+	return nil
+}
+
+// projectServices 项目的后台服务：与项目同名的服务，以及名称以 "项目名-" 开头、
+// 工作目录在项目目录中的服务（如 Laravel 的队列 worker），后者排除了名称相近的其他项目
+func (m *DeployManager) projectServices(projectName string) []string {
+	targetDir := filepath.Join(m.projectsDir, projectName)
+
+	var names []string
+	if m.services.HasServiceConf(projectName) {
+		names = append(names, projectName)
+	}
+	for _, name := range m.services.GetServiceNames(projectName + "-") {
+		spec, err := m.services.GetServiceSpec(name)
+		if err != nil {
+			continue
+		}
+		if spec.WorkingDir == targetDir || strings.HasPrefix(spec.WorkingDir, targetDir+string(filepath.Separator)) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// restartProjectServices 重启项目的所有后台服务，使其运行切换后的版本，项目没有后台服务时忽略
+func (m *DeployManager) restartProjectServices(dctx *contract.DeployContext) error {
+	var failed []string
+	for _, name := range m.projectServices(dctx.ProjectName) {
+		if err := m.services.Restart(name); err != nil {
+			dctx.Printf("重启服务 %s 失败: %v\n", name, err)
+			failed = append(failed, name)
+			continue
+		}
+		dctx.Printf("已重启服务: %s\n", name)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("重启服务失败: %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// PruneReleases 清理超出保留数量的旧版本，当前版本不会被删除，返回被清理的版本
+func (m *DeployManager) PruneReleases(projectName string) ([]Release, error) {
+	keep := m.GetDeploySettings().KeepReleases
+	if keep <= 0 {
+		return nil, nil
+	}
+
+	releases, err := m.ListReleases(projectName)
+	if err != nil {
+		return nil, err
+	}
+
+	removed := []Release{}
+	for i, release := range releases {
+		if i < keep || release.Current {
+			continue
+		}
+
+		if err := os.RemoveAll(release.Path); err != nil {
+			return removed, fmt.Errorf("清理旧版本 %s 失败: %v", release.ID, err)
+		}
+		removed = append(removed, release)
+	}
+
+	return removed, nil
+}
+
+// deploySettingsPath 部署配置文件路径
+func (m *DeployManager) deploySettingsPath() string {
+	return filepath.Join(m.configDir, "deploy.json")
+}
+
+// defaultDeploySettings 默认的部署配置
+func defaultDeploySettings() DeploySettings {
+	return DeploySettings{
+		KeepReleases:  DefaultKeepReleases,
+		MaxConcurrent: DefaultMaxConcurrentDeploys,
+	}
+}
+
+// GetDeploySettings 获取部署配置，配置文件不存在时返回默认值
+func (m *DeployManager) GetDeploySettings() DeploySettings {
+	settings := defaultDeploySettings()
+
+	data, err := os.ReadFile(m.deploySettingsPath())
+	if err != nil {
+		return settings
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		fmt.Printf("解析部署配置失败，使用默认配置: %v\n", err)
+		return defaultDeploySettings()
+	}
+
+	return settings
+}
+
+// SaveDeploySettings 保存部署配置
+func (m *DeployManager) SaveDeploySettings(settings DeploySettings) error {
+	if settings.KeepReleases < 1 {
+		return fmt.Errorf("保留的版本数量至少为 1")
+	}
+	if settings.MaxConcurrent < 1 {
+		return fmt.Errorf("同时进行的部署数量至少为 1")
+	}
+
+	if err := os.MkdirAll(m.configDir, 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化部署配置失败: %v", err)
+	}
+
+	if err := os.WriteFile(m.deploySettingsPath(), data, 0644); err != nil {
+		return fmt.Errorf("写入部署配置失败: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package managers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"servon/components/utils"
+)
+
+// writeFixture 按 路径 -> 内容 写入文件
+func writeFixture(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestPublishReleaseKeepsBinLinks 测试发布版本保留 node_modules/.bin 中的相对软链接，
+// 命令脚本中的相对 require 在发布目录中仍然有效，源目录删除后也能运行
+func TestPublishReleaseKeepsBinLinks(t *testing.T) {
+	m := &DeployManager{fileUtil: utils.DefaultFileUtil}
+	dir := t.TempDir()
+	workDir := filepath.Join(dir, "work")
+	targetDir := filepath.Join(dir, "project")
+
+	writeFixture(t, workDir, map[string]string{
+		"node_modules/tool/package.json": `{"name": "tool", "bin": {"tool": "bin/cli.js"}}`,
+		"node_modules/tool/bin/cli.js":   "#!/usr/bin/env node\nconsole.log(require('../lib/index.js'))\n",
+		"node_modules/tool/lib/index.js": "module.exports = 'tool ok'\n",
+	})
+	if err := os.MkdirAll(filepath.Join(workDir, "node_modules", ".bin"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../tool/bin/cli.js", filepath.Join(workDir, "node_modules", ".bin", "tool")); err != nil {
+		t.Fatal(err)
+	}
+
+	// 指向源目录之外的软链接复制为文件
+	external := filepath.Join(dir, "external.txt")
+	writeFixture(t, dir, map[string]string{"external.txt": "external"})
+	if err := os.Symlink(external, filepath.Join(workDir, "external.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	releaseDir, err := m.PublishRelease(targetDir, "20240101000000-abcd", workDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.RemoveAll(workDir)
+	os.Remove(external)
+
+	bin := filepath.Join(releaseDir, "node_modules", ".bin", "tool")
+	if target, err := os.Readlink(bin); err != nil || target != "../tool/bin/cli.js" {
+		t.Errorf("Expected .bin/tool to stay a relative symlink, got %q, %v", target, err)
+	}
+	if info, err := os.Lstat(filepath.Join(releaseDir, "external.txt")); err != nil || info.Mode()&os.ModeSymlink != 0 {
+		t.Errorf("Expected external symlink to be copied as a file, got %v", err)
+	}
+
+	if _, err := exec.LookPath("node"); err != nil {
+		t.Skip("node not installed")
+	}
+	out, err := exec.Command("node", bin).CombinedOutput()
+	if err != nil || strings.TrimSpace(string(out)) != "tool ok" {
+		t.Errorf("Expected .bin shim to run from the release, got %q, %v", out, err)
+	}
+}
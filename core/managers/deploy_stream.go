@@ -0,0 +1,101 @@
+package managers
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"servon/core/models"
+)
+
+// followPollInterval 跟踪部署输出时检查新输出的间隔
+const followPollInterval = 300 * time.Millisecond
+
+// isDeployFinished 判断部署是否已经结束
+func isDeployFinished(record *models.DeployLog) bool {
+	return record.Status != models.DeployStatusQueued && record.Status != models.DeployStatusRunning
+}
+
+// FollowDeployOutput 逐行读取部署输出并交给 onLine 处理
+// 部署未结束时持续等待新的输出，直到部署结束或 ctx 取消，返回最新的部署记录
+// 输出从部署日志文件中读取，因此可以跟踪其他进程执行的部署；执行部署的进程退出后部署记为失败
+func (m *DeployManager) FollowDeployOutput(ctx context.Context, id string, onLine func(line string)) (*models.DeployLog, error) {
+	if !deployIDPattern.MatchString(id) {
+		return nil, fmt.Errorf("无效的部署ID: %s", id)
+	}
+
+	record, err := m.loadDeployLog(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		file    *os.File
+		reader  *bufio.Reader
+		partial strings.Builder
+	)
+	defer func() {
+		if file != nil {
+			file.Close()
+		}
+	}()
+
+	// drain 读取当前已写入的全部输出，不完整的最后一行留到下次
+	drain := func() error {
+		if file == nil {
+			f, err := os.Open(m.deployOutputPath(id))
+			if err != nil {
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return fmt.Errorf("读取部署输出失败: %v", err)
+			}
+			file, reader = f, bufio.NewReader(f)
+		}
+
+		for {
+			chunk, err := reader.ReadString('\n')
+			partial.WriteString(chunk)
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("读取部署输出失败: %v", err)
+			}
+
+			onLine(strings.TrimRight(partial.String(), "\r\n"))
+			partial.Reset()
+		}
+	}
+
+	for {
+		// 先读取状态再读取输出，部署结束前写入的输出都能被读到
+		finished := isDeployFinished(record)
+
+		if err := drain(); err != nil {
+			return record, err
+		}
+
+		if finished {
+			if partial.Len() > 0 {
+				onLine(partial.String())
+			}
+			return record, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return record, ctx.Err()
+		case <-time.After(followPollInterval):
+		}
+
+		if latest, _, err := m.finishOrphanedDeploy(id, models.DeployStatusFailed, ErrDeployInterrupted.Error()); err == nil {
+			record = latest
+		}
+	}
+}
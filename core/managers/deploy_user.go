@@ -0,0 +1,98 @@
+package managers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	osuser "os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// projectUserPrefix 项目专用用户的名称前缀
+const projectUserPrefix = "servon-"
+
+// maxUserNameLength useradd 允许的最长用户名
+const maxUserNameLength = 32
+
+var invalidUserChars = regexp.MustCompile(`[^a-z0-9_-]+`)
+
+// projectUserHashLength 用户名中项目名哈希的长度
+const projectUserHashLength = 8
+
+// ProjectUser 项目专用的系统用户名，如 servon-blog
+// 用户名只能包含小写字母、数字、下划线和短横线，项目名中的其他字符会被替换为短横线；
+// 项目名被修改或截断时加上项目名的哈希，避免 a.b 和 a-b 这类项目共用同一个用户
+func ProjectUser(projectName string) string {
+	base := invalidUserChars.ReplaceAllString(strings.ToLower(projectName), "-")
+	name := strings.TrimRight(projectUserPrefix+base, "-")
+	if name == projectUserPrefix+projectName && len(name) <= maxUserNameLength {
+		return name
+	}
+
+	sum := sha256.Sum256([]byte(projectName))
+	hash := hex.EncodeToString(sum[:])[:projectUserHashLength]
+	if keep := maxUserNameLength - len(projectUserPrefix) - len(hash) - 1; len(base) > keep {
+		base = base[:keep]
+	}
+	if base = strings.Trim(base, "-"); base == "" {
+		return projectUserPrefix + hash
+	}
+	return projectUserPrefix + base + "-" + hash
+}
+
+// PrepareProjectUser 确定运行项目服务的用户，并把发布版本的文件交给该用户
+// configured 为 servon.yaml 中的 user，为空时使用项目专用用户，不存在时创建不能登录的系统用户；
+// 为 root 时不修改文件的所有者
+func (m *DeployManager) PrepareProjectUser(projectName, releaseDir, configured string) (string, error) {
+	username := configured
+	if username == "" {
+		username = ProjectUser(projectName)
+	}
+	if username == "root" {
+		return username, nil
+	}
+
+	exists, err := m.users.UserExists(username)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		if configured != "" {
+			return "", fmt.Errorf("用户 %s 不存在", username)
+		}
+		if err := m.users.CreateSystemUser(username); err != nil {
+			return "", err
+		}
+	}
+
+	if err := chownTree(releaseDir, username); err != nil {
+		return "", err
+	}
+	return username, nil
+}
+
+// chownTree 把目录及其中的所有文件交给 username，软链接本身被修改而不跟随
+func chownTree(dir, username string) error {
+	account, err := osuser.Lookup(username)
+	if err != nil {
+		return fmt.Errorf("查找用户 %s 失败: %v", username, err)
+	}
+	uid, _ := strconv.Atoi(account.Uid)
+	gid, _ := strconv.Atoi(account.Gid)
+
+	err = filepath.WalkDir(dir, func(path string, _ fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		return os.Lchown(path, uid, gid)
+	})
+	if err != nil {
+		return fmt.Errorf("修改 %s 的所有者失败: %v", dir, err)
+	}
+	return nil
+}
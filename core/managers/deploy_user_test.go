@@ -0,0 +1,30 @@
+package managers
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestProjectUser 测试项目用户名的生成，不同项目不会得到相同的用户名
+func TestProjectUser(t *testing.T) {
+	if got := ProjectUser("blog"); got != "servon-blog" {
+		t.Errorf("Expected servon-blog, got %s", got)
+	}
+
+	long := strings.Repeat("project", 6)
+	names := []string{"a.b", "a-b", "A-b", "a_b", "a-", "...", long + "1", long + "2", "blog"}
+	seen := map[string]string{}
+	for _, name := range names {
+		user := ProjectUser(name)
+		if len(user) > maxUserNameLength {
+			t.Errorf("%s: expected at most %d characters, got %s", name, maxUserNameLength, user)
+		}
+		if !strings.HasPrefix(user, projectUserPrefix) || invalidUserChars.MatchString(user) || strings.HasSuffix(user, "-") {
+			t.Errorf("%s: invalid user name %s", name, user)
+		}
+		if other, ok := seen[user]; ok {
+			t.Errorf("Expected %s and %s to get different users, both got %s", other, name, user)
+		}
+		seen[user] = name
+	}
+}
@@ -8,6 +8,8 @@ import (
 )
 
 type FullManager struct {
+	*AuthManager
+	*TokenManager
 	*CronManager
 	*DeployManager
 	*DownloadManager
@@ -43,12 +45,15 @@ func NewManager(eventBus events.IEventBus) *FullManager {
 		dataManager.GetLogsRootFolder(),
 		dataManager.GetTempRootFolder(),
 		dataManager.GetProjectsFolder(),
+		dataManager.GetConfigRootFolder(),
 	)
 	if err != nil {
 		panic(fmt.Sprintf("Failed to create deploy manager: %v", err))
 	}
 
 	core := &FullManager{
+		AuthManager:            NewAuthManager(dataManager.GetConfigRootFolder()),
+		TokenManager:           NewTokenManager(dataManager.GetConfigRootFolder()),
 		CronManager:            DefaultCronManager,
 		SoftManager:            softManager,
 		DataManager:            dataManager,
@@ -27,3 +27,4 @@ var PrintErrorMessage = logger.ErrorMessage
 var RunShell = shell.RunShell
 var RunShellWithSudo = shell.RunShellWithSudo
 var RunShellWithOutput = shell.RunShellWithOutput
+var RunShellInFolder = shell.RunShellInFolder
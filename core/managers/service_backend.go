@@ -0,0 +1,94 @@
+package managers
+
+import (
+	"os"
+	"path/filepath"
+	"servon/components/supervisor_util"
+	"strings"
+)
+
+const (
+	// ServiceBackendSupervisor 通过 supervisor 管理后台服务
+	ServiceBackendSupervisor = "supervisor"
+	// ServiceBackendSystemd 通过 systemd 管理后台服务
+	ServiceBackendSystemd = "systemd"
+)
+
+// ServiceBackend 后台服务的运行后端，ServiceManager 的启停、状态和日志都交给后端完成
+// 服务名称是 Servon 中的名称，后端负责转换为自己的程序名或 unit 名
+type ServiceBackend interface {
+	// Name 后端名称
+	Name() string
+
+	// CheckAvailable 检查后端是否可用，不可用时返回 ErrServiceBackendUnavailable
+	CheckAvailable() error
+
+	// ConfigFileName 服务配置文件的文件名
+	ConfigFileName(serviceName string) string
+
+	// ConfigSection 配置文件中服务自身配置所在的段名，如 program:web
+	ConfigSection(serviceName string) string
+
+	// RenderConfig 根据已填充默认值的服务定义生成配置文件内容
+	RenderConfig(spec ServiceSpec) (string, error)
+
+	// Install 让后端加载 Servon 目录中的配置文件
+	Install(serviceName, configPath string) error
+
+	// Uninstall 撤销 Install 所做的操作
+	Uninstall(serviceName string) error
+
+	// Reload 让后端重新读取配置文件
+	Reload() error
+
+	Start(serviceName string) error
+	Stop(serviceName string) error
+	Restart(serviceName string) error
+
+	// Status 获取单个服务的状态
+	Status(serviceName string) (*ServiceStatus, error)
+
+	// Statuses 获取后端中所有服务的状态
+	Statuses() ([]ServiceStatus, error)
+
+	// Logs 获取服务最后 lines 行日志，stream 为 stdout、stderr 或空（两者都返回）
+	Logs(serviceName string, lines int, stream string) (string, error)
+
+	// LogFiles 服务的日志文件，key 为输出流，日志不在文件中时返回空
+	LogFiles(serviceName string) map[string]string
+}
+
+// detectServiceBackend 选择后台服务的后端，1 号进程为 systemd 时使用 systemd
+// 已经通过 supervisor 创建过服务时继续使用 supervisor，避免同一个服务在两个后端中各运行一份
+func detectServiceBackend() string {
+	if !isSystemdInit() {
+		return ServiceBackendSupervisor
+	}
+
+	confDir := filepath.Join(DefaultDataManager.GetSoftwareRootFolder(ServiceBackendSupervisor), "conf.d")
+	if confs, _ := filepath.Glob(filepath.Join(confDir, "*.conf")); len(confs) > 0 {
+		return ServiceBackendSupervisor
+	}
+
+	return ServiceBackendSystemd
+}
+
+// isSystemdInit 判断 1 号进程是否为 systemd
+func isSystemdInit() bool {
+	comm, err := os.ReadFile("/proc/1/comm")
+	if err != nil {
+		return false
+	}
+	return strings.TrimSpace(string(comm)) == "systemd"
+}
+
+// newServiceBackend 根据名称创建后端，rootFolder 为后端在 Servon 数据目录中的目录
+func newServiceBackend(name, rootFolder string) ServiceBackend {
+	if name == ServiceBackendSystemd {
+		return &systemdBackend{}
+	}
+	return &supervisorBackend{
+		rootFolder: rootFolder,
+		client:     supervisor_util.NewClient(supervisor_util.DefaultSocket),
+	}
+}
@@ -0,0 +1,170 @@
+package managers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// RawConfigKey 服务配置中表示完整配置文件内容的键，只读
+const RawConfigKey = "raw_config"
+
+// ErrInvalidServiceConfig 提交的服务配置无效
+var ErrInvalidServiceConfig = errors.New("无效的服务配置")
+
+// GetServiceConfig 获取 Servon 创建的服务的配置
+// 有服务定义时返回服务定义的各字段，否则返回配置文件中服务所在段（如 [program:x]）的配置项，两种情况都附带生成的配置文件内容
+func (p *ServiceManager) GetServiceConfig(serviceName string) (map[string]interface{}, error) {
+	content, err := p.readServiceConf(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	config := map[string]interface{}{}
+	spec, err := p.GetServiceSpec(serviceName)
+	switch {
+	case err == nil:
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return nil, fmt.Errorf("序列化服务定义失败: %v", err)
+		}
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, fmt.Errorf("序列化服务定义失败: %v", err)
+		}
+	case errors.Is(err, ErrServiceNotFound):
+		for key, value := range parseConfigSection(content, p.backend.ConfigSection(serviceName)) {
+			config[key] = value
+		}
+	default:
+		return nil, err
+	}
+
+	config[RawConfigKey] = content
+	return config, nil
+}
+
+// UpdateServiceConfig 修改服务定义中的字段，重新生成配置文件后让后端重新加载并重启服务
+// config 的键为 ServiceSpec 的 JSON 字段名，未提供的字段保持不变；重新加载失败时恢复原来的服务定义和配置文件
+func (p *ServiceManager) UpdateServiceConfig(serviceName string, config map[string]interface{}) error {
+	original, err := p.readServiceConf(serviceName)
+	if err != nil {
+		return err
+	}
+
+	current, err := p.GetServiceSpec(serviceName)
+	if errors.Is(err, ErrServiceNotFound) {
+		return fmt.Errorf("%w: %s 没有服务定义，请重新部署后再修改", ErrInvalidServiceConfig, serviceName)
+	}
+	if err != nil {
+		return err
+	}
+
+	if _, ok := config[RawConfigKey]; ok {
+		return fmt.Errorf("%w: %s 是生成的配置文件，不能直接修改", ErrInvalidServiceConfig, RawConfigKey)
+	}
+	if len(config) == 0 {
+		return fmt.Errorf("%w: 没有要修改的配置项", ErrInvalidServiceConfig)
+	}
+
+	spec, err := mergeServiceSpec(*current, config)
+	if err != nil {
+		return err
+	}
+	if spec.Name != serviceName {
+		return fmt.Errorf("%w: 不能修改服务名称", ErrInvalidServiceConfig)
+	}
+
+	spec, err = p.prepareSpec(spec)
+	if err != nil {
+		return err
+	}
+	content, err := p.backend.RenderConfig(spec)
+	if err != nil {
+		return err
+	}
+
+	if err := p.saveSpec(spec); err != nil {
+		return err
+	}
+	if err := p.writeServiceConf(serviceName, content); err != nil {
+		p.saveSpec(*current)
+		return err
+	}
+	if err := p.Reload(serviceName); err != nil {
+		p.saveSpec(*current)
+		p.writeServiceConf(serviceName, original)
+		p.Reload(serviceName)
+		return fmt.Errorf("重载服务配置失败，已恢复原配置: %w", err)
+	}
+
+	return p.Restart(serviceName)
+}
+
+// mergeServiceSpec 把 config 中的字段覆盖到 spec 上，不认识的字段视为无效配置
+func mergeServiceSpec(spec ServiceSpec, config map[string]interface{}) (ServiceSpec, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return spec, fmt.Errorf("%w: %v", ErrInvalidServiceConfig, err)
+	}
+
+	// 解码会向已有的 map 中追加，提交了 env 时整体替换
+	if _, ok := config["env"]; ok {
+		spec.Env = nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return spec, fmt.Errorf("%w: %v", ErrInvalidServiceConfig, err)
+	}
+	return spec, nil
+}
+
+// readServiceConf 读取服务配置文件，只有 Servon 创建的服务才有
+func (p *ServiceManager) readServiceConf(serviceName string) (string, error) {
+	data, err := os.ReadFile(p.GetServiceFilePath(serviceName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: %s 不是由 Servon 创建的服务", ErrServiceNotFound, serviceName)
+		}
+		return "", fmt.Errorf("读取服务配置失败: %v", err)
+	}
+	return string(data), nil
+}
+
+// writeServiceConf 先写临时文件再重命名，避免后端读取到写了一半的配置
+func (p *ServiceManager) writeServiceConf(serviceName, content string) error {
+	path := p.GetServiceFilePath(serviceName)
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, []byte(content), 0644); err != nil {
+		return fmt.Errorf("写入服务配置失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入服务配置失败: %v", err)
+	}
+	return nil
+}
+
+// parseConfigSection 解析配置文件中 [section] 的配置项
+func parseConfigSection(content, section string) map[string]string {
+	values := map[string]string{}
+	inSection := false
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inSection = line == "["+section+"]"
+			continue
+		}
+		if !inSection || line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if key, value, ok := strings.Cut(line, "="); ok {
+			values[strings.TrimSpace(key)] = strings.TrimSpace(value)
+		}
+	}
+	return values
+}
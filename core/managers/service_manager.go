@@ -1,190 +1,97 @@
 package managers
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"strings"
-	"text/template"
-
-	"servon/core/templates"
 )
 
 var DefaultServiceManager = newServiceManager()
 
+// ServiceManager 管理 Servon 创建的后台服务，具体的启停由 supervisor 或 systemd 后端完成
+// 配置文件保存在 RootFolder/conf.d 中，再由后端链接到自己的配置目录
 type ServiceManager struct {
 	RootFolder string
 	ConfigDir  string
-}
-
-type SupervisorConfig struct {
-	ServiceName string
-	Command     string
-	Args        string
-	RootFolder  string
-	WorkingDir  string
-	Environment string
+	backend    ServiceBackend
 }
 
 func newServiceManager() *ServiceManager {
+	name := detectServiceBackend()
+	rootFolder := DefaultDataManager.GetSoftwareRootFolder(name)
 	return &ServiceManager{
-		RootFolder: DefaultDataManager.GetSoftwareRootFolder("supervisor"),
-		ConfigDir:  DefaultDataManager.GetSoftwareRootFolder("supervisor") + "/conf.d",
+		RootFolder: rootFolder,
+		ConfigDir:  rootFolder + "/conf.d",
+		backend:    newServiceBackend(name, rootFolder),
 	}
 }
 
-func (p *ServiceManager) CheckSupervisorInstalled() error {
-	cmd := exec.Command("which", "supervisord")
-	if err := cmd.Run(); err != nil {
-		PrintErrorMessage("Supervisor未安装，请先安装Supervisor")
-		PrintInfo("Ubuntu/Debian: sudo apt-get install supervisor")
-		PrintInfo("CentOS/RHEL: sudo yum install supervisor")
-		return fmt.Errorf("supervisor未安装")
-	}
-	return nil
+// BackendName 当前使用的后端名称，supervisor 或 systemd
+func (p *ServiceManager) BackendName() string {
+	return p.backend.Name()
+}
+
+// CheckAvailable 检查后端是否可用
+func (p *ServiceManager) CheckAvailable() error {
+	return p.backend.CheckAvailable()
 }
 
 func (p *ServiceManager) ensureConfigDir() error {
 	if err := os.MkdirAll(p.ConfigDir, 0755); err != nil {
-		return fmt.Errorf("创建Supervisor配置目录失败: %v", err)
-	}
-
-	// 确保日志目录存在
-	logDir := filepath.Join(p.RootFolder, "logs")
-	if err := os.MkdirAll(logDir, 0755); err != nil {
-		return fmt.Errorf("创建Supervisor日志目录失败: %v", err)
+		return fmt.Errorf("创建服务配置目录失败: %v", err)
 	}
-
 	return nil
 }
 
-// getSupervisorConfigDir 获取supervisor配置目录
-func (p *ServiceManager) getSupervisorConfigDir() (string, error) {
-	// 常见的supervisor配置目录
-	configDirs := []string{
-		"/etc/supervisor/conf.d",
-		"/etc/supervisord.d",
-	}
-
-	for _, dir := range configDirs {
-		if _, err := os.Stat(dir); err == nil {
-			return dir, nil
-		}
-	}
-
-	return "", fmt.Errorf("未找到supervisor配置目录")
-}
-
-// createConfig 创建supervisor配置文件，返回配置文件路径
-func (p *ServiceManager) createConfig(serviceName string, command string, args []string, envVars []string) (string, error) {
-	if p.HasServiceConf(serviceName) {
-		return "", fmt.Errorf("服务配置文件已存在: %s", serviceName)
+// prepareSpec 填充默认值、检查服务定义，并把命令转换为绝对路径
+func (p *ServiceManager) prepareSpec(spec ServiceSpec) (ServiceSpec, error) {
+	spec = spec.withDefaults(p.RootFolder)
+	if err := spec.validate(); err != nil {
+		return spec, err
 	}
 
-	configPath := filepath.Join(p.ConfigDir, serviceName+".conf")
-
-	// 获取命令的绝对路径
-	absCommand, err := exec.LookPath(command)
+	absCommand, err := exec.LookPath(spec.Command)
 	if err != nil {
-		return "", fmt.Errorf("找不到命令 %s: %v", command, err)
+		return spec, fmt.Errorf("%w: 找不到命令 %s", ErrInvalidServiceConfig, spec.Command)
 	}
+	spec.Command = absCommand
+	return spec, nil
+}
 
-	// 获取当前工作目录
-	workingDir, err := os.Getwd()
-	if err != nil {
-		return "", fmt.Errorf("获取工作目录失败: %v", err)
+// createConfig 保存服务定义，生成配置文件并交给后端加载，返回配置文件路径
+func (p *ServiceManager) createConfig(spec ServiceSpec) (string, error) {
+	if p.HasServiceConf(spec.Name) {
+		return "", fmt.Errorf("%w: %s", ErrServiceExists, spec.Name)
 	}
 
-	// 获取模板内容
-	tmplContent, err := templates.GetSupervisorConfigTemplate()
-	if err != nil {
-		return "", fmt.Errorf("获取模板内容失败: %v", err)
-	}
+	configPath := p.GetServiceFilePath(spec.Name)
 
-	// 解析模板
-	tmpl, err := template.New("supervisor").Parse(tmplContent)
+	content, err := p.backend.RenderConfig(spec)
 	if err != nil {
-		return "", fmt.Errorf("解析supervisor模板失败: %v", err)
+		return "", err
 	}
 
-	// 准备模板数据
-	config := SupervisorConfig{
-		ServiceName: serviceName,
-		Command:     absCommand,
-		Args:        strings.Join(args, " "),
-		RootFolder:  p.RootFolder,
-		WorkingDir:  workingDir,
-		Environment: strings.Join(envVars, ","),
+	if err := p.saveSpec(spec); err != nil {
+		return "", err
 	}
 
-	// 创建配置文件
-	f, err := os.Create(configPath)
-	if err != nil {
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
 		return "", fmt.Errorf("创建配置文件失败: %v", err)
 	}
-	defer f.Close()
-
-	// 执行模板
-	if err := tmpl.Execute(f, config); err != nil {
-		return "", fmt.Errorf("生成配置文件失败: %v", err)
-	}
-
-	// 获取supervisor系统配置目录
-	supervisorConfigDir, err := p.getSupervisorConfigDir()
-	if err != nil {
-		return "", fmt.Errorf("获取supervisor配置目录失败: %v", err)
-	}
 
-	// 创建软链接
-	systemConfigPath := filepath.Join(supervisorConfigDir, serviceName+".conf")
-	// 如果已存在，先删除
-	if _, err := os.Lstat(systemConfigPath); err == nil {
-		if err := os.Remove(systemConfigPath); err != nil {
-			return "", fmt.Errorf("删除已存在的配置文件软链接失败: %v", err)
-		}
-	}
-
-	// 创建软链接
-	if err := os.Symlink(configPath, systemConfigPath); err != nil {
-		return "", fmt.Errorf("创建配置文件软链接失败: %v", err)
+	if err := p.backend.Install(spec.Name, configPath); err != nil {
+		return "", err
 	}
 
-	PrintInfof("已创建配置文件软链接: %s -> %s", systemConfigPath, configPath)
-
 	return configPath, nil
 }
 
-func (p *ServiceManager) CheckSupervisorRunning() error {
-	// 首先检查是否安装
-	if err := p.CheckSupervisorInstalled(); err != nil {
-		return err
-	}
-
-	// 检查 supervisord 是否运行
-	cmd := exec.Command("pgrep", "supervisord")
-	if err := cmd.Run(); err != nil {
-		PrintErrorMessage("Supervisor守护进程未运行")
-		PrintCommandOutput("请使用以下命令启动 Supervisor:")
-		PrintCommandOutput("supervisord -c /etc/supervisor/supervisord.conf")
-
-		return fmt.Errorf("supervisor守护进程未运行")
-	}
-
-	// 检查 socket 文件
-	if _, err := os.Stat("/var/run/supervisor.sock"); os.IsNotExist(err) {
-		PrintErrorf("Supervisor socket 文件不存在")
-		PrintInfo("请检查 Supervisor 配置文件是否正确")
-		PrintInfo("配置文件通常位于: /etc/supervisor/supervisord.conf")
-		return fmt.Errorf("supervisor socket 文件不存在")
-	}
-
-	return nil
-}
-
 // HasServiceConf 判断服务配置文件是否存在
 func (p *ServiceManager) HasServiceConf(serviceName string) bool {
-	if _, err := os.Stat(filepath.Join(p.ConfigDir, serviceName+".conf")); os.IsNotExist(err) {
+	if _, err := os.Stat(p.GetServiceFilePath(serviceName)); os.IsNotExist(err) {
 		return false
 	}
 
@@ -193,43 +100,41 @@ func (p *ServiceManager) HasServiceConf(serviceName string) bool {
 
 // GetServiceFilePath 获取服务配置文件路径
 func (p *ServiceManager) GetServiceFilePath(serviceName string) string {
-	return filepath.Join(p.ConfigDir, serviceName+".conf")
+	return filepath.Join(p.ConfigDir, p.backend.ConfigFileName(serviceName))
 }
 
-func (p *ServiceManager) IsActive(serviceName string) bool {
-	if err := p.CheckSupervisorInstalled(); err != nil {
-		return false
+// GetServiceNames 获取 Servon 创建的、名称以 prefix 开头的服务
+func (p *ServiceManager) GetServiceNames(prefix string) []string {
+	pattern := p.backend.ConfigFileName(prefix + "*")
+	configs, _ := filepath.Glob(filepath.Join(p.ConfigDir, pattern))
+
+	before, after, _ := strings.Cut(pattern, "*")
+	names := make([]string, 0, len(configs))
+	for _, config := range configs {
+		name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(config), before), after)
+		names = append(names, prefix+name)
 	}
+	return names
+}
 
-	cmd := exec.Command("supervisorctl", "status", serviceName)
-	output, err := cmd.CombinedOutput()
+func (p *ServiceManager) IsActive(serviceName string) bool {
+	status, err := p.backend.Status(serviceName)
 	if err != nil {
-		PrintErrorf("检查服务状态失败 %s: %v", serviceName, err)
+		if !errors.Is(err, ErrServiceNotFound) {
+			PrintErrorf("检查服务状态失败 %s: %v", serviceName, err)
+		}
 		return false
 	}
 
-	outputStr := string(output)
-	return strings.Contains(outputStr, "RUNNING")
+	return status.Status == "RUNNING"
 }
 
 // Reload 重载服务
 func (p *ServiceManager) Reload(serviceName string) error {
-	if err := p.CheckSupervisorInstalled(); err != nil {
-		return err
-	}
-
 	PrintInfof("正在重载服务: %s", serviceName)
 
-	// 首先执行 reread 命令读取新配置
-	err, output := RunShellWithOutput("supervisorctl", "reread")
-	if err != nil {
-		return fmt.Errorf("读取配置失败: %v\n%s", err, output)
-	}
-
-	// 然后执行 update 命令更新配置
-	err, output = RunShellWithOutput("supervisorctl", "update")
-	if err != nil {
-		return fmt.Errorf("更新配置失败: %v\n%s", err, output)
+	if err := p.backend.Reload(); err != nil {
+		return err
 	}
 
 	PrintSuccessf("服务已成功重载: %s", serviceName)
@@ -238,34 +143,35 @@ func (p *ServiceManager) Reload(serviceName string) error {
 
 // Start 启动服务
 func (p *ServiceManager) Start(serviceName string) error {
-	if err := p.CheckSupervisorInstalled(); err != nil {
-		return err
-	}
-
 	PrintInfof("正在启动服务: %s", serviceName)
 
-	err, output := RunShellWithOutput("supervisorctl", "start", serviceName)
-	if err != nil {
-		return fmt.Errorf("启动服务失败: %v\n%s", err, output)
+	if err := p.backend.Start(serviceName); err != nil {
+		return err
 	}
 
 	PrintSuccessf("服务已成功启动: %s", serviceName)
 	return nil
 }
 
-// Stop 停止服务
-func (p *ServiceManager) Stop(serviceName string) error {
-	if err := p.CheckSupervisorInstalled(); err != nil {
+// Restart 重启服务
+func (p *ServiceManager) Restart(serviceName string) error {
+	PrintInfof("正在重启服务: %s", serviceName)
+
+	if err := p.backend.Restart(serviceName); err != nil {
 		return err
 	}
 
+	PrintSuccessf("服务已成功重启: %s", serviceName)
+	return nil
+}
+
+// Stop 停止服务
+func (p *ServiceManager) Stop(serviceName string) error {
 	PrintInfof("正在停止服务: %s", serviceName)
 
-	cmd := exec.Command("supervisorctl", "stop", serviceName)
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		PrintErrorf("停止服务失败 %s: %v\n输出: %s", serviceName, err, string(output))
-		return fmt.Errorf("停止服务失败: %v", err)
+	if err := p.backend.Stop(serviceName); err != nil {
+		PrintErrorf("停止服务失败 %s: %v", serviceName, err)
+		return err
 	}
 
 	// 验证服务是否已停止
@@ -279,19 +185,21 @@ func (p *ServiceManager) Stop(serviceName string) error {
 	return nil
 }
 
-// AddBackgroundService 添加后台服务，返回配置文件路径
-// serviceName: 服务名称
-// command: 要执行的命令
-// args: 命令参数
-// env: 环境变量，格式如 ["KEY=VALUE", ...]
-func (p *ServiceManager) AddBackgroundService(serviceName string, command string, args []string, env []string) (string, error) {
-	PrintInfof("正在添加后台服务: %s", command)
+// AddBackgroundService 添加后台服务并启动，返回配置文件路径
+// spec 中未设置的字段使用默认值，工作目录默认为后端的数据目录
+func (p *ServiceManager) AddBackgroundService(spec ServiceSpec) (string, error) {
+	PrintInfof("正在添加后台服务: %s", spec.Name)
 
-	if p.HasServiceConf(serviceName) {
-		return "", fmt.Errorf("服务配置文件已存在: %s", serviceName)
+	if p.HasServiceConf(spec.Name) {
+		return "", fmt.Errorf("%w: %s", ErrServiceExists, spec.Name)
+	}
+
+	spec, err := p.prepareSpec(spec)
+	if err != nil {
+		return "", err
 	}
 
-	if err := p.CheckSupervisorInstalled(); err != nil {
+	if err := p.CheckAvailable(); err != nil {
 		return "", err
 	}
 
@@ -299,55 +207,137 @@ func (p *ServiceManager) AddBackgroundService(serviceName string, command string
 		return "", err
 	}
 
-	PrintInfof("正在创建服务配置文件: %s", serviceName)
+	PrintInfof("正在创建服务配置文件: %s", spec.Name)
 
-	configPath, err := p.createConfig(serviceName, command, args, env)
+	configPath, err := p.createConfig(spec)
 	if err != nil {
 		return "", err
 	}
 
-	if err := p.Reload(serviceName); err != nil {
-		return "", fmt.Errorf("重载supervisor配置失败: %v", err)
+	if err := p.Reload(spec.Name); err != nil {
+		return "", fmt.Errorf("重载%s配置失败: %w", p.BackendName(), err)
 	}
 
-	if err := p.Start(serviceName); err != nil {
+	if err := p.Start(spec.Name); err != nil {
 		return "", err
 	}
 
 	return configPath, nil
 }
 
-// StopBackgroundService 停止后台服务
+// SaveBackgroundService 创建或覆盖后台服务，并使服务以新配置运行
+// 服务已存在时会重写服务定义和配置文件并重启，适合每次部署都可能改变启动参数和环境变量的场景
+// 新配置通过临时文件替换旧配置，生成或加载失败时恢复旧配置，服务始终有可用的配置文件
+func (p *ServiceManager) SaveBackgroundService(spec ServiceSpec) (string, error) {
+	if !p.HasServiceConf(spec.Name) {
+		return p.AddBackgroundService(spec)
+	}
+
+	PrintInfof("正在更新后台服务: %s", spec.Name)
+
+	original, err := p.readServiceConf(spec.Name)
+	if err != nil {
+		return "", err
+	}
+	// 早期创建的服务没有服务定义，恢复时删除新写入的定义
+	current, err := p.GetServiceSpec(spec.Name)
+	if err != nil && !errors.Is(err, ErrServiceNotFound) {
+		return "", err
+	}
+
+	spec, err = p.prepareSpec(spec)
+	if err != nil {
+		return "", err
+	}
+	content, err := p.backend.RenderConfig(spec)
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.saveSpec(spec); err != nil {
+		return "", err
+	}
+	if err := p.writeServiceConf(spec.Name, content); err != nil {
+		p.restoreService(spec.Name, original, current)
+		return "", err
+	}
+
+	configPath := p.GetServiceFilePath(spec.Name)
+	if err := p.backend.Install(spec.Name, configPath); err != nil {
+		p.restoreService(spec.Name, original, current)
+		return "", err
+	}
+
+	if err := p.Reload(spec.Name); err != nil {
+		p.restoreService(spec.Name, original, current)
+		p.Reload(spec.Name)
+		return "", fmt.Errorf("重载%s配置失败，已恢复原配置: %w", p.BackendName(), err)
+	}
+
+	if err := p.Restart(spec.Name); err != nil {
+		return "", err
+	}
+
+	return configPath, nil
+}
+
+// restoreService 恢复更新前的配置文件和服务定义，spec 为 nil 表示原来没有服务定义
+func (p *ServiceManager) restoreService(serviceName, original string, spec *ServiceSpec) {
+	if spec != nil {
+		p.saveSpec(*spec)
+	} else {
+		os.Remove(p.specPath(serviceName))
+	}
+	p.writeServiceConf(serviceName, original)
+}
+
+// StopBackgroundService 停止并删除后台服务
 func (p *ServiceManager) StopBackgroundService(serviceName string, logChan chan<- string) error {
-	if err := p.Stop(serviceName); err != nil {
+	if !p.HasServiceConf(serviceName) {
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceName)
+	}
+
+	if err := p.Stop(serviceName); err != nil && !errors.Is(err, ErrServiceNotFound) {
 		return err
 	}
 
-	configPath := filepath.Join(p.ConfigDir, serviceName+".conf")
-	if err := os.Remove(configPath); err != nil {
+	if err := p.backend.Uninstall(serviceName); err != nil {
+		return err
+	}
+
+	if err := os.Remove(p.GetServiceFilePath(serviceName)); err != nil {
 		PrintErrorf("删除服务配置文件失败: %v", err)
 		return err
 	}
+	if err := os.Remove(p.specPath(serviceName)); err != nil && !os.IsNotExist(err) {
+		PrintErrorf("删除服务定义失败: %v", err)
+	}
 
 	if err := p.Reload(serviceName); err != nil {
-		return fmt.Errorf("重载supervisor配置失败: %v", err)
+		return fmt.Errorf("重载%s配置失败: %w", p.BackendName(), err)
 	}
 
 	return nil
 }
 
-// GetServiceList 获取所有服务列表
+// GetServiceList 获取所有服务列表，每行一个服务
 func (p *ServiceManager) GetServiceList() (string, error) {
 	PrintInfo("获取服务列表...")
 
-	if err := p.CheckSupervisorInstalled(); err != nil {
-		return "", err
-	}
-
-	err, output := RunShellWithOutput("supervisorctl", "status")
+	statuses, err := p.GetServiceStatuses()
 	if err != nil {
-		return "", fmt.Errorf("获取服务列表失败: %v", err)
+		return "", fmt.Errorf("获取服务列表失败: %w", err)
 	}
 
-	return string(output), nil
+	var lines []string
+	for _, status := range statuses {
+		line := fmt.Sprintf("%-32s %-9s", status.Name, status.Status)
+		if status.PID > 0 {
+			line += fmt.Sprintf(" pid %d, uptime %s", status.PID, status.Uptime)
+		} else if status.Description != "" {
+			line += " " + status.Description
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.Join(lines, "\n"), nil
 }
@@ -0,0 +1,90 @@
+package managers
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeServiceBackend 把配置渲染为命令行，reloadErr 不为空时重载失败
+type fakeServiceBackend struct {
+	ServiceBackend
+	reloadErr error
+	restarts  int
+}
+
+func (b *fakeServiceBackend) Name() string { return "fake" }
+
+func (b *fakeServiceBackend) CheckAvailable() error { return nil }
+
+func (b *fakeServiceBackend) ConfigFileName(serviceName string) string {
+	return serviceName + ".conf"
+}
+
+func (b *fakeServiceBackend) RenderConfig(spec ServiceSpec) (string, error) {
+	return commandLine(spec.Command, spec.Args) + "\n", nil
+}
+
+func (b *fakeServiceBackend) Install(serviceName, configPath string) error { return nil }
+
+func (b *fakeServiceBackend) Reload() error { return b.reloadErr }
+
+func (b *fakeServiceBackend) Start(serviceName string) error { return nil }
+
+func (b *fakeServiceBackend) Restart(serviceName string) error {
+	b.restarts++
+	return nil
+}
+
+func newTestServiceManager(t *testing.T) (*ServiceManager, *fakeServiceBackend) {
+	dir := t.TempDir()
+	backend := &fakeServiceBackend{}
+	return &ServiceManager{
+		RootFolder: dir,
+		ConfigDir:  filepath.Join(dir, "conf.d"),
+		backend:    backend,
+	}, backend
+}
+
+// TestSaveBackgroundServiceRestoresConfig 测试更新失败时保留原来的配置文件和服务定义
+func TestSaveBackgroundServiceRestoresConfig(t *testing.T) {
+	m, backend := newTestServiceManager(t)
+
+	if _, err := m.SaveBackgroundService(ServiceSpec{Name: "web", Command: "sh", Args: []string{"-c", "echo v1"}}); err != nil {
+		t.Fatal(err)
+	}
+	original, _ := os.ReadFile(m.GetServiceFilePath("web"))
+
+	// 服务定义无效时不修改任何文件
+	if _, err := m.SaveBackgroundService(ServiceSpec{Name: "web", Command: "servon-missing-command"}); err == nil {
+		t.Error("Expected invalid command to be rejected")
+	}
+
+	backend.reloadErr = errors.New("reload failed")
+	if _, err := m.SaveBackgroundService(ServiceSpec{Name: "web", Command: "sh", Args: []string{"-c", "echo v2"}}); err == nil {
+		t.Error("Expected reload failure to be returned")
+	}
+
+	content, err := os.ReadFile(m.GetServiceFilePath("web"))
+	if err != nil || string(content) != string(original) {
+		t.Errorf("Expected original config to be restored, got %q, %v", content, err)
+	}
+	spec, err := m.GetServiceSpec("web")
+	if err != nil || strings.Join(spec.Args, " ") != "-c echo v1" {
+		t.Errorf("Expected original spec to be restored, got %+v, %v", spec, err)
+	}
+	if _, err := os.Stat(m.GetServiceFilePath("web") + ".tmp"); !os.IsNotExist(err) {
+		t.Error("Expected no temporary config to be left")
+	}
+
+	backend.reloadErr = nil
+	if _, err := m.SaveBackgroundService(ServiceSpec{Name: "web", Command: "sh", Args: []string{"-c", "echo v3"}}); err != nil {
+		t.Fatal(err)
+	}
+	content, _ = os.ReadFile(m.GetServiceFilePath("web"))
+	if !strings.Contains(string(content), "echo v3") || backend.restarts != 1 {
+		t.Errorf("Expected updated config and one restart, got %q and %d restarts", content, backend.restarts)
+	}
+}
@@ -0,0 +1,198 @@
+package managers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// 服务退出后的重启策略
+const (
+	RestartAlways    = "always"     // 总是重启
+	RestartOnFailure = "on-failure" // 非正常退出时重启
+	RestartNever     = "never"      // 不重启
+)
+
+var (
+	serviceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+	userPattern        = regexp.MustCompile(`^[a-z_][a-z0-9_-]*\$?$`)
+	logSizePattern     = regexp.MustCompile(`^[0-9]+(KB|MB|GB)?$`)
+)
+
+// stopSignals 支持的停止信号，不带 SIG 前缀
+var stopSignals = map[string]bool{
+	"TERM": true, "INT": true, "QUIT": true, "HUP": true, "KILL": true, "USR1": true, "USR2": true,
+}
+
+// ServiceSpec 后台服务的定义，由后端转换为各自的配置文件
+// 服务定义保存在 RootFolder/specs 中，修改配置时修改的是服务定义，配置文件总是重新生成
+type ServiceSpec struct {
+	Name        string            `json:"name"`
+	Command     string            `json:"command"`                // 要执行的命令，不在 PATH 中时需要使用绝对路径
+	Args        []string          `json:"args,omitempty"`         // 命令参数，每个参数会被单独转义，不需要自行加引号
+	WorkingDir  string            `json:"working_dir,omitempty"`  // 工作目录，默认为后端的数据目录
+	User        string            `json:"user,omitempty"`         // 运行服务的用户，默认为 root
+	Env         map[string]string `json:"env,omitempty"`          // 环境变量
+	StopSignal  string            `json:"stop_signal,omitempty"`  // 停止信号，如 TERM、INT，默认为 TERM
+	StopTimeout int               `json:"stop_timeout,omitempty"` // 发送停止信号后等待退出的秒数，超时后强制结束，默认为 10
+	Restart     string            `json:"restart,omitempty"`      // 重启策略：always、on-failure、never，默认为 always
+	NumProcs    int               `json:"numprocs,omitempty"`     // 进程数量，默认为 1
+	LogMaxSize  string            `json:"log_max_size,omitempty"` // 日志文件达到该大小后轮转，如 50MB，默认为 50MB
+	Sandbox     bool              `json:"sandbox,omitempty"`      // 开启 systemd 的沙箱选项（系统目录只读、独立的 /tmp、禁止提权），supervisor 后端不支持
+}
+
+// AddEnv 添加 KEY=VALUE 形式的环境变量，后添加的覆盖先添加的
+func (s *ServiceSpec) AddEnv(env ...string) {
+	if s.Env == nil {
+		s.Env = make(map[string]string, len(env))
+	}
+	for _, e := range env {
+		key, value, _ := strings.Cut(e, "=")
+		s.Env[key] = value
+	}
+}
+
+// withDefaults 返回填充了默认值的服务定义，workingDir 为默认的工作目录
+func (s ServiceSpec) withDefaults(workingDir string) ServiceSpec {
+	if s.WorkingDir == "" {
+		s.WorkingDir = workingDir
+	}
+	if s.StopSignal == "" {
+		s.StopSignal = "TERM"
+	}
+	s.StopSignal = strings.TrimPrefix(strings.ToUpper(s.StopSignal), "SIG")
+	if s.StopTimeout == 0 {
+		s.StopTimeout = 10
+	}
+	if s.Restart == "" {
+		s.Restart = RestartAlways
+	}
+	if s.NumProcs == 0 {
+		s.NumProcs = 1
+	}
+	if s.LogMaxSize == "" {
+		s.LogMaxSize = "50MB"
+	}
+	return s
+}
+
+// validate 检查服务定义，所有值都会写入配置文件，不允许出现换行
+func (s ServiceSpec) validate() error {
+	invalid := func(format string, args ...interface{}) error {
+		return fmt.Errorf("%w: %s", ErrInvalidServiceConfig, fmt.Sprintf(format, args...))
+	}
+
+	if !serviceNamePattern.MatchString(s.Name) {
+		return invalid("服务名称只能包含字母、数字、下划线、点和短横线")
+	}
+	if strings.TrimSpace(s.Command) == "" {
+		return invalid("命令不能为空")
+	}
+	if s.WorkingDir != "" && !filepath.IsAbs(s.WorkingDir) {
+		return invalid("工作目录必须是绝对路径: %s", s.WorkingDir)
+	}
+	if s.User != "" && !userPattern.MatchString(s.User) {
+		return invalid("无效的用户名: %s", s.User)
+	}
+	for key := range s.Env {
+		if !envKeyPattern.MatchString(key) {
+			return invalid("无效的环境变量名: %s", key)
+		}
+	}
+	if s.StopSignal != "" && !stopSignals[strings.TrimPrefix(strings.ToUpper(s.StopSignal), "SIG")] {
+		return invalid("不支持的停止信号: %s", s.StopSignal)
+	}
+	if s.StopTimeout < 0 {
+		return invalid("停止超时不能为负数")
+	}
+	switch s.Restart {
+	case "", RestartAlways, RestartOnFailure, RestartNever:
+	default:
+		return invalid("无效的重启策略: %s，可选值: always、on-failure、never", s.Restart)
+	}
+	if s.NumProcs < 0 || s.NumProcs > 100 {
+		return invalid("进程数量必须在 1 到 100 之间")
+	}
+	if s.LogMaxSize != "" && !logSizePattern.MatchString(s.LogMaxSize) {
+		return invalid("无效的日志大小: %s，格式如 50MB", s.LogMaxSize)
+	}
+
+	values := append([]string{s.Command, s.WorkingDir}, s.Args...)
+	for _, value := range s.Env {
+		values = append(values, value)
+	}
+	for _, value := range values {
+		if strings.ContainsAny(value, "\r\n") {
+			return invalid("命令、参数和环境变量中不能包含换行")
+		}
+	}
+	return nil
+}
+
+// GetServiceSpec 获取 Servon 创建的服务的定义
+func (p *ServiceManager) GetServiceSpec(serviceName string) (*ServiceSpec, error) {
+	data, err := os.ReadFile(p.specPath(serviceName))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("%w: %s 没有服务定义", ErrServiceNotFound, serviceName)
+		}
+		return nil, fmt.Errorf("读取服务定义失败: %v", err)
+	}
+
+	var spec ServiceSpec
+	if err := json.Unmarshal(data, &spec); err != nil {
+		return nil, fmt.Errorf("解析服务定义失败: %v", err)
+	}
+	return &spec, nil
+}
+
+// saveSpec 保存服务定义，先写临时文件再重命名
+func (p *ServiceManager) saveSpec(spec ServiceSpec) error {
+	path := p.specPath(spec.Name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建服务定义目录失败: %v", err)
+	}
+
+	data, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化服务定义失败: %v", err)
+	}
+
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("保存服务定义失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("保存服务定义失败: %v", err)
+	}
+	return nil
+}
+
+// specPath 服务定义文件的路径
+func (p *ServiceManager) specPath(serviceName string) string {
+	return filepath.Join(p.RootFolder, "specs", serviceName+".json")
+}
+
+// quoteArg 参数中有空白或引号时用双引号包裹，supervisor 和 systemd 都按这种方式拆分命令行
+func quoteArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	arg = strings.ReplaceAll(arg, `\`, `\\`)
+	arg = strings.ReplaceAll(arg, `"`, `\"`)
+	return `"` + arg + `"`
+}
+
+// commandLine 把命令和参数转换为转义后的命令行
+func commandLine(command string, args []string) string {
+	parts := make([]string, 0, len(args)+1)
+	parts = append(parts, quoteArg(command))
+	for _, arg := range args {
+		parts = append(parts, quoteArg(arg))
+	}
+	return strings.Join(parts, " ")
+}
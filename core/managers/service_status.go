@@ -0,0 +1,127 @@
+package managers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+var (
+	// ErrServiceNotFound 后端中没有该服务
+	ErrServiceNotFound = errors.New("服务不存在")
+	// ErrServiceExists 服务配置文件已存在
+	ErrServiceExists = errors.New("服务已存在")
+	// ErrServiceBackendUnavailable 服务后端不可用，如 supervisor 未安装或 supervisord 未运行
+	ErrServiceBackendUnavailable = errors.New("服务后端不可用")
+)
+
+// maxLogReadSize 读取日志时最多从文件末尾读取的字节数
+const maxLogReadSize = 1024 * 1024
+
+// ServiceStatus 服务状态，不同后端的状态统一转换为 supervisor 的进程状态
+type ServiceStatus struct {
+	Name          string `json:"name"`
+	Status        string `json:"status"`                // 进程状态，如 RUNNING、STOPPED、FATAL
+	PID           int    `json:"pid,omitempty"`         // 运行中的进程ID
+	Uptime        string `json:"uptime,omitempty"`      // 运行时长，如 1 day, 0:01:02
+	UptimeSeconds int    `json:"uptime_seconds"`        // 运行时长（秒）
+	ExitStatus    *int   `json:"exit_status,omitempty"` // 最近一次退出的退出码
+	Description   string `json:"description,omitempty"` // 后端输出的说明，如停止的时间或失败原因
+	Managed       bool   `json:"managed"`               // 是否为 Servon 创建的服务
+}
+
+// GetServiceStatuses 获取后端中所有服务的状态
+func (p *ServiceManager) GetServiceStatuses() ([]ServiceStatus, error) {
+	statuses, err := p.backend.Statuses()
+	if err != nil {
+		return nil, err
+	}
+	for i := range statuses {
+		statuses[i].Managed = p.HasServiceConf(statuses[i].Name)
+	}
+	return statuses, nil
+}
+
+// GetServiceStatus 获取单个服务的状态
+func (p *ServiceManager) GetServiceStatus(serviceName string) (*ServiceStatus, error) {
+	status, err := p.backend.Status(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	status.Managed = p.HasServiceConf(serviceName)
+	return status, nil
+}
+
+// GetServiceLogs 获取服务最后 lines 行日志，stream 为 stdout、stderr 或空（两者都返回）
+func (p *ServiceManager) GetServiceLogs(serviceName string, lines int, stream string) (string, error) {
+	if lines <= 0 {
+		lines = 100 // 默认获取100行
+	}
+	if stream != "" && stream != "stdout" && stream != "stderr" {
+		return "", fmt.Errorf("无效的日志类型: %s，可选值: stdout、stderr", stream)
+	}
+
+	if _, err := p.backend.Status(serviceName); err != nil {
+		return "", err
+	}
+	return p.backend.Logs(serviceName, lines, stream)
+}
+
+// GetServiceLogFiles 获取服务的日志文件，日志不在文件中（如 systemd 的 journal）时返回空
+func (p *ServiceManager) GetServiceLogFiles(serviceName string) map[string]string {
+	return p.backend.LogFiles(serviceName)
+}
+
+// formatUptime 把秒转换为 1 day, 0:01:02 形式的运行时长
+func formatUptime(seconds int) string {
+	days := seconds / 86400
+	clock := fmt.Sprintf("%d:%02d:%02d", seconds%86400/3600, seconds%3600/60, seconds%60)
+	switch days {
+	case 0:
+		return clock
+	case 1:
+		return "1 day, " + clock
+	default:
+		return fmt.Sprintf("%d days, %s", days, clock)
+	}
+}
+
+// readTail 读取文件最后 limit 字节
+func readTail(path string, limit int64) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	if info.Size() > limit {
+		if _, err := f.Seek(-limit, io.SeekEnd); err != nil {
+			return "", err
+		}
+	}
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// lastLines 返回内容的最后 n 行
+func lastLines(content string, n int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	result := strings.Join(lines, "\n")
+	if result == "" {
+		return ""
+	}
+	return result + "\n"
+}
@@ -0,0 +1,431 @@
+package managers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"servon/components/supervisor_util"
+	"servon/core/templates"
+)
+
+// SupervisorConfig supervisor 配置模板的数据，值都已转义
+type SupervisorConfig struct {
+	ServiceName  string
+	Command      string
+	WorkingDir   string
+	User         string
+	Environment  string
+	AutoRestart  string
+	StopSignal   string
+	StopWaitSecs int
+	NumProcs     int
+	LogPrefix    string // 日志文件路径去掉 .out.log 和 .err.log 后的部分
+	LogMaxSize   string
+}
+
+// supervisorAutoRestart 重启策略对应的 autorestart 值
+var supervisorAutoRestart = map[string]string{
+	RestartAlways:    "true",
+	RestartOnFailure: "unexpected",
+	RestartNever:     "false",
+}
+
+// supervisorBackend 通过 supervisord 的 XML-RPC 接口管理后台服务
+type supervisorBackend struct {
+	rootFolder string
+	client     *supervisor_util.Client
+}
+
+func (b *supervisorBackend) Name() string {
+	return ServiceBackendSupervisor
+}
+
+// CheckAvailable 检查 supervisor 是否安装以及 supervisord 是否运行
+func (b *supervisorBackend) CheckAvailable() error {
+	if _, err := exec.LookPath("supervisord"); err != nil {
+		PrintErrorMessage("Supervisor未安装，请先安装Supervisor")
+		PrintInfo("Ubuntu/Debian: sudo apt-get install supervisor")
+		PrintInfo("CentOS/RHEL: sudo yum install supervisor")
+		return fmt.Errorf("%w: supervisor未安装", ErrServiceBackendUnavailable)
+	}
+
+	state, err := b.client.GetState()
+	if err != nil {
+		PrintErrorMessage("Supervisor守护进程未运行")
+		PrintInfo("请使用以下命令启动 Supervisor: supervisord -c /etc/supervisor/supervisord.conf")
+		return supervisorError(err, "")
+	}
+	if state != "RUNNING" {
+		return fmt.Errorf("%w: supervisord 状态为 %s", ErrServiceBackendUnavailable, state)
+	}
+	return nil
+}
+
+func (b *supervisorBackend) ConfigFileName(serviceName string) string {
+	return serviceName + ".conf"
+}
+
+func (b *supervisorBackend) ConfigSection(serviceName string) string {
+	return "program:" + serviceName
+}
+
+// RenderConfig 根据模板生成 supervisor 配置
+// 多进程的服务中每个进程名为 <name>_00、<name>_01……，日志文件也按进程分开
+func (b *supervisorBackend) RenderConfig(spec ServiceSpec) (string, error) {
+	tmplContent, err := templates.GetSupervisorConfigTemplate()
+	if err != nil {
+		return "", fmt.Errorf("获取模板内容失败: %v", err)
+	}
+
+	tmpl, err := template.New("supervisor").Parse(tmplContent)
+	if err != nil {
+		return "", fmt.Errorf("解析supervisor模板失败: %v", err)
+	}
+
+	user := spec.User
+	if user == "" {
+		user = "root"
+	}
+
+	logPrefix := supervisorEscape(filepath.Join(b.rootFolder, "logs", spec.Name))
+	if spec.NumProcs > 1 {
+		logPrefix += "_%(process_num)02d"
+	}
+
+	config := SupervisorConfig{
+		ServiceName:  spec.Name,
+		Command:      supervisorEscape(commandLine(spec.Command, spec.Args)),
+		WorkingDir:   supervisorEscape(spec.WorkingDir),
+		User:         user,
+		Environment:  supervisorEnvironment(spec.Env),
+		AutoRestart:  supervisorAutoRestart[spec.Restart],
+		StopSignal:   spec.StopSignal,
+		StopWaitSecs: spec.StopTimeout,
+		NumProcs:     spec.NumProcs,
+		LogPrefix:    logPrefix,
+		LogMaxSize:   spec.LogMaxSize,
+	}
+
+	var content strings.Builder
+	if err := tmpl.Execute(&content, config); err != nil {
+		return "", fmt.Errorf("生成配置文件失败: %v", err)
+	}
+	return content.String(), nil
+}
+
+// Install 在 supervisor 的配置目录中创建指向配置文件的软链接
+func (b *supervisorBackend) Install(serviceName, configPath string) error {
+	if err := os.MkdirAll(filepath.Join(b.rootFolder, "logs"), 0755); err != nil {
+		return fmt.Errorf("创建Supervisor日志目录失败: %v", err)
+	}
+
+	supervisorConfigDir, err := getSupervisorConfigDir()
+	if err != nil {
+		return fmt.Errorf("获取supervisor配置目录失败: %v", err)
+	}
+
+	systemConfigPath := filepath.Join(supervisorConfigDir, b.ConfigFileName(serviceName))
+	// 如果已存在，先删除
+	if _, err := os.Lstat(systemConfigPath); err == nil {
+		if err := os.Remove(systemConfigPath); err != nil {
+			return fmt.Errorf("删除已存在的配置文件软链接失败: %v", err)
+		}
+	}
+
+	if err := os.Symlink(configPath, systemConfigPath); err != nil {
+		return fmt.Errorf("创建配置文件软链接失败: %v", err)
+	}
+
+	PrintInfof("已创建配置文件软链接: %s -> %s", systemConfigPath, configPath)
+	return nil
+}
+
+// Uninstall 删除 supervisor 配置目录中的软链接
+func (b *supervisorBackend) Uninstall(serviceName string) error {
+	supervisorConfigDir, err := getSupervisorConfigDir()
+	if err != nil {
+		return nil
+	}
+
+	systemConfigPath := filepath.Join(supervisorConfigDir, b.ConfigFileName(serviceName))
+	if err := os.Remove(systemConfigPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除配置文件软链接失败: %v", err)
+	}
+	return nil
+}
+
+// Reload 和 supervisorctl update 相同，重新读取配置并应用新增、修改和删除的程序
+func (b *supervisorBackend) Reload() error {
+	if err := b.client.Update(); err != nil {
+		return fmt.Errorf("更新配置失败: %w", supervisorError(err, ""))
+	}
+	return nil
+}
+
+func (b *supervisorBackend) Start(serviceName string) error {
+	err := b.forProcesses(serviceName, func(name string) error {
+		return b.client.StartProcess(name, true)
+	})
+	if err != nil && !supervisor_util.IsFault(err, supervisor_util.FaultAlreadyStarted) {
+		return fmt.Errorf("启动服务失败: %w", supervisorError(err, serviceName))
+	}
+	return nil
+}
+
+func (b *supervisorBackend) Stop(serviceName string) error {
+	err := b.forProcesses(serviceName, func(name string) error {
+		return b.client.StopProcess(name, true)
+	})
+	if err != nil && !supervisor_util.IsFault(err, supervisor_util.FaultNotRunning) {
+		return fmt.Errorf("停止服务失败: %w", supervisorError(err, serviceName))
+	}
+	return nil
+}
+
+// Restart 先停止再启动，未运行的服务直接启动
+func (b *supervisorBackend) Restart(serviceName string) error {
+	if err := b.Stop(serviceName); err != nil {
+		return err
+	}
+	err := b.forProcesses(serviceName, func(name string) error {
+		return b.client.StartProcess(name, true)
+	})
+	if err != nil {
+		return fmt.Errorf("重启服务失败: %w", supervisorError(err, serviceName))
+	}
+	return nil
+}
+
+// forProcesses 对服务执行操作，多进程的服务没有同名的进程，改为对组中的所有进程（name:*）执行
+func (b *supervisorBackend) forProcesses(serviceName string, action func(name string) error) error {
+	err := action(serviceName)
+	if supervisor_util.IsFault(err, supervisor_util.FaultBadName) {
+		return action(serviceName + ":*")
+	}
+	return err
+}
+
+// Statuses 获取 supervisord 中所有进程的状态
+func (b *supervisorBackend) Statuses() ([]ServiceStatus, error) {
+	infos, err := b.client.GetAllProcessInfo()
+	if err != nil {
+		return nil, fmt.Errorf("获取服务列表失败: %w", supervisorError(err, ""))
+	}
+
+	statuses := make([]ServiceStatus, 0, len(infos))
+	for _, info := range infos {
+		statuses = append(statuses, supervisorStatus(info))
+	}
+	return statuses, nil
+}
+
+// Status 获取服务的状态，多进程的服务汇总组中所有进程的状态
+func (b *supervisorBackend) Status(serviceName string) (*ServiceStatus, error) {
+	info, err := b.client.GetProcessInfo(serviceName)
+	if err == nil {
+		status := supervisorStatus(*info)
+		return &status, nil
+	}
+	if !supervisor_util.IsFault(err, supervisor_util.FaultBadName) {
+		return nil, supervisorError(err, serviceName)
+	}
+
+	infos, err := b.client.GetAllProcessInfo()
+	if err != nil {
+		return nil, supervisorError(err, serviceName)
+	}
+	var group []supervisor_util.ProcessInfo
+	for _, info := range infos {
+		if info.Group == serviceName {
+			group = append(group, info)
+		}
+	}
+	if len(group) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceName)
+	}
+
+	status := groupStatus(serviceName, group)
+	return &status, nil
+}
+
+// Logs Servon 创建的服务直接读取日志文件，其他服务通过 XML-RPC 读取
+func (b *supervisorBackend) Logs(serviceName string, lines int, stream string) (string, error) {
+	streams := []string{"stdout", "stderr"}
+	if stream != "" {
+		streams = []string{stream}
+	}
+
+	var sections []string
+	for _, s := range streams {
+		content, err := b.readLog(serviceName, s)
+		if err != nil {
+			return "", err
+		}
+		content = lastLines(content, lines)
+		if stream == "" {
+			if content == "" {
+				continue
+			}
+			content = fmt.Sprintf("==> %s <==\n%s", s, content)
+		}
+		sections = append(sections, content)
+	}
+	return strings.Join(sections, "\n"), nil
+}
+
+// LogFiles 模板中配置的日志文件
+func (b *supervisorBackend) LogFiles(serviceName string) map[string]string {
+	logDir := filepath.Join(b.rootFolder, "logs")
+	return map[string]string{
+		"stdout": filepath.Join(logDir, serviceName+".out.log"),
+		"stderr": filepath.Join(logDir, serviceName+".err.log"),
+	}
+}
+
+// readLog 读取服务某个输出流的日志末尾，多进程的服务依次读取每个进程的日志文件
+func (b *supervisorBackend) readLog(serviceName, stream string) (string, error) {
+	path := b.LogFiles(serviceName)[stream]
+	content, err := readTail(path, maxLogReadSize)
+	if err == nil || !os.IsNotExist(err) {
+		return content, err
+	}
+
+	suffix := strings.TrimPrefix(filepath.Base(path), serviceName)
+	processLogs, _ := filepath.Glob(filepath.Join(filepath.Dir(path), serviceName+"_[0-9][0-9]"+suffix))
+	if len(processLogs) > 0 {
+		var sections []string
+		for _, processLog := range processLogs {
+			content, err := readTail(processLog, maxLogReadSize)
+			if err != nil {
+				return "", err
+			}
+			sections = append(sections, fmt.Sprintf("--- %s ---\n%s", filepath.Base(processLog), content))
+		}
+		return strings.Join(sections, "\n"), nil
+	}
+
+	tail := b.client.TailProcessStdoutLog
+	if stream == "stderr" {
+		tail = b.client.TailProcessStderrLog
+	}
+	content, _, _, err = tail(serviceName, 0, maxLogReadSize)
+	if err != nil && !supervisor_util.IsFault(err, supervisor_util.FaultNoFile) {
+		return "", fmt.Errorf("读取服务日志失败: %w", supervisorError(err, serviceName))
+	}
+	return content, nil
+}
+
+// getSupervisorConfigDir 获取supervisor配置目录
+func getSupervisorConfigDir() (string, error) {
+	// 常见的supervisor配置目录
+	configDirs := []string{
+		"/etc/supervisor/conf.d",
+		"/etc/supervisord.d",
+	}
+
+	for _, dir := range configDirs {
+		if _, err := os.Stat(dir); err == nil {
+			return dir, nil
+		}
+	}
+
+	return "", fmt.Errorf("未找到supervisor配置目录")
+}
+
+// supervisorError 把 XML-RPC 的错误转换为 ErrServiceNotFound 或 ErrServiceBackendUnavailable
+func supervisorError(err error, serviceName string) error {
+	var fault *supervisor_util.Fault
+	switch {
+	case !errors.As(err, &fault):
+		// 不是 supervisord 返回的错误，说明无法连接
+		return fmt.Errorf("%w: %v", ErrServiceBackendUnavailable, err)
+	case fault.Code == supervisor_util.FaultBadName:
+		return fmt.Errorf("%w: %s", ErrServiceNotFound, serviceName)
+	case fault.Code == supervisor_util.FaultShutdownState:
+		return fmt.Errorf("%w: %v", ErrServiceBackendUnavailable, err)
+	}
+	return err
+}
+
+// supervisorStatus 把进程信息转换为服务状态
+func supervisorStatus(info supervisor_util.ProcessInfo) ServiceStatus {
+	status := ServiceStatus{
+		Name:   info.FullName(),
+		Status: info.StateName,
+	}
+
+	if info.State == supervisor_util.StateRunning {
+		status.PID = info.PID
+		status.UptimeSeconds = int(info.Uptime())
+		status.Uptime = formatUptime(status.UptimeSeconds)
+		return status
+	}
+
+	status.Description = info.Description
+	if info.SpawnErr != "" {
+		status.Description = info.SpawnErr
+	}
+	// 进程退出过才有退出码
+	if info.Stop > 0 && info.State != supervisor_util.StateStarting {
+		exitStatus := info.ExitStatus
+		status.ExitStatus = &exitStatus
+	}
+	return status
+}
+
+// groupStatus 汇总多进程服务的状态，全部运行时为 RUNNING，否则取第一个未运行的进程的状态
+func groupStatus(serviceName string, infos []supervisor_util.ProcessInfo) ServiceStatus {
+	running := 0
+	var status ServiceStatus
+	for _, info := range infos {
+		if info.State == supervisor_util.StateRunning {
+			running++
+			if status.Name == "" {
+				status = supervisorStatus(info)
+			}
+		}
+	}
+	for _, info := range infos {
+		if info.State != supervisor_util.StateRunning {
+			status = supervisorStatus(info)
+			break
+		}
+	}
+
+	status.Name = serviceName
+	summary := fmt.Sprintf("%d/%d 个进程运行中", running, len(infos))
+	if status.Description == "" {
+		status.Description = summary
+	} else {
+		status.Description = summary + ", " + status.Description
+	}
+	return status
+}
+
+// supervisorEscape 转义 supervisor 配置中的 %，避免被当作 %(name)s 展开
+func supervisorEscape(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// supervisorEnvironment 生成 environment 配置，值用双引号包裹，可以包含逗号、空格和引号
+func supervisorEnvironment(env map[string]string) string {
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		value := strings.ReplaceAll(env[key], `\`, `\\`)
+		value = strings.ReplaceAll(value, `"`, `\"`)
+		pairs = append(pairs, key+`="`+supervisorEscape(value)+`"`)
+	}
+	return strings.Join(pairs, ",")
+}
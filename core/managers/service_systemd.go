@@ -0,0 +1,345 @@
+package managers
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"servon/core/templates"
+)
+
+const (
+	// systemdUnitPrefix Servon 创建的 unit 的名称前缀，避免和系统中的 unit 重名
+	systemdUnitPrefix = "servon-"
+	// systemdUnitDir unit 文件的软链接所在目录
+	systemdUnitDir = "/etc/systemd/system"
+)
+
+// systemdShowProperties 获取服务状态时读取的 unit 属性
+var systemdShowProperties = []string{
+	"Id", "LoadState", "ActiveState", "SubState", "Result", "MainPID",
+	"ActiveEnterTimestampMonotonic", "ExecMainStatus", "ExecMainExitTimestampMonotonic",
+}
+
+// SystemdServiceConfig systemd unit 模板的数据
+type SystemdServiceConfig struct {
+	ServiceName string
+	ExecStart   string
+	WorkingDir  string
+	User        string
+	Environment []string // 已转义的 Environment 值
+	Restart     string
+	StopSignal  string
+	StopTimeout int
+	Sandbox     bool
+}
+
+// systemdRestart 重启策略对应的 Restart 值
+var systemdRestart = map[string]string{
+	RestartAlways:    "always",
+	RestartOnFailure: "on-failure",
+	RestartNever:     "no",
+}
+
+// systemdBackend 通过 systemctl 管理后台服务，服务对应名为 servon-<name>.service 的 unit
+// 日志由 journald 保存，通过 journalctl 读取
+type systemdBackend struct{}
+
+func (b *systemdBackend) Name() string {
+	return ServiceBackendSystemd
+}
+
+func (b *systemdBackend) CheckAvailable() error {
+	if _, err := exec.LookPath("systemctl"); err != nil {
+		return fmt.Errorf("%w: 未找到 systemctl", ErrServiceBackendUnavailable)
+	}
+	if !isSystemdInit() {
+		return fmt.Errorf("%w: 系统未使用 systemd 启动", ErrServiceBackendUnavailable)
+	}
+	return nil
+}
+
+func (b *systemdBackend) ConfigFileName(serviceName string) string {
+	return systemdUnitName(serviceName)
+}
+
+func (b *systemdBackend) ConfigSection(serviceName string) string {
+	return "Service"
+}
+
+// RenderConfig 根据模板生成 unit 文件
+// 日志由 journald 统一轮转，LogMaxSize 不起作用；一个 unit 只有一个主进程，不支持 NumProcs
+func (b *systemdBackend) RenderConfig(spec ServiceSpec) (string, error) {
+	if spec.NumProcs > 1 {
+		return "", fmt.Errorf("%w: systemd 后端不支持多进程（numprocs）", ErrInvalidServiceConfig)
+	}
+
+	tmplContent, err := templates.GetSystemdServiceTemplate()
+	if err != nil {
+		return "", fmt.Errorf("获取模板内容失败: %v", err)
+	}
+
+	tmpl, err := template.New("systemd").Parse(tmplContent)
+	if err != nil {
+		return "", fmt.Errorf("解析systemd模板失败: %v", err)
+	}
+
+	config := SystemdServiceConfig{
+		ServiceName: spec.Name,
+		// ExecStart 中的 $ 会被当作环境变量展开
+		ExecStart:   strings.ReplaceAll(systemdEscape(commandLine(spec.Command, spec.Args)), "$", "$$"),
+		WorkingDir:  systemdEscape(spec.WorkingDir),
+		User:        spec.User,
+		Restart:     systemdRestart[spec.Restart],
+		StopSignal:  spec.StopSignal,
+		StopTimeout: spec.StopTimeout,
+		Sandbox:     spec.Sandbox,
+	}
+
+	keys := make([]string, 0, len(spec.Env))
+	for key := range spec.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		config.Environment = append(config.Environment, systemdQuote(key+"="+spec.Env[key]))
+	}
+
+	var content strings.Builder
+	if err := tmpl.Execute(&content, config); err != nil {
+		return "", fmt.Errorf("生成配置文件失败: %v", err)
+	}
+	return content.String(), nil
+}
+
+// Install 把 unit 文件链接到 /etc/systemd/system 并设置开机启动
+func (b *systemdBackend) Install(serviceName, configPath string) error {
+	unitPath := filepath.Join(systemdUnitDir, systemdUnitName(serviceName))
+	if _, err := os.Lstat(unitPath); err == nil {
+		if err := os.Remove(unitPath); err != nil {
+			return fmt.Errorf("删除已存在的 unit 文件软链接失败: %v", err)
+		}
+	}
+	if err := os.Symlink(configPath, unitPath); err != nil {
+		return fmt.Errorf("创建 unit 文件软链接失败: %v", err)
+	}
+	PrintInfof("已创建 unit 文件软链接: %s -> %s", unitPath, configPath)
+
+	if err := b.Reload(); err != nil {
+		return err
+	}
+	if output, err := systemctl("enable", systemdUnitName(serviceName)); err != nil {
+		return fmt.Errorf("设置开机启动失败: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// Uninstall 取消开机启动并删除 unit 文件软链接
+func (b *systemdBackend) Uninstall(serviceName string) error {
+	if output, err := systemctl("disable", systemdUnitName(serviceName)); err != nil && !errors.Is(err, ErrServiceNotFound) {
+		return fmt.Errorf("取消开机启动失败: %w\n%s", err, output)
+	}
+
+	unitPath := filepath.Join(systemdUnitDir, systemdUnitName(serviceName))
+	if err := os.Remove(unitPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除 unit 文件软链接失败: %v", err)
+	}
+	return nil
+}
+
+// Reload 执行 daemon-reload 让 systemd 加载新的 unit 文件
+func (b *systemdBackend) Reload() error {
+	if output, err := systemctl("daemon-reload"); err != nil {
+		return fmt.Errorf("重新加载 unit 文件失败: %w\n%s", err, output)
+	}
+	return nil
+}
+
+func (b *systemdBackend) Start(serviceName string) error {
+	if output, err := systemctl("start", systemdUnitName(serviceName)); err != nil {
+		return fmt.Errorf("启动服务失败: %w\n%s", err, output)
+	}
+	return nil
+}
+
+func (b *systemdBackend) Stop(serviceName string) error {
+	if output, err := systemctl("stop", systemdUnitName(serviceName)); err != nil {
+		return fmt.Errorf("停止服务失败: %w\n%s", err, output)
+	}
+	return nil
+}
+
+func (b *systemdBackend) Restart(serviceName string) error {
+	if output, err := systemctl("restart", systemdUnitName(serviceName)); err != nil {
+		return fmt.Errorf("重启服务失败: %w\n%s", err, output)
+	}
+	return nil
+}
+
+// Statuses 获取所有 servon- 开头的 unit 的状态
+func (b *systemdBackend) Statuses() ([]ServiceStatus, error) {
+	output, err := systemctl("list-unit-files", "--no-legend", "--plain", systemdUnitPrefix+"*.service")
+	if err != nil && output != "" {
+		return nil, fmt.Errorf("获取服务列表失败: %w\n%s", err, output)
+	}
+
+	var units []string
+	for _, line := range strings.Split(output, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 {
+			units = append(units, fields[0])
+		}
+	}
+	if len(units) == 0 {
+		return nil, nil
+	}
+
+	return showSystemdUnits(units)
+}
+
+func (b *systemdBackend) Status(serviceName string) (*ServiceStatus, error) {
+	statuses, err := showSystemdUnits([]string{systemdUnitName(serviceName)})
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrServiceNotFound, serviceName)
+	}
+	return &statuses[0], nil
+}
+
+// Logs 通过 journalctl 读取日志，journal 中不区分标准输出和标准错误，stream 会被忽略
+func (b *systemdBackend) Logs(serviceName string, lines int, stream string) (string, error) {
+	output, err := exec.Command("journalctl", "-u", systemdUnitName(serviceName),
+		"-n", strconv.Itoa(lines), "--no-pager", "-o", "short-iso").CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("读取服务日志失败: %v\n%s", err, output)
+	}
+	return string(output), nil
+}
+
+func (b *systemdBackend) LogFiles(serviceName string) map[string]string {
+	return nil
+}
+
+// systemdUnitName 服务对应的 unit 名称
+func systemdUnitName(serviceName string) string {
+	return systemdUnitPrefix + serviceName + ".service"
+}
+
+// systemctl 执行 systemctl 命令并返回输出，unit 不存在时返回 ErrServiceNotFound
+func systemctl(args ...string) (string, error) {
+	output, err := exec.Command("systemctl", args...).CombinedOutput()
+	text := strings.TrimSpace(string(output))
+
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", fmt.Errorf("%w: 未找到 systemctl", ErrServiceBackendUnavailable)
+	}
+
+	lower := strings.ToLower(text)
+	switch {
+	case strings.Contains(lower, "system has not been booted with systemd"),
+		strings.Contains(lower, "failed to connect to bus"):
+		return text, fmt.Errorf("%w: %s", ErrServiceBackendUnavailable, text)
+	case strings.Contains(lower, "not found"), strings.Contains(lower, "does not exist"):
+		return text, fmt.Errorf("%w: %s", ErrServiceNotFound, text)
+	}
+	return text, err
+}
+
+// showSystemdUnits 通过 systemctl show 获取 unit 的状态，未加载的 unit 会被跳过
+func showSystemdUnits(units []string) ([]ServiceStatus, error) {
+	args := append([]string{"show", "--property=" + strings.Join(systemdShowProperties, ",")}, units...)
+	output, err := systemctl(args...)
+	if err != nil {
+		return nil, fmt.Errorf("获取服务状态失败: %w\n%s", err, output)
+	}
+
+	uptime := systemUptime()
+	var statuses []ServiceStatus
+	for _, block := range strings.Split(output, "\n\n") {
+		properties := map[string]string{}
+		for _, line := range strings.Split(block, "\n") {
+			if key, value, ok := strings.Cut(strings.TrimSpace(line), "="); ok {
+				properties[key] = value
+			}
+		}
+		if properties["Id"] == "" || properties["LoadState"] == "not-found" {
+			continue
+		}
+		statuses = append(statuses, systemdStatus(properties, uptime))
+	}
+	return statuses, nil
+}
+
+// systemdStatus 把 unit 属性转换为 supervisor 风格的状态，uptime 为系统已运行的秒数
+func systemdStatus(properties map[string]string, uptime float64) ServiceStatus {
+	status := ServiceStatus{
+		Name:   strings.TrimSuffix(strings.TrimPrefix(properties["Id"], systemdUnitPrefix), ".service"),
+		Status: "UNKNOWN",
+	}
+
+	switch properties["ActiveState"] {
+	case "active":
+		status.Status = "RUNNING"
+		if properties["SubState"] == "exited" {
+			status.Status = "EXITED"
+		}
+	case "activating":
+		status.Status = "STARTING"
+		if properties["SubState"] == "auto-restart" {
+			status.Status = "BACKOFF"
+		}
+	case "deactivating":
+		status.Status = "STOPPING"
+	case "failed":
+		status.Status = "FATAL"
+		status.Description = properties["Result"]
+	case "inactive":
+		status.Status = "STOPPED"
+	}
+
+	if status.Status == "RUNNING" {
+		status.PID, _ = strconv.Atoi(properties["MainPID"])
+		if started, err := strconv.ParseFloat(properties["ActiveEnterTimestampMonotonic"], 64); err == nil && started > 0 && uptime > 0 {
+			status.UptimeSeconds = int(uptime - started/1e6)
+			status.Uptime = formatUptime(status.UptimeSeconds)
+		}
+	} else if exited := properties["ExecMainExitTimestampMonotonic"]; exited != "" && exited != "0" {
+		if code, err := strconv.Atoi(properties["ExecMainStatus"]); err == nil {
+			status.ExitStatus = &code
+		}
+	}
+	return status
+}
+
+// systemUptime 从 /proc/uptime 读取系统已运行的秒数，与 systemd 的单调时间戳对应
+func systemUptime() float64 {
+	data, err := os.ReadFile("/proc/uptime")
+	if err != nil {
+		return 0
+	}
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 {
+		return 0
+	}
+	uptime, _ := strconv.ParseFloat(fields[0], 64)
+	return uptime
+}
+
+// systemdEscape 转义 unit 文件中的 % 说明符
+func systemdEscape(value string) string {
+	return strings.ReplaceAll(value, "%", "%%")
+}
+
+// systemdQuote 用双引号包裹 Environment 的值
+func systemdQuote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `"`, `\"`)
+	return `"` + systemdEscape(value) + `"`
+}
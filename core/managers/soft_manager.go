@@ -28,12 +28,13 @@ func NewSoftManager() *SoftManager {
 	sm := &SoftManager{
 		Softwares: make(map[string]contract.Software),
 		Gateways:  make(map[string]contract.SuperGateway),
+		Services:  make(map[string]contract.SuperService),
 		ShellUtil: shell_util.NewShellUtil(),
 	}
 
 	sm.ProxyManager = &ProxyManager{SoftManager: sm}
 	sm.GatewayManager = &GatewayManager{SoftManager: sm}
-	sm.AptManager = &soft_util.AptManager{}
+	sm.AptManager = soft_util.NewAptManager(sm.ShellUtil)
 	sm.DpkgManager = &soft_util.DpkgManager{}
 	sm.ServiceSoftManager = &ServiceSoftManager{SoftManager: sm}
 	return sm
@@ -0,0 +1,352 @@
+package managers
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TokenPrefix API 令牌的固定前缀，便于识别和扫描泄露
+const TokenPrefix = "svn_"
+
+// ScopeAll 拥有全部权限的作用域
+const ScopeAll = "*"
+
+// TokenScopes 所有可用的令牌作用域，与 Web API 的路由分组一一对应
+// 作用域本身授予读写权限，追加 ":read" 后缀则只授予只读权限
+var TokenScopes = []string{
+	"cron",
+	"deploy",
+	"files",
+	"github",
+	"info",
+	"integrations",
+	"logs",
+	"ports",
+	"processes",
+	"services",
+	"soft",
+	"tasks",
+	"topology",
+	"users",
+}
+
+// APIToken 表示一个个人访问令牌，磁盘上只保存令牌的哈希
+type APIToken struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Hash       string     `json:"hash"`
+	Scopes     []string   `json:"scopes"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`   // 为空表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"` // 为空表示从未使用
+}
+
+// IsExpired 判断令牌是否已过期，未设置过期时间的令牌永不过期
+func (t *APIToken) IsExpired() bool {
+	return t.ExpiresAt != nil && time.Now().After(*t.ExpiresAt)
+}
+
+// HasScope 判断令牌是否拥有访问某个资源的权限
+// readOnly 为 true 时，资源的只读作用域也视为满足
+func (t *APIToken) HasScope(resource string, readOnly bool) bool {
+	for _, scope := range t.Scopes {
+		if scope == ScopeAll || scope == resource || scope == resource+":write" {
+			return true
+		}
+		if readOnly && scope == resource+":read" {
+			return true
+		}
+	}
+	return false
+}
+
+// tokenLockFile 令牌文件的锁，服务进程和命令行进程读写令牌时通过它互斥
+const tokenLockFile = "tokens.lock"
+
+// TokenManager 负责 API 令牌的签发、校验和吊销
+type TokenManager struct {
+	storePath string
+	mu        sync.Mutex
+}
+
+// NewTokenManager 创建令牌管理器
+func NewTokenManager(configDir string) *TokenManager {
+	return &TokenManager{
+		storePath: filepath.Join(configDir, "tokens.json"),
+	}
+}
+
+// CreateToken 签发新的令牌，返回明文令牌（只在此时可见）和令牌记录
+// ttl 为 0 表示永不过期
+func (m *TokenManager) CreateToken(name string, scopes []string, ttl time.Duration) (string, *APIToken, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", nil, fmt.Errorf("令牌名称不能为空")
+	}
+	if len(scopes) == 0 {
+		return "", nil, fmt.Errorf("至少需要指定一个作用域")
+	}
+	for _, scope := range scopes {
+		if !isValidScope(scope) {
+			return "", nil, fmt.Errorf("无效的作用域: %s，可用的作用域: %s", scope, strings.Join(TokenScopes, ", "))
+		}
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return "", nil, fmt.Errorf("生成令牌ID失败: %v", err)
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", nil, fmt.Errorf("生成令牌失败: %v", err)
+	}
+	plain := TokenPrefix + id + "_" + secret
+
+	token := &APIToken{
+		ID:        id,
+		Name:      name,
+		Hash:      hashToken(plain),
+		Scopes:    scopes,
+		CreatedAt: time.Now(),
+	}
+	if ttl > 0 {
+		expiresAt := token.CreatedAt.Add(ttl)
+		token.ExpiresAt = &expiresAt
+	}
+
+	unlock, err := m.lock()
+	if err != nil {
+		return "", nil, err
+	}
+	defer unlock()
+
+	tokens, err := m.loadTokens()
+	if err != nil {
+		return "", nil, err
+	}
+	tokens = append(tokens, token)
+	if err := m.saveTokens(tokens); err != nil {
+		return "", nil, err
+	}
+
+	return plain, token, nil
+}
+
+// ListTokens 获取所有令牌
+func (m *TokenManager) ListTokens() ([]*APIToken, error) {
+	unlock, err := m.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tokens, err := m.loadTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].CreatedAt.Before(tokens[j].CreatedAt)
+	})
+	return tokens, nil
+}
+
+// RevokeToken 吊销指定ID的令牌
+func (m *TokenManager) RevokeToken(id string) error {
+	unlock, err := m.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tokens, err := m.loadTokens()
+	if err != nil {
+		return err
+	}
+
+	kept := make([]*APIToken, 0, len(tokens))
+	for _, token := range tokens {
+		if token.ID != id {
+			kept = append(kept, token)
+		}
+	}
+	if len(kept) == len(tokens) {
+		return fmt.Errorf("令牌不存在: %s", id)
+	}
+
+	return m.saveTokens(kept)
+}
+
+// ValidateToken 校验明文令牌，成功时返回令牌记录并更新最后使用时间
+func (m *TokenManager) ValidateToken(plain string) (*APIToken, error) {
+	if !strings.HasPrefix(plain, TokenPrefix) {
+		return nil, fmt.Errorf("无效的令牌格式")
+	}
+	parts := strings.SplitN(strings.TrimPrefix(plain, TokenPrefix), "_", 2)
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("无效的令牌格式")
+	}
+
+	unlock, err := m.lock()
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	tokens, err := m.loadTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	hash := hashToken(plain)
+	for _, token := range tokens {
+		if token.ID != parts[0] {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(token.Hash), []byte(hash)) != 1 {
+			return nil, fmt.Errorf("无效的令牌")
+		}
+		if token.IsExpired() {
+			return nil, fmt.Errorf("令牌已过期")
+		}
+
+		// 最后使用时间只精确到分钟，避免每个请求都写盘
+		if token.LastUsedAt == nil || time.Since(*token.LastUsedAt) > time.Minute {
+			now := time.Now()
+			token.LastUsedAt = &now
+			if err := m.saveTokens(tokens); err != nil {
+				PrintErrorf("记录令牌 %s 的使用时间失败: %v", token.ID, err)
+			}
+		}
+		return token, nil
+	}
+
+	return nil, fmt.Errorf("无效的令牌")
+}
+
+// ParseTokenTTL 解析令牌有效期，支持 Go 的时长格式以及以 d 结尾的天数，如 30d
+// 空字符串或 0 表示永不过期
+func ParseTokenTTL(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.Atoi(strings.TrimSuffix(value, "d"))
+		if err != nil || days < 0 {
+			return 0, fmt.Errorf("无效的有效期: %s", value)
+		}
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+
+	ttl, err := time.ParseDuration(value)
+	if err != nil || ttl < 0 {
+		return 0, fmt.Errorf("无效的有效期: %s", value)
+	}
+	return ttl, nil
+}
+
+// isValidScope 判断作用域是否合法
+func isValidScope(scope string) bool {
+	if scope == ScopeAll {
+		return true
+	}
+
+	resource := strings.TrimSuffix(strings.TrimSuffix(scope, ":read"), ":write")
+	for _, s := range TokenScopes {
+		if s == resource {
+			return true
+		}
+	}
+	return false
+}
+
+// hashToken 计算令牌的 SHA-256 哈希
+func hashToken(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// lock 获取令牌文件的读写锁，返回释放锁的函数
+// 先获取进程内的锁，再获取配置目录中的文件锁，避免命令行进程和服务进程同时读写时丢失修改
+func (m *TokenManager) lock() (func(), error) {
+	m.mu.Lock()
+
+	dir := filepath.Dir(m.storePath)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	lock, err := lockFile(context.Background(), filepath.Join(dir, tokenLockFile))
+	if err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	return func() {
+		lock.Unlock()
+		m.mu.Unlock()
+	}, nil
+}
+
+// loadTokens 从磁盘读取所有令牌，调用方需持有锁
+func (m *TokenManager) loadTokens() ([]*APIToken, error) {
+	data, err := os.ReadFile(m.storePath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*APIToken{}, nil
+		}
+		return nil, fmt.Errorf("读取令牌文件失败: %v", err)
+	}
+
+	var tokens []*APIToken
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return nil, fmt.Errorf("解析令牌文件失败: %v", err)
+	}
+
+	// 旧版本把未设置的时间保存为零值，读取时视为未设置
+	for _, token := range tokens {
+		if token.ExpiresAt != nil && token.ExpiresAt.IsZero() {
+			token.ExpiresAt = nil
+		}
+		if token.LastUsedAt != nil && token.LastUsedAt.IsZero() {
+			token.LastUsedAt = nil
+		}
+	}
+	return tokens, nil
+}
+
+// saveTokens 将令牌写入磁盘，调用方需持有锁
+func (m *TokenManager) saveTokens(tokens []*APIToken) error {
+	if err := os.MkdirAll(filepath.Dir(m.storePath), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("序列化令牌失败: %v", err)
+	}
+
+	// 先写临时文件再重命名，避免其他进程读到写了一半的文件
+	tmpPath := m.storePath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("写入令牌文件失败: %v", err)
+	}
+	if err := os.Rename(tmpPath, m.storePath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("写入令牌文件失败: %v", err)
+	}
+	return nil
+}
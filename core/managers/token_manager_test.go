@@ -0,0 +1,174 @@
+package managers
+
+import (
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCreateAndValidateToken 测试令牌只以哈希保存，并能通过明文校验
+func TestCreateAndValidateToken(t *testing.T) {
+	m := NewTokenManager(t.TempDir())
+
+	plain, token, err := m.CreateToken("ci", []string{"deploy"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(plain, TokenPrefix+token.ID+"_") {
+		t.Errorf("Unexpected token format: %s", plain)
+	}
+
+	data, err := os.ReadFile(m.storePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), plain) {
+		t.Error("Expected plain token not to be stored")
+	}
+	if !strings.Contains(string(data), hashToken(plain)) {
+		t.Error("Expected token hash to be stored")
+	}
+
+	if _, err := m.ValidateToken(plain); err != nil {
+		t.Errorf("Expected valid token, got %v", err)
+	}
+	for _, invalid := range []string{"", "abc", TokenPrefix + token.ID, plain + "x", TokenPrefix + "00000000_" + strings.Repeat("0", 48)} {
+		if _, err := m.ValidateToken(invalid); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+
+	if _, _, err := m.CreateToken("ci", []string{"unknown"}, 0); err == nil {
+		t.Error("Expected invalid scope to be rejected")
+	}
+	if _, _, err := m.CreateToken("ci", nil, 0); err == nil {
+		t.Error("Expected empty scopes to be rejected")
+	}
+}
+
+// TestTokenExpiryAndRevocation 测试过期和吊销的令牌不能再使用
+func TestTokenExpiryAndRevocation(t *testing.T) {
+	m := NewTokenManager(t.TempDir())
+
+	expired, _, err := m.CreateToken("expired", []string{"*"}, time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := m.ValidateToken(expired); err == nil {
+		t.Error("Expected expired token to be rejected")
+	}
+
+	revoked, token, _ := m.CreateToken("revoked", []string{"*"}, 0)
+	kept, _, _ := m.CreateToken("kept", []string{"*"}, 0)
+	if err := m.RevokeToken(token.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.ValidateToken(revoked); err == nil {
+		t.Error("Expected revoked token to be rejected")
+	}
+	if _, err := m.ValidateToken(kept); err != nil {
+		t.Errorf("Expected other token to stay valid, got %v", err)
+	}
+	if err := m.RevokeToken(token.ID); err == nil {
+		t.Error("Expected revoking twice to fail")
+	}
+}
+
+// TestLoadLegacyTokens 测试旧版本以零值保存的过期时间视为永不过期，未设置的时间不再写入文件
+func TestLoadLegacyTokens(t *testing.T) {
+	m := NewTokenManager(t.TempDir())
+
+	plain, _, err := m.CreateToken("ci", []string{"deploy"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(m.storePath)
+	if strings.Contains(string(data), "expires_at") || strings.Contains(string(data), "last_used_at") {
+		t.Errorf("Expected unset times to be omitted, got %s", data)
+	}
+
+	legacy := strings.Replace(string(data), `"created_at"`, `"expires_at": "0001-01-01T00:00:00Z", "last_used_at": "0001-01-01T00:00:00Z", "created_at"`, 1)
+	if err := os.WriteFile(m.storePath, []byte(legacy), 0600); err != nil {
+		t.Fatal(err)
+	}
+	token, err := m.ValidateToken(plain)
+	if err != nil {
+		t.Fatalf("Expected legacy token without expiry to be valid, got %v", err)
+	}
+	if token.ExpiresAt != nil || token.LastUsedAt == nil {
+		t.Errorf("Expected no expiry and a recorded last use, got %v %v", token.ExpiresAt, token.LastUsedAt)
+	}
+}
+
+// TestHasScope 测试读写作用域的匹配
+func TestHasScope(t *testing.T) {
+	cases := []struct {
+		scope    string
+		readOnly bool
+		want     bool
+	}{
+		{"*", false, true},
+		{"deploy", false, true},
+		{"deploy:write", false, true},
+		{"deploy:read", true, true},
+		{"deploy:read", false, false},
+		{"files", true, false},
+	}
+	for _, c := range cases {
+		token := &APIToken{Scopes: []string{c.scope}}
+		if got := token.HasScope("deploy", c.readOnly); got != c.want {
+			t.Errorf("HasScope(%q, readOnly=%v) = %v, want %v", c.scope, c.readOnly, got, c.want)
+		}
+	}
+}
+
+// TestParseTokenTTL 测试有效期的解析
+func TestParseTokenTTL(t *testing.T) {
+	cases := map[string]time.Duration{
+		"":    0,
+		"0":   0,
+		"30d": 30 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	}
+	for value, want := range cases {
+		if got, err := ParseTokenTTL(value); err != nil || got != want {
+			t.Errorf("ParseTokenTTL(%q) = %v, %v, want %v", value, got, err, want)
+		}
+	}
+	for _, value := range []string{"-1d", "abc", "xd", "-1h"} {
+		if _, err := ParseTokenTTL(value); err == nil {
+			t.Errorf("Expected %q to be rejected", value)
+		}
+	}
+}
+
+// TestConcurrentTokenWriters 测试共用令牌文件的多个管理器（如命令行进程和服务进程）同时写入时不会丢失令牌
+func TestConcurrentTokenWriters(t *testing.T) {
+	dir := t.TempDir()
+	managers := []*TokenManager{NewTokenManager(dir), NewTokenManager(dir)}
+
+	var wg sync.WaitGroup
+	for _, m := range managers {
+		wg.Add(1)
+		go func(m *TokenManager) {
+			defer wg.Done()
+			for i := 0; i < 5; i++ {
+				if _, _, err := m.CreateToken("ci", []string{"deploy"}, 0); err != nil {
+					t.Error(err)
+				}
+			}
+		}(m)
+	}
+	wg.Wait()
+
+	tokens, err := managers[0].ListTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tokens) != 10 {
+		t.Errorf("Expected 10 tokens, got %d", len(tokens))
+	}
+}
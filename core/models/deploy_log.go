@@ -2,14 +2,47 @@ package models
 
 import "time"
 
+// 部署状态
+const (
+	DeployStatusQueued     = "queued"      // 排队等待部署
+	DeployStatusRunning    = "running"     // 部署进行中
+	DeployStatusSuccess    = "success"     // 部署成功
+	DeployStatusFailed     = "failed"      // 部署失败
+	DeployStatusRolledBack = "rolled_back" // 健康检查失败，已回滚到上一个版本
+	DeployStatusSkipped    = "skipped"     // 推送的不是部署分支，跳过部署
+	DeployStatusCancelled  = "cancelled"   // 部署被取消，或排队时被新的推送取代
+)
+
+// 部署的触发来源
+const (
+	DeployTriggerWebhook = "webhook" // GitHub webhook 推送
+	DeployTriggerCLI     = "cli"     // 命令行执行
+	DeployTriggerAPI     = "api"     // Web API 调用
+)
+
+// 部署记录对应的操作
+const (
+	DeployActionDeploy   = "deploy"   // 拉取代码并部署
+	DeployActionRollback = "rollback" // 回滚到已有的发布版本
+)
+
 // DeployLog 表示一条部署日志记录
 // 包含部署的ID、时间戳、内容、仓库信息、状态和消息等信息
 type DeployLog struct {
-	ID        string    `json:"id"`        // 部署日志的唯一标识符
-	Timestamp time.Time `json:"timestamp"` // 部署时间
-	Content   string    `json:"content"`   // 部署的详细内容
-	Repo      string    `json:"repo"`      // 关联的代码仓库
-	Status    string    `json:"status"`    // 部署状态（如：成功、失败）
-	Message   string    `json:"message"`   // 部署相关的消息或错误信息
-	// 根据实际需求添加其他字段
+	ID          string    `json:"id"`                     // 部署日志的唯一标识符
+	QueuedAt    time.Time `json:"queued_at"`              // 加入部署队列的时间
+	Timestamp   time.Time `json:"timestamp"`              // 部署开始时间，排队期间为入队时间
+	FinishedAt  time.Time `json:"finished_at"`            // 部署结束时间
+	Content     string    `json:"content,omitempty"`      // 部署的详细输出，只在查看单条记录时填充
+	Project     string    `json:"project"`                // 项目名称
+	Repo        string    `json:"repo"`                   // 关联的代码仓库
+	Branch      string    `json:"branch"`                 // 部署的分支
+	Commit      string    `json:"commit"`                 // 部署的提交 SHA
+	Deployer    string    `json:"deployer"`               // 使用的部署器名称
+	Action      string    `json:"action,omitempty"`       // 操作，见 DeployAction*，为空表示部署
+	Trigger     string    `json:"trigger"`                // 触发来源（webhook/cli/api）
+	TriggeredBy string    `json:"triggered_by"`           // 触发者，如推送者或 API 调用者
+	Status      string    `json:"status"`                 // 部署状态（如：成功、失败）
+	Message     string    `json:"message"`                // 部署相关的消息或错误信息
+	HealthCheck string    `json:"health_check,omitempty"` // 健康检查失败时探测的输出
 }
@@ -30,6 +30,9 @@ func NewCommandProvider(fullManager *managers.FullManager, webServer *web_server
 	p.AddCommand(commands.GetUpgradeCommand(p.fullManager.VersionManager))
 	p.AddCommand(commands.GetSoftwareCommand(p.fullManager.SoftManager))
 	p.AddCommand(commands.GetGitRootCommand(p.fullManager.GitManager))
+	p.AddCommand(commands.GetTokenCommand(p.fullManager.TokenManager))
+	p.AddCommand(commands.GetCronCommand(p.fullManager))
+	p.AddCommand(commands.GetGitHubCommand(p.fullManager.GitHubIntegration))
 
 	return p
 }
@@ -31,6 +31,7 @@ func NewWebProvider(manager *managers.FullManager, host string, port int) *WebPr
 		config: config,
 	}
 
+	server.SetupHealthCheck()
 	routers.Setup(manager, server.Engine, true)
 
 	return webProvider
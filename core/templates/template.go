@@ -45,3 +45,12 @@ func GetSupervisorConfigTemplate() (string, error) {
 	}
 	return string(tmplContent), nil
 }
+
+// GetSystemdServiceTemplate 返回systemd服务模板内容
+func GetSystemdServiceTemplate() (string, error) {
+	tmplContent, err := SystemdServiceTemplateFS.ReadFile("systemd_service.tmpl")
+	if err != nil {
+		return "", fmt.Errorf("读取systemd模板文件失败: %v", err)
+	}
+	return string(tmplContent), nil
+}
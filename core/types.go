@@ -24,8 +24,12 @@ type SuperService = contract.SuperService
 type DeployLog = models.DeployLog
 type WebhookPayload = github.WebhookPayload
 type OSType = managers.OSType
+type ServiceSpec = managers.ServiceSpec
 type Project = contract.Project
 
 type Deployer = contract.SuperDeployer
+type DeployContext = contract.DeployContext
+type RollbackHook = contract.RollbackHook
+type DeployConfig = contract.DeployConfig
 
 type LogUtil = logger.LogUtil
@@ -0,0 +1,80 @@
+package controllers
+
+import (
+	"net/http"
+	"time"
+
+	"servon/core/managers"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AuthController struct {
+	*managers.FullManager
+}
+
+func NewAuthController(manager *managers.FullManager) *AuthController {
+	return &AuthController{FullManager: manager}
+}
+
+// HandleLogin 处理登录请求，校验成功后签发会话 Cookie
+func (h *AuthController) HandleLogin(c *gin.Context) {
+	var req struct {
+		Username string `json:"username" binding:"required"`
+		Password string `json:"password" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	if !h.HasAdminAccount() {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "尚未设置管理员账号，请先执行: servon server passwd"})
+		return
+	}
+
+	if !h.VerifyAdminPassword(req.Username, req.Password) {
+		// 登录失败时稍作延迟，降低暴力破解的速度
+		time.Sleep(time.Second)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "用户名或密码错误"})
+		return
+	}
+
+	token, session, err := h.CreateSession(req.Username)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(managers.SessionCookieName, token, int(managers.SessionTTL.Seconds()), "/", "", c.Request.TLS != nil, true)
+	c.JSON(http.StatusOK, session)
+}
+
+// HandleLogout 处理注销请求
+func (h *AuthController) HandleLogout(c *gin.Context) {
+	if token, err := c.Cookie(managers.SessionCookieName); err == nil {
+		h.RevokeSession(token)
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(managers.SessionCookieName, "", -1, "/", "", c.Request.TLS != nil, true)
+	c.Status(http.StatusOK)
+}
+
+// HandleCurrentSession 返回当前会话信息
+func (h *AuthController) HandleCurrentSession(c *gin.Context) {
+	token, err := c.Cookie(managers.SessionCookieName)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+		return
+	}
+
+	session, err := h.ValidateSession(token)
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, session)
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"servon/components/cron_util"
 	"servon/core/managers"
@@ -98,3 +99,39 @@ func (c *CronController) HandleToggleCronTask(ctx *gin.Context) {
 	}
 	ctx.JSON(http.StatusOK, task)
 }
+
+// HandleRunCronTask 处理立即执行定时任务的请求，任务在后台执行
+func (c *CronController) HandleRunCronTask(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务ID"})
+		return
+	}
+
+	run, err := c.RunCronTask(id)
+	if errors.Is(err, cron_util.ErrTaskRunning) {
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusAccepted, run)
+}
+
+// HandleListCronTaskRuns 处理获取定时任务执行记录的请求
+func (c *CronController) HandleListCronTaskRuns(ctx *gin.Context) {
+	id, err := strconv.Atoi(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的任务ID"})
+		return
+	}
+
+	runs, err := c.GetCronTaskRuns(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, runs)
+}
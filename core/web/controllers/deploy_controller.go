@@ -1,12 +1,23 @@
 package controllers
 
 import (
+	"context"
+	"errors"
+	"io"
 	"net/http"
 	"servon/core/managers"
+	"servon/core/models"
+	"servon/core/web/middlewares"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// deployStreamHeartbeat 部署输出流发送心跳的间隔
+const deployStreamHeartbeat = 15 * time.Second
+
 type DeployController struct {
 	*managers.FullManager
 }
@@ -15,7 +26,8 @@ func NewDeployController(manager *managers.FullManager) *DeployController {
 	return &DeployController{FullManager: manager}
 }
 
-// DeployRepository 部署仓库
+// DeployRepository 将仓库加入部署队列，立即返回部署ID
+// 部署输出可以通过 /deploy/:id/stream 实时获取
 func (h *DeployController) DeployRepository(c *gin.Context) {
 	repoID := c.Query("id")
 	if repoID == "" {
@@ -23,7 +35,185 @@ func (h *DeployController) DeployRepository(c *gin.Context) {
 		return
 	}
 
-	h.DeployProject(repoID)
+	record, err := h.EnqueueDeploy(managers.DeployRequest{
+		Repo:        repoID,
+		Branch:      c.Query("branch"),
+		Trigger:     models.DeployTriggerAPI,
+		TriggeredBy: c.GetString(middlewares.ContextUserKey),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"message": "部署已加入队列",
+		"id":      record.ID,
+		"deploy":  record,
+		"stream":  "/web_api/deploy/" + record.ID + "/stream",
+	})
+}
+
+// HandleDeployStream 通过 Server-Sent Events 逐行推送部署输出
+// 每行输出为一个 output 事件，部署结束时发送携带部署记录的 done 事件；
+// 部署排队期间没有输出，定期发送注释作为心跳，避免连接被代理超时断开
+func (h *DeployController) HandleDeployStream(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.GetDeployRecord(id); err != nil {
+		if errors.Is(err, managers.ErrDeployNotFound) {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	c.Writer.Flush()
+
+	// 输出和心跳在不同的 goroutine 中写入
+	var mu sync.Mutex
+	send := func(event string, data interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		c.SSEvent(event, data)
+		c.Writer.Flush()
+	}
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	defer wg.Wait()
+	defer close(stop)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		ticker := time.NewTicker(deployStreamHeartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				mu.Lock()
+				io.WriteString(c.Writer, ": heartbeat\n\n")
+				c.Writer.Flush()
+				mu.Unlock()
+			}
+		}
+	}()
+
+	record, err := h.FollowDeployOutput(c.Request.Context(), id, func(line string) {
+		send("output", line)
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return
+		}
+		send("error", err.Error())
+		return
+	}
+
+	send("done", record)
+}
+
+// HandleDeployHistory 获取部署历史，支持按项目过滤
+func (h *DeployController) HandleDeployHistory(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+
+	records, err := h.ListDeployLogs(c.Query("project"), limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, records)
+}
+
+// HandleDeployDetail 获取单次部署的详情和输出
+func (h *DeployController) HandleDeployDetail(c *gin.Context) {
+	record, err := h.GetDeployLog(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, record)
+}
+
+// HandleListReleases 获取项目的发布版本
+func (h *DeployController) HandleListReleases(c *gin.Context) {
+	releases, err := h.ListReleases(c.Param("project"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, releases)
+}
+
+// HandleRollback 回滚项目到指定版本，不指定版本时回滚到上一个版本
+func (h *DeployController) HandleRollback(c *gin.Context) {
+	var req struct {
+		Release string `json:"release"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	release, err := h.Rollback(managers.RollbackRequest{
+		Project:     c.Param("project"),
+		Release:     req.Release,
+		Trigger:     models.DeployTriggerAPI,
+		TriggeredBy: c.GetString(middlewares.ContextUserKey),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error(), "release": release})
+		return
+	}
+
+	c.JSON(http.StatusOK, release)
+}
+
+// HandleGetDeploySettings 获取部署配置
+func (h *DeployController) HandleGetDeploySettings(c *gin.Context) {
+	c.JSON(http.StatusOK, h.GetDeploySettings())
+}
+
+// HandleUpdateDeploySettings 更新部署配置
+func (h *DeployController) HandleUpdateDeploySettings(c *gin.Context) {
+	// 在当前配置的基础上更新，请求中未提供的字段保持不变
+	settings := h.GetDeploySettings()
+	if err := c.ShouldBindJSON(&settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据"})
+		return
+	}
+
+	if err := h.SaveDeploySettings(settings); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, settings)
+}
+
+// HandleDeployQueue 获取部署队列的状态
+func (h *DeployController) HandleDeployQueue(c *gin.Context) {
+	c.JSON(http.StatusOK, h.GetDeployQueue())
+}
+
+// HandleCancelDeploy 取消排队中或正在进行的部署
+func (h *DeployController) HandleCancelDeploy(c *gin.Context) {
+	record, err := h.CancelDeploy(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error(), "deploy": record})
+		return
+	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "部署仓库成功"})
+	c.JSON(http.StatusOK, gin.H{"message": "已请求取消部署", "deploy": record})
 }
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"servon/components/github"
@@ -60,7 +61,15 @@ func (h *GitHubController) HandleGitHubCallback(c *gin.Context) {
 func (h *GitHubController) HandleGitHubWebhook(c *gin.Context) {
 	logger.Infof("HandleGitHubWebhook")
 	if err := h.GitHubIntegration.HandleWebhook(c); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		switch {
+		case errors.Is(err, github.ErrDuplicateDelivery):
+			// 重复投递返回成功，避免 GitHub 继续重试
+			c.JSON(http.StatusOK, gin.H{"message": err.Error()})
+		case errors.Is(err, github.ErrInvalidSignature):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+		default:
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		}
 		return
 	}
 
@@ -1,9 +1,11 @@
 package controllers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"servon/core/managers"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
@@ -20,77 +22,172 @@ func NewServiceController(manager *managers.ServiceManager) *ServiceController {
 	}
 }
 
+// respondServiceError 根据错误类型返回对应的状态码
+func respondServiceError(ctx *gin.Context, err error) {
+	status := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, managers.ErrServiceNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, managers.ErrServiceExists):
+		status = http.StatusConflict
+	case errors.Is(err, managers.ErrInvalidServiceConfig):
+		status = http.StatusBadRequest
+	case errors.Is(err, managers.ErrServiceBackendUnavailable):
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, gin.H{"error": err.Error()})
+}
+
 // GetServiceList 获取所有服务列表
 func (c *ServiceController) GetServiceList(ctx *gin.Context) {
-
-	ctx.String(http.StatusOK, "")
+	statuses, err := c.manager.GetServiceStatuses()
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	if statuses == nil {
+		statuses = []managers.ServiceStatus{}
+	}
+	ctx.JSON(http.StatusOK, statuses)
 }
 
 // StartService 启动指定服务
 func (c *ServiceController) StartService(ctx *gin.Context) {
-	name := ctx.Param("name")
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("服务 %s 已启动", name),
-	})
+	c.operate(ctx, c.manager.Start)
 }
 
 // StopService 停止指定服务
 func (c *ServiceController) StopService(ctx *gin.Context) {
-	name := ctx.Param("name")
-
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("服务 %s 已停止", name),
-	})
+	c.operate(ctx, c.manager.Stop)
 }
 
 // RestartService 重启指定服务
 func (c *ServiceController) RestartService(ctx *gin.Context) {
+	c.operate(ctx, c.manager.Restart)
+}
+
+// operate 对服务执行操作，成功后返回服务的最新状态
+func (c *ServiceController) operate(ctx *gin.Context, action func(string) error) {
 	name := ctx.Param("name")
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"message": fmt.Sprintf("服务 %s 已重启", name),
-	})
+	if _, err := c.manager.GetServiceStatus(name); err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	if err := action(name); err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	status, err := c.manager.GetServiceStatus(name)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, status)
 }
 
 // GetServiceConfig 获取服务配置
 func (c *ServiceController) GetServiceConfig(ctx *gin.Context) {
-
-	ctx.JSON(http.StatusOK, "")
+	config, err := c.manager.GetServiceConfig(ctx.Param("name"))
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, config)
 }
 
-// UpdateServiceConfig 更新服务配置
+// UpdateServiceConfig 更新服务定义，请求体中只需包含要修改的字段
 func (c *ServiceController) UpdateServiceConfig(ctx *gin.Context) {
 	name := ctx.Param("name")
 
+	var config map[string]interface{}
+	if err := ctx.ShouldBindJSON(&config); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据: " + err.Error()})
+		return
+	}
+
+	if err := c.manager.UpdateServiceConfig(name, config); err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": fmt.Sprintf("服务 %s 配置已更新", name),
 	})
 }
 
-// GetServiceLogs 获取服务日志
+// GetServiceLogs 获取服务日志，支持 lines 和 stream（stdout 或 stderr）参数
 func (c *ServiceController) GetServiceLogs(ctx *gin.Context) {
+	lines, err := strconv.Atoi(ctx.DefaultQuery("lines", "100"))
+	if err != nil || lines <= 0 {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的行数"})
+		return
+	}
+	stream := ctx.Query("stream")
+	if stream != "" && stream != "stdout" && stream != "stderr" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的日志类型，可选值: stdout、stderr"})
+		return
+	}
 
-	ctx.String(http.StatusOK, "")
+	logs, err := c.manager.GetServiceLogs(ctx.Param("name"), lines, stream)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+	ctx.String(http.StatusOK, logs)
 }
 
-// GetServiceDetails 获取服务详情
+// GetServiceDetails 获取服务详情，Servon 创建的服务还会返回配置文件和日志文件的路径
 func (c *ServiceController) GetServiceDetails(ctx *gin.Context) {
 	name := ctx.Param("name")
 
-	ctx.JSON(http.StatusOK, name)
+	status, err := c.manager.GetServiceStatus(name)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	details := gin.H{"status": status}
+	details["backend"] = c.manager.BackendName()
+	if status.Managed {
+		details["config_file"] = c.manager.GetServiceFilePath(name)
+		for stream, path := range c.manager.GetServiceLogFiles(name) {
+			details[stream+"_log"] = path
+		}
+	}
+	ctx.JSON(http.StatusOK, details)
 }
 
-// AddBackgroundService 添加后台服务
+// AddBackgroundService 添加后台服务，请求体为服务定义，名称、命令等由 ServiceManager 检查
 func (c *ServiceController) AddBackgroundService(ctx *gin.Context) {
+	var spec managers.ServiceSpec
+	if err := ctx.ShouldBindJSON(&spec); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "无效的请求数据: " + err.Error()})
+		return
+	}
 
-	ctx.JSON(http.StatusOK, "")
+	configPath, err := c.manager.AddBackgroundService(spec)
+	if err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, gin.H{
+		"message":     fmt.Sprintf("后台服务 %s 已添加", spec.Name),
+		"config_file": configPath,
+	})
 }
 
 // RemoveBackgroundService 删除后台服务
 func (c *ServiceController) RemoveBackgroundService(ctx *gin.Context) {
 	name := ctx.Param("name")
 
+	if err := c.manager.StopBackgroundService(name, nil); err != nil {
+		respondServiceError(ctx, err)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, gin.H{
 		"message": fmt.Sprintf("后台服务 %s 已删除", name),
 	})
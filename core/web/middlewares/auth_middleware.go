@@ -0,0 +1,104 @@
+package middlewares
+
+import (
+	"net/http"
+	"strings"
+
+	"servon/core/managers"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ContextUserKey 在 gin.Context 中保存当前登录用户名的键
+const ContextUserKey = "servon_user"
+
+// ContextTokenKey 在 gin.Context 中保存当前 API 令牌的键，会话登录时不存在
+const ContextTokenKey = "servon_token"
+
+// AuthRequired 校验请求是否携带有效的会话或 API 令牌，skipPaths 中的路由无需认证
+func AuthRequired(auth *managers.AuthManager, tokens *managers.TokenManager, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(c *gin.Context) {
+		if skip[c.FullPath()] {
+			c.Next()
+			return
+		}
+
+		// 优先使用 Authorization: Bearer 令牌，供自动化脚本调用
+		if header := c.GetHeader("Authorization"); header != "" {
+			plain, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "仅支持 Bearer 认证"})
+				return
+			}
+
+			token, err := tokens.ValidateToken(strings.TrimSpace(plain))
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+				return
+			}
+
+			c.Set(ContextUserKey, "token:"+token.Name)
+			c.Set(ContextTokenKey, token)
+			c.Next()
+			return
+		}
+
+		if !auth.HasAdminAccount() {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "尚未设置管理员账号，请先执行: servon server passwd",
+			})
+			return
+		}
+
+		token, err := c.Cookie(managers.SessionCookieName)
+		if err != nil || token == "" {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "未登录"})
+			return
+		}
+
+		session, err := auth.ValidateSession(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.Set(ContextUserKey, session.Username)
+		c.Next()
+	}
+}
+
+// RequireScope 限制 API 令牌只能访问其作用域内的资源
+// GET/HEAD 请求需要 resource:read 或 resource，其余请求需要 resource 或 resource:write
+// 通过会话登录的管理员不受限制
+func RequireScope(resource string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, exists := c.Get(ContextTokenKey)
+		if !exists {
+			c.Next()
+			return
+		}
+
+		token, ok := value.(*managers.APIToken)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "无效的令牌"})
+			return
+		}
+
+		readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+		if !token.HasScope(resource, readOnly) {
+			scope := resource
+			if readOnly {
+				scope = resource + ":read"
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "令牌缺少作用域: " + scope})
+			return
+		}
+
+		c.Next()
+	}
+}
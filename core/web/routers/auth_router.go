@@ -0,0 +1,18 @@
+package routers
+
+import (
+	"servon/core/managers"
+	"servon/core/web/controllers"
+
+	"github.com/gin-gonic/gin"
+)
+
+func SetupAuthRouter(r *gin.RouterGroup, manager *managers.FullManager) {
+	controller := controllers.NewAuthController(manager)
+
+	// 认证相关API
+	group := r.Group("/auth")
+	group.POST("/login", controller.HandleLogin)           // 登录
+	group.POST("/logout", controller.HandleLogout)         // 注销
+	group.GET("/session", controller.HandleCurrentSession) // 获取当前会话
+}
@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +11,7 @@ import (
 func SetupGitHubRouter(r *gin.RouterGroup, fullIntegration *managers.FullManager) {
 	controller := controllers.NewGitHubController(fullIntegration)
 
-	group := r.Group("/github")
+	group := r.Group("/github", middlewares.RequireScope("github"))
 	group.POST("/setup", controller.HandleGitHubSetup)
 	group.GET("/callback", controller.HandleGitHubCallback)
 	group.POST("/webhook", controller.HandleGitHubWebhook)
@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +11,7 @@ import (
 func SetupInfoRouter(r *gin.RouterGroup, manager *managers.FullManager) {
 	controller := controllers.NewInfoController(manager)
 
-	api := r.Group("/info")
+	api := r.Group("/info", middlewares.RequireScope("info"))
 	api.GET("/resources", controller.HandleSystemResources)
 	api.GET("/network", controller.HandleNetworkResources)
 	api.GET("/user", controller.HandleCurrentUser)
@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +11,7 @@ import (
 func SetupIntegrationRouter(r *gin.RouterGroup, fullIntegration *managers.FullManager) {
 	controller := controllers.NewIntegrationController(fullIntegration)
 
-	api := r.Group("/integrations")
+	api := r.Group("/integrations", middlewares.RequireScope("integrations"))
 	api.GET("/github/repos", controller.HandleListGitHubRepos)
 	// api.GET("/", w.Handler.HandleIntegrationList)
 	// api.GET("", w.Handler.HandleIntegrationList)
@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +11,7 @@ import (
 func SetupLogRouter(r *gin.RouterGroup, logManager *managers.LogManager) {
 	controller := controllers.NewLogController(logManager)
 
-	group := r.Group("/logs")
+	group := r.Group("/logs", middlewares.RequireScope("logs"))
 	{
 		group.GET("/files", controller.HandleListLogFiles)     // 获取日志文件列表
 		group.GET("/entries", controller.HandleReadLogEntries) // 读取日志内容
@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,7 +12,7 @@ func SetupPortRouter(r *gin.RouterGroup, manager *managers.FullManager) {
 	controller := controllers.NewPortController(manager)
 
 	// 端口管理相关API
-	api := r.Group("/ports")
+	api := r.Group("/ports", middlewares.RequireScope("ports"))
 	api.GET("/", controller.HandlePortList)
 	api.GET("", controller.HandlePortList)
 }
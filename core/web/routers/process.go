@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,7 +12,7 @@ func SetupProcessRouter(r *gin.RouterGroup, manager *managers.FullManager) {
 	controller := controllers.NewProcessController(manager)
 
 	// 进程管理相关API
-	api := r.Group("/processes")
+	api := r.Group("/processes", middlewares.RequireScope("processes"))
 	api.GET("/", controller.HandleProcessList)
 	api.GET("", controller.HandleProcessList)
 }
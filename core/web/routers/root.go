@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -13,8 +14,16 @@ func Setup(manager *managers.FullManager, r *gin.Engine, isDev bool) {
 	fileController := controllers.NewFileController(manager)
 	cronController := controllers.NewCronController(manager)
 
-	api := r.Group("/web_api")
+	// 除登录接口和 GitHub webhook 外，所有 API 都需要登录会话或 API 令牌
+	authRequired := middlewares.AuthRequired(manager.AuthManager, manager.TokenManager,
+		"/web_api/auth/login",
+		"/web_api/auth/logout",
+		"/web_api/github/webhook",
+	)
 
+	api := r.Group("/web_api", authRequired)
+
+	SetupAuthRouter(api, manager)
 	SetupSoftRouter(api, manager)
 	SetupProcessRouter(api, manager)
 	SetupInfoRouter(api, manager)
@@ -27,15 +36,17 @@ func Setup(manager *managers.FullManager, r *gin.Engine, isDev bool) {
 	SetupTopologyRoutes(api, manager.ProjectManager)
 
 	// 定时任务相关API
-	group := r.Group("/cron")
+	group := r.Group("/cron", authRequired, middlewares.RequireScope("cron"))
 	group.GET("/tasks", cronController.HandleListCronTasks)              // 获取所有定时任务
 	group.POST("/tasks", cronController.HandleCreateCronTask)            // 创建定时任务
 	group.PUT("/tasks/:id", cronController.HandleUpdateCronTask)         // 更新定时任务
 	group.DELETE("/tasks/:id", cronController.HandleDeleteCronTask)      // 删除定时任务
 	group.POST("/tasks/:id/toggle", cronController.HandleToggleCronTask) // 启用/禁用定时任务
+	group.POST("/tasks/:id/run", cronController.HandleRunCronTask)       // 立即执行定时任务
+	group.GET("/tasks/:id/runs", cronController.HandleListCronTaskRuns)  // 获取定时任务的执行记录
 
 	// 文件管理
-	fileGroup := api.Group("/files")
+	fileGroup := api.Group("/files", middlewares.RequireScope("files"))
 	fileGroup.GET("/", fileController.HandleFileList)
 	fileGroup.GET("", fileController.HandleFileList)
 	fileGroup.GET("/download", fileController.HandleFileDownload)
@@ -48,11 +59,20 @@ func Setup(manager *managers.FullManager, r *gin.Engine, isDev bool) {
 	fileGroup.POST("/copy", fileController.HandleCopyFile)
 
 	// 部署管理
-	deployRouter := api.Group("/deploy")
+	deployRouter := api.Group("/deploy", middlewares.RequireScope("deploy"))
 	deployRouter.POST("/repository", deployController.DeployRepository)
+	deployRouter.GET("/history", deployController.HandleDeployHistory)
+	deployRouter.GET("/history/:id", deployController.HandleDeployDetail)
+	deployRouter.GET("/queue", deployController.HandleDeployQueue)
+	deployRouter.POST("/:id/cancel", deployController.HandleCancelDeploy)
+	deployRouter.GET("/:id/stream", deployController.HandleDeployStream)
+	deployRouter.GET("/releases/:project", deployController.HandleListReleases)
+	deployRouter.POST("/releases/:project/rollback", deployController.HandleRollback)
+	deployRouter.GET("/settings", deployController.HandleGetDeploySettings)
+	deployRouter.PUT("/settings", deployController.HandleUpdateDeploySettings)
 
 	// 服务管理路由组
-	serviceGroup := api.Group("/services")
+	serviceGroup := api.Group("/services", middlewares.RequireScope("services"))
 	{
 		// 获取所有服务列表
 		serviceGroup.GET("", serviceController.GetServiceList)
@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +11,7 @@ import (
 func SetupSoftRouter(r *gin.RouterGroup, manager *managers.FullManager) {
 	controller := controllers.NewSoftController(manager)
 
-	api := r.Group("/soft")
+	api := r.Group("/soft", middlewares.RequireScope("soft"))
 	api.GET("", controller.HandleGetSoftwareList)
 	api.GET("/", controller.HandleGetSoftwareList)
 	api.POST("/:name/install", controller.HandleInstallSoftware)
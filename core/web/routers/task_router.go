@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,7 +12,7 @@ func SetupTaskRouter(r *gin.RouterGroup, manager *managers.FullManager) {
 	controller := controllers.NewTaskController(manager)
 
 	// 任务管理相关API
-	group := r.Group("/tasks")
+	group := r.Group("/tasks", middlewares.RequireScope("tasks"))
 	group.GET("", controller.HandleListTasks)
 	group.GET("/", controller.HandleListTasks)               // 获取任务列表
 	group.DELETE("/:id", controller.HandleRemoveTask)        // 删除任务
@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -10,7 +11,7 @@ import (
 func SetupTopologyRoutes(r *gin.RouterGroup, manager *managers.ProjectManager) {
 	controller := controllers.NewTopologyController(manager)
 
-	topology := r.Group("/topology")
+	topology := r.Group("/topology", middlewares.RequireScope("topology"))
 	{
 		topology.GET("/gateways", controller.HandleGetGateways)
 		topology.GET("/gateways/:gateway/projects", controller.HandleGetProjects)
@@ -3,6 +3,7 @@ package routers
 import (
 	"servon/core/managers"
 	"servon/core/web/controllers"
+	"servon/core/web/middlewares"
 
 	"github.com/gin-gonic/gin"
 )
@@ -11,7 +12,7 @@ func SetupUserRouter(r *gin.RouterGroup, manager *managers.FullManager) {
 	controller := controllers.NewUserController(manager)
 
 	// 用户管理相关API
-	group := r.Group("/users")
+	group := r.Group("/users", middlewares.RequireScope("users"))
 	group.GET("", controller.HandleListUsers)
 	group.GET("/", controller.HandleListUsers)              // 获取用户列表
 	group.POST("/", controller.HandleCreateUser)            // 创建用户
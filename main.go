@@ -10,17 +10,27 @@ import (
 	"servon/plugins/astro"
 	"servon/plugins/caddy"
 	"servon/plugins/clash"
+	"servon/plugins/docker"
 	"servon/plugins/git"
 	"servon/plugins/github_runner"
+	"servon/plugins/golang"
 	"servon/plugins/ip"
 	"servon/plugins/joke"
+	"servon/plugins/laravel"
+	"servon/plugins/nextjs"
 	"servon/plugins/nodejs"
 	"servon/plugins/npm"
+	"servon/plugins/nuxt"
 	"servon/plugins/ping"
 	"servon/plugins/pm2"
 	"servon/plugins/pnpm"
 	"servon/plugins/port"
+	"servon/plugins/python"
+	"servon/plugins/remix"
+	"servon/plugins/rust"
+	"servon/plugins/static"
 	"servon/plugins/supervisor"
+	"servon/plugins/sveltekit"
 	"servon/plugins/xcode"
 	"servon/plugins/yarn"
 )
@@ -32,17 +42,27 @@ func main() {
 	astro.Setup(app)
 	caddy.Setup(app)
 	clash.Setup(app)
+	docker.Setup(app)
 	git.Setup(app)
 	github_runner.Setup(app)
+	golang.Setup(app)
 	ip.Setup(app)
 	joke.Setup(app)
+	laravel.Setup(app)
+	nextjs.Setup(app)
 	nodejs.Setup(app)
 	npm.Setup(app)
+	nuxt.Setup(app)
 	ping.Setup(app)
 	pm2.Setup(app)
 	pnpm.Setup(app)
 	port.Setup(app)
+	python.Setup(app)
+	remix.Setup(app)
+	rust.Setup(app)
+	static.Setup(app)
 	supervisor.Setup(app)
+	sveltekit.Setup(app)
 	xcode.Setup(app)
 	yarn.Setup(app)
 
@@ -0,0 +1,148 @@
+// Package backend 提供后端服务部署器的通用实现
+//
+// Go、Rust 和 Python 部署器负责构建，发布版本、启动命令和
+// 后台服务的注册都由这里完成。
+package backend
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"servon/core"
+)
+
+const DefaultHost = "0.0.0.0"
+
+// DefaultPort servon.yaml 未配置端口时服务监听的端口
+const DefaultPort = 8080
+
+// Deployer 后端服务的通用部署器
+type Deployer struct {
+	*core.App
+	Name  string // 部署器名称，与 DetectProjectType 返回的项目类型一致
+	Title string // 显示名称
+	// Env 返回服务需要的额外环境变量，如 Python 虚拟环境的 PATH
+	Env func(current string) []string
+}
+
+func NewDeployer(app *core.App, name, title string) *Deployer {
+	return &Deployer{
+		App:   app,
+		Name:  name,
+		Title: title,
+	}
+}
+
+func (d *Deployer) GetName() string {
+	return d.Name
+}
+
+// EnsurePackages 找不到 binary 时通过 apt 安装 packages
+func (d *Deployer) EnsurePackages(ctx *core.DeployContext, binary string, packages ...string) error {
+	if _, err := exec.LookPath(binary); err == nil {
+		return nil
+	}
+
+	ctx.Printf("未找到 %s，开始安装: %v\n", binary, packages)
+	if _, err := d.AptUpdate(); err != nil {
+		ctx.Printf("更新软件包索引失败: %v\n", err)
+		return err
+	}
+	if err := d.AptInstall(packages...); err != nil {
+		ctx.Printf("安装 %s 失败: %v\n", binary, err)
+		return fmt.Errorf("安装 %s 失败: %v", binary, err)
+	}
+	return nil
+}
+
+// Run 执行构建命令，失败时记录输出并返回带说明的错误
+func (d *Deployer) Run(ctx *core.DeployContext, dir, title string, command ...string) error {
+	ctx.Printf("开始%s\n", title)
+	if err := ctx.RunCommand(dir, command[0], command[1:]...); err != nil {
+		ctx.Printf("%s失败: %v\n", title, err)
+		return fmt.Errorf("%s失败: %v", title, err)
+	}
+	return nil
+}
+
+// Release 将工作目录发布为新版本，返回发布目录
+func (d *Deployer) Release(ctx *core.DeployContext) (string, error) {
+	releaseDir, err := d.CreateRelease(ctx.TargetDir, ctx.WorkDir)
+	if err != nil {
+		ctx.Printf("创建发布版本失败: %v\n", err)
+		return "", fmt.Errorf("创建发布版本失败: %v", err)
+	}
+	return releaseDir, nil
+}
+
+// Serve 切换到发布版本，并以 current 目录为工作目录注册后台服务
+// start 为默认的启动命令，servon.yaml 中配置了 start 时使用配置的命令
+func (d *Deployer) Serve(ctx *core.DeployContext, releaseDir, start string) error {
+	projectName, targetDir, config := ctx.ProjectName, ctx.TargetDir, ctx.Config
+
+	if config.Start != "" {
+		start = config.Start
+	}
+	if start == "" {
+		ctx.Println("无法确定启动命令，请在 servon.yaml 中配置 start")
+		return fmt.Errorf("无法确定启动命令，请在 servon.yaml 中配置 start")
+	}
+
+	// 服务以项目专用用户运行，发布版本的文件归该用户所有
+	runUser, err := d.PrepareProjectUser(projectName, releaseDir, config.User)
+	if err != nil {
+		ctx.Printf("准备运行用户失败: %v\n", err)
+		return fmt.Errorf("准备运行用户失败: %v", err)
+	}
+
+	if err := d.ActivateRelease(targetDir, filepath.Base(releaseDir)); err != nil {
+		ctx.Printf("切换版本失败: %v\n", err)
+		return fmt.Errorf("切换版本失败: %v", err)
+	}
+
+	currentLink := filepath.Join(targetDir, "current")
+	host := DefaultHost
+	port := config.PortOrDefault(DefaultPort)
+	ctx.Port = port
+
+	// 启动命令中的路径基于 current 软链接，回滚后无需修改服务配置
+	command, args := "sh", []string{"-c", fmt.Sprintf("cd %s && exec %s", currentLink, start)}
+
+	spec := core.ServiceSpec{
+		Name:       projectName,
+		Command:    command,
+		Args:       args,
+		WorkingDir: currentLink,
+		User:       runUser,
+		Sandbox:    config.Sandbox,
+	}
+	spec.AddEnv(
+		fmt.Sprintf("HOST=%s", host),
+		fmt.Sprintf("PORT=%d", port),
+	)
+	if d.Env != nil {
+		spec.AddEnv(d.Env(currentLink)...)
+	}
+	spec.AddEnv(config.EnvList()...)
+
+	serviceFilePath, err := d.SaveBackgroundService(spec)
+	if err != nil {
+		ctx.Printf("配置后台服务失败: %v\n", err)
+		return fmt.Errorf("配置后台服务失败: %v", err)
+	}
+
+	ctx.Println()
+	ctx.Printf("✨ %s 项目部署成功！\n", d.Title)
+	ctx.Println()
+	ctx.Printf("📦 发布版本: %s\n", releaseDir)
+	ctx.Printf("📁 current（软链接） 路径: %s\n", currentLink)
+	ctx.Printf("📁 服务文件路径: %s\n", serviceFilePath)
+	ctx.Printf("🚀 启动命令: %s\n", start)
+	ctx.Printf("👤 运行用户: %s\n", runUser)
+	ctx.Printf("🌐 快速打开: http://%s:%d\n", host, port)
+	if config.Domain != "" {
+		ctx.Printf("🌐 域名: %s\n", config.Domain)
+	}
+	ctx.Println()
+	return nil
+}
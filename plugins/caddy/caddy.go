@@ -12,12 +12,14 @@ type Caddy struct {
 	BaseDir string
 	CaddyTemplate
 	*core.App
-	info core.SoftwareInfo
+	info  core.SoftwareInfo
+	admin *caddyAdmin
 }
 
 func NewCaddy(app *core.App) *Caddy {
 	return &Caddy{
-		App: app,
+		App:   app,
+		admin: newCaddyAdmin(DefaultAdminAddress),
 		info: core.SoftwareInfo{
 			Name:            "caddy",
 			Description:     "Modern web server with automatic HTTPS",
@@ -292,21 +294,6 @@ func (c *Caddy) SetConfig(config map[string]interface{}) error {
 	return fmt.Errorf("invalid config format")
 }
 
-func (c *Caddy) GetProjects() ([]core.Project, error) {
-	// TODO: 解析 Caddyfile 获取项目列表
-	return []core.Project{}, nil
-}
-
-func (c *Caddy) AddProject(project core.Project) error {
-	// TODO: 将项目配置添加到 Caddyfile
-	return c.Reload()
-}
-
-func (c *Caddy) RemoveProject(projectName string) error {
-	// TODO: 从 Caddyfile 中移除项目配置
-	return c.Reload()
-}
-
 func (c *Caddy) ReloadConfig() error {
 	return c.Reload()
 }
@@ -0,0 +1,227 @@
+package caddy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultAdminAddress Caddy 管理接口的默认地址
+const DefaultAdminAddress = "http://localhost:2019"
+
+// errAdminUnavailable 无法连接管理接口，通常是 Caddy 未运行
+var errAdminUnavailable = errors.New("无法连接 Caddy 管理接口")
+
+// adminMu 修改路由需要先读取再按下标修改，同时部署多个项目时依次进行
+var adminMu sync.Mutex
+
+// caddyAdmin 通过 Caddy 的 JSON 管理接口读取和修改运行中的配置
+// 修改只作用于内存中的配置，持久化依靠配置目录中的站点文件
+type caddyAdmin struct {
+	address string
+	client  *http.Client
+}
+
+func newCaddyAdmin(address string) *caddyAdmin {
+	return &caddyAdmin{
+		address: address,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// do 调用管理接口，body 为字符串时按 contentType 原样发送，其他值编码为 JSON；out 不为空时解码响应
+func (a *caddyAdmin) do(method, path, contentType string, body interface{}, out interface{}) error {
+	var reader io.Reader
+	switch v := body.(type) {
+	case nil:
+	case string:
+		reader = strings.NewReader(v)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+		contentType = "application/json"
+	}
+
+	req, err := http.NewRequest(method, a.address+path, reader)
+	if err != nil {
+		return err
+	}
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", errAdminUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("读取 Caddy 管理接口响应失败: %v", err)
+	}
+	if resp.StatusCode >= 400 {
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error != "" {
+			return fmt.Errorf("Caddy 管理接口 %s %s 失败: %s", method, path, apiErr.Error)
+		}
+		return fmt.Errorf("Caddy 管理接口 %s %s 返回 %s", method, path, resp.Status)
+	}
+
+	if out != nil && len(bytes.TrimSpace(data)) > 0 {
+		if err := json.Unmarshal(data, out); err != nil {
+			return fmt.Errorf("解析 Caddy 管理接口响应失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// httpServers 配置中 apps.http.servers 的内容，服务器和路由保持原样，修改时原样写回
+type httpServers map[string]map[string]json.RawMessage
+
+// adapt 把 Caddyfile 转换为 JSON 配置，同时校验其中的语法和指令
+func (a *caddyAdmin) adapt(caddyfile string) (httpServers, error) {
+	var result struct {
+		Result struct {
+			Apps struct {
+				HTTP struct {
+					Servers httpServers `json:"servers"`
+				} `json:"http"`
+			} `json:"apps"`
+		} `json:"result"`
+	}
+	if err := a.do(http.MethodPost, "/adapt", "text/caddyfile", caddyfile, &result); err != nil {
+		return nil, err
+	}
+	return result.Result.Apps.HTTP.Servers, nil
+}
+
+// servers 获取运行中的 HTTP 服务器，未配置时返回空
+func (a *caddyAdmin) servers() (httpServers, error) {
+	var servers httpServers
+	if err := a.do(http.MethodGet, "/config/apps/http/servers", "", nil, &servers); err != nil {
+		return nil, err
+	}
+	return servers, nil
+}
+
+// load 用 Caddyfile 替换全部配置，只在运行中的 Caddy 没有任何 HTTP 服务器时使用
+func (a *caddyAdmin) load(caddyfile string) error {
+	return a.do(http.MethodPost, "/load", "text/caddyfile", caddyfile, nil)
+}
+
+// routes 服务器的路由列表
+func (s httpServers) routes(server string) []json.RawMessage {
+	var routes []json.RawMessage
+	json.Unmarshal(s[server]["routes"], &routes)
+	return routes
+}
+
+// listen 服务器监听的地址，排序后拼接，用于比较两个服务器是否相同
+func (s httpServers) listen(server string) string {
+	var listen []string
+	json.Unmarshal(s[server]["listen"], &listen)
+	sort.Strings(listen)
+	return strings.Join(listen, ",")
+}
+
+// findRoute 查找匹配 host 的路由，返回服务器名称和路由
+func (s httpServers) findRoute(host string) (string, json.RawMessage, bool) {
+	for name := range s {
+		for _, route := range s.routes(name) {
+			if routeMatchesHost(route, host) {
+				return name, route, true
+			}
+		}
+	}
+	return "", nil, false
+}
+
+// serverByListen 查找监听地址相同的服务器，listen 为 listen() 的返回值
+func (s httpServers) serverByListen(listen string) string {
+	for name := range s {
+		if s.listen(name) == listen {
+			return name
+		}
+	}
+	return ""
+}
+
+// hosts 所有路由匹配的域名
+func (s httpServers) hosts() map[string]bool {
+	hosts := map[string]bool{}
+	for name := range s {
+		for _, route := range s.routes(name) {
+			for _, host := range routeHosts(route) {
+				hosts[host] = true
+			}
+		}
+	}
+	return hosts
+}
+
+// routeHosts 路由的 host 匹配条件中的域名
+func routeHosts(route json.RawMessage) []string {
+	var parsed struct {
+		Match []struct {
+			Host []string `json:"host"`
+		} `json:"match"`
+	}
+	json.Unmarshal(route, &parsed)
+
+	var hosts []string
+	for _, match := range parsed.Match {
+		hosts = append(hosts, match.Host...)
+	}
+	return hosts
+}
+
+func routeMatchesHost(route json.RawMessage, host string) bool {
+	for _, h := range routeHosts(route) {
+		if strings.EqualFold(h, host) {
+			return true
+		}
+	}
+	return false
+}
+
+// replaceRoute 删除服务器中匹配 host 的路由，再把 route 插入到最前面
+// 站点之间按 host 区分，插入到最前面可以避免被没有 host 条件的路由拦截
+func (a *caddyAdmin) replaceRoute(servers httpServers, server, host string, route json.RawMessage) error {
+	if err := a.deleteRoutes(servers, server, host); err != nil {
+		return err
+	}
+
+	path := "/config/apps/http/servers/" + server + "/routes"
+	if _, ok := servers[server]["routes"]; !ok {
+		return a.do(http.MethodPut, path, "", []json.RawMessage{route}, nil)
+	}
+	return a.do(http.MethodPut, path+"/0", "", route, nil)
+}
+
+// deleteRoutes 删除服务器中匹配 host 的路由，从后往前删除，避免下标变化
+func (a *caddyAdmin) deleteRoutes(servers httpServers, server, host string) error {
+	routes := servers.routes(server)
+	for i := len(routes) - 1; i >= 0; i-- {
+		if !routeMatchesHost(routes[i], host) {
+			continue
+		}
+		path := fmt.Sprintf("/config/apps/http/servers/%s/routes/%d", server, i)
+		if err := a.do(http.MethodDelete, path, "", nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"text/template"
 )
 
@@ -55,21 +56,31 @@ func (cc *Caddy) EnsureCaddyfile() error {
 
 // UpdateProjectConfig 更新特定项目的配置
 func (cc *Caddy) UpdateProjectConfig(project *Project) error {
-	// 确保配置目录存在
-	if err := cc.EnsureConfigDir(); err != nil {
-		return fmt.Errorf("failed to create config directory: %v", err)
+	content, err := cc.RenderProjectConfig(project)
+	if err != nil {
+		return err
+	}
+
+	// 确保主 Caddyfile 存在
+	if err := cc.EnsureCaddyfile(); err != nil {
+		return fmt.Errorf("failed to ensure Caddyfile exists: %v", err)
 	}
 
+	return cc.WriteConfig(cc.GetProjectConfigPath(project.Name), content)
+}
+
+// RenderProjectConfig 根据站点模板生成项目的配置内容
+func (cc *Caddy) RenderProjectConfig(project *Project) (string, error) {
 	// 读取站点配置模板
 	templateContent, err := templateFS.ReadFile(caddySiteTemplate)
 	if err != nil {
-		return fmt.Errorf("failed to read site template: %v", err)
+		return "", fmt.Errorf("failed to read site template: %v", err)
 	}
 
 	// 解析并执行模板
 	tmpl, err := template.New("caddy").Parse(string(templateContent))
 	if err != nil {
-		return fmt.Errorf("failed to parse config template: %v", err)
+		return "", fmt.Errorf("failed to parse config template: %v", err)
 	}
 
 	// 准备模板数据
@@ -78,31 +89,27 @@ func (cc *Caddy) UpdateProjectConfig(project *Project) error {
 		Type       string
 		OutputPath string
 		Port       int
+		SPA        bool
+		FastCGI    string
 	}{
 		Domain:     project.Domain,
 		Type:       project.Type,
 		OutputPath: project.OutputDir,
 		Port:       project.Port,
+		SPA:        project.SPA,
+		FastCGI:    project.FastCGI,
 	}
 
-	// 确保主 Caddyfile 存在
-	if err := cc.EnsureCaddyfile(); err != nil {
-		return fmt.Errorf("failed to ensure Caddyfile exists: %v", err)
-	}
-
-	// 创建项目配置文件
-	configFile := cc.GetProjectConfigPath(project.Name)
-	f, err := os.Create(configFile)
-	if err != nil {
-		return fmt.Errorf("failed to create config file: %v", err)
-	}
-	defer f.Close()
-
-	if err := tmpl.Execute(f, data); err != nil {
-		return fmt.Errorf("failed to generate config file: %v", err)
+	var content strings.Builder
+	if err := tmpl.Execute(&content, data); err != nil {
+		return "", fmt.Errorf("failed to generate config file: %v", err)
 	}
+	return content.String(), nil
+}
 
-	return nil
+// GetDisabledConfigPath 返回停用的项目配置文件路径，Caddyfile 只导入 *.conf，停用的配置不会生效
+func (cc *Caddy) GetDisabledConfigPath(projectName string) string {
+	return cc.GetProjectConfigPath(projectName) + ".disabled"
 }
 
 // WriteConfig 将配置内容写入指定路径
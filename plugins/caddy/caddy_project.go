@@ -0,0 +1,386 @@
+package caddy
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"servon/core"
+)
+
+// GetProjects 解析配置目录中的站点文件获取项目列表
+// Enabled 表示站点文件是否被 Caddyfile 导入，Config["active"] 表示运行中的 Caddy 是否已有该域名的路由
+func (c *Caddy) GetProjects() ([]core.Project, error) {
+	enabled, _ := filepath.Glob(filepath.Join(c.GetConfigDir(), "*.conf"))
+	disabled, _ := filepath.Glob(filepath.Join(c.GetConfigDir(), "*.conf.disabled"))
+
+	// Caddy 未运行时所有站点都不在线
+	live := map[string]bool{}
+	servers, err := c.admin.servers()
+	if err == nil {
+		live = servers.hosts()
+	}
+
+	projects := []core.Project{}
+	for _, path := range append(enabled, disabled...) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取站点配置失败: %v", err)
+		}
+
+		project := parseSiteConfig(string(content))
+		project.Name = strings.TrimSuffix(strings.TrimSuffix(filepath.Base(path), ".disabled"), ".conf")
+		project.Enabled = !strings.HasSuffix(path, ".disabled")
+		project.Config["config_file"] = path
+
+		active := false
+		for _, host := range siteHosts(project.Domain) {
+			active = active || live[host]
+		}
+		if listen := siteListen(project.Domain); listen != "" && servers != nil {
+			active = servers.serverByListen(listen) != ""
+		}
+		project.Config["active"] = active
+
+		projects = append(projects, project)
+	}
+
+	sort.Slice(projects, func(i, j int) bool { return projects[i].Name < projects[j].Name })
+	return projects, nil
+}
+
+// AddProject 根据项目配置生成站点配置并使其生效
+// Config["type"] 为 static 时以 Config["output_path"] 作为站点根目录，
+// Config["spa"] 为 true 时找不到的路径回退到 index.html；
+// Config["type"] 为 php 时以 Config["output_path"] 为根目录，通过 Config["fastcgi"] 交给 php-fpm 处理；
+// 否则反向代理到 UpstreamURL
+// Caddy 运行中时通过管理接口只替换该站点的路由，不重新加载整个配置；Enabled 为 false 时保存为停用的配置并移除路由
+func (c *Caddy) AddProject(project core.Project) error {
+	site := &Project{
+		Name:   project.Name,
+		Domain: project.Domain,
+	}
+	if site.Name == "" || strings.ContainsAny(site.Name, `/\`) {
+		return fmt.Errorf("无效的项目名称: %s", site.Name)
+	}
+	if site.Domain == "" {
+		return fmt.Errorf("项目 %s 缺少域名", site.Name)
+	}
+
+	switch projectType, _ := project.Config["type"].(string); projectType {
+	case "static":
+		site.Type = "static"
+		site.OutputDir, _ = project.Config["output_path"].(string)
+		site.SPA, _ = project.Config["spa"].(bool)
+		if site.OutputDir == "" {
+			return fmt.Errorf("静态站点缺少 output_path")
+		}
+	case "php":
+		site.Type = "php"
+		site.OutputDir, _ = project.Config["output_path"].(string)
+		site.FastCGI, _ = project.Config["fastcgi"].(string)
+		if site.OutputDir == "" || site.FastCGI == "" {
+			return fmt.Errorf("PHP 站点缺少 output_path 或 fastcgi")
+		}
+	default:
+		upstream, err := url.Parse(project.UpstreamURL)
+		if err != nil {
+			return fmt.Errorf("无效的上游地址 %s: %v", project.UpstreamURL, err)
+		}
+		port, err := strconv.Atoi(upstream.Port())
+		if err != nil {
+			return fmt.Errorf("上游地址 %s 缺少端口", project.UpstreamURL)
+		}
+		site.Port = port
+	}
+
+	content, err := c.RenderProjectConfig(site)
+	if err != nil {
+		return err
+	}
+	if err := c.EnsureCaddyfile(); err != nil {
+		return fmt.Errorf("确保 Caddyfile 存在失败: %v", err)
+	}
+
+	adminMu.Lock()
+	defer adminMu.Unlock()
+
+	if !project.Enabled {
+		if err := c.WriteConfig(c.GetDisabledConfigPath(site.Name), content); err != nil {
+			return err
+		}
+		if err := c.RemoveConfig(c.GetProjectConfigPath(site.Name)); err != nil {
+			return err
+		}
+		return c.removeSiteRoutes(site.Domain)
+	}
+
+	// 先通过 /adapt 转换站点配置，配置有误时不会写入配置目录，避免影响之后的重新加载
+	servers, err := c.admin.adapt(c.globalOptions() + "\n" + content)
+	if errors.Is(err, errAdminUnavailable) {
+		if err := c.WriteConfig(c.GetProjectConfigPath(site.Name), content); err != nil {
+			return err
+		}
+		c.RemoveConfig(c.GetDisabledConfigPath(site.Name))
+		return c.Start()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.WriteConfig(c.GetProjectConfigPath(site.Name), content); err != nil {
+		return err
+	}
+	if err := c.RemoveConfig(c.GetDisabledConfigPath(site.Name)); err != nil {
+		return err
+	}
+
+	return c.applySiteRoutes(site.Domain, servers)
+}
+
+// RemoveProject 删除项目的站点配置，并从运行中的配置中移除对应的路由
+func (c *Caddy) RemoveProject(projectName string) error {
+	adminMu.Lock()
+	defer adminMu.Unlock()
+
+	var domain string
+	for _, path := range []string{c.GetProjectConfigPath(projectName), c.GetDisabledConfigPath(projectName)} {
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("读取站点配置失败: %v", err)
+		}
+		domain = parseSiteConfig(string(content)).Domain
+		if err := c.RemoveConfig(path); err != nil {
+			return err
+		}
+	}
+	if domain == "" {
+		return fmt.Errorf("项目 %s 没有站点配置", projectName)
+	}
+
+	return c.removeSiteRoutes(domain)
+}
+
+// applySiteRoutes 把 adapt 得到的站点路由放入运行中的配置
+// 已有监听相同地址的服务器时替换其中同一域名的路由，否则把站点的服务器整体加入；
+// 只有端口的站点（如 :8080）独占该端口的服务器，直接替换整个服务器
+func (c *Caddy) applySiteRoutes(domain string, adapted httpServers) error {
+	running, err := c.admin.servers()
+	if err != nil {
+		return err
+	}
+
+	// 运行中的 Caddy 没有任何站点，直接加载 Caddyfile
+	if len(running) == 0 {
+		content, err := os.ReadFile(c.GetCaddyfilePath())
+		if err != nil {
+			return fmt.Errorf("读取 Caddyfile 失败: %v", err)
+		}
+		return c.admin.load(string(content))
+	}
+
+	if listen := siteListen(domain); listen != "" {
+		for name := range adapted {
+			if adapted.listen(name) != listen {
+				continue
+			}
+			if target := running.serverByListen(listen); target != "" {
+				return c.admin.do("PATCH", "/config/apps/http/servers/"+target, "", adapted[name], nil)
+			}
+			return c.admin.do("PUT", "/config/apps/http/servers/"+newServerName(running, name), "", adapted[name], nil)
+		}
+		return fmt.Errorf("站点配置中没有监听 %s 的服务器", listen)
+	}
+
+	for _, host := range siteHosts(domain) {
+		adaptedServer, route, ok := adapted.findRoute(host)
+		if !ok {
+			return fmt.Errorf("站点配置中没有 %s 的路由", host)
+		}
+
+		target := ""
+		for name := range running {
+			if running.listen(name) == adapted.listen(adaptedServer) {
+				target = name
+				break
+			}
+		}
+
+		if target != "" {
+			// 同一站点的多个域名共用一条路由，替换后重新读取以获得最新的下标
+			if err := c.admin.replaceRoute(running, target, host, route); err != nil {
+				return err
+			}
+		} else {
+			// 新的监听地址，先删除其他服务器中的旧路由，再加入 adapt 得到的服务器
+			for name := range running {
+				if err := c.admin.deleteRoutes(running, name, host); err != nil {
+					return err
+				}
+			}
+			name := newServerName(running, adaptedServer)
+			if err := c.admin.do("PUT", "/config/apps/http/servers/"+name, "", adapted[adaptedServer], nil); err != nil {
+				return err
+			}
+		}
+
+		if running, err = c.admin.servers(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeSiteRoutes 从运行中的配置删除域名的路由，只有端口的站点删除整个服务器，Caddy 未运行时不需要处理
+func (c *Caddy) removeSiteRoutes(domain string) error {
+	if listen := siteListen(domain); listen != "" {
+		running, err := c.admin.servers()
+		if errors.Is(err, errAdminUnavailable) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if target := running.serverByListen(listen); target != "" {
+			return c.admin.do("DELETE", "/config/apps/http/servers/"+target, "", nil, nil)
+		}
+		return nil
+	}
+
+	for _, host := range siteHosts(domain) {
+		running, err := c.admin.servers()
+		if errors.Is(err, errAdminUnavailable) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for name := range running {
+			if err := c.admin.deleteRoutes(running, name, host); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// newServerName 运行中的配置里未使用的服务器名称，优先使用 adapt 得到的名称
+func newServerName(running httpServers, name string) string {
+	for i := 0; running[name] != nil; i++ {
+		name = fmt.Sprintf("servon%d", i)
+	}
+	return name
+}
+
+// globalOptions 主 Caddyfile 开头的全局选项块，adapt 单个站点时需要带上，如 auto_https off
+func (c *Caddy) globalOptions() string {
+	content, err := os.ReadFile(c.GetCaddyfilePath())
+	if err != nil {
+		return ""
+	}
+
+	text := strings.TrimSpace(string(content))
+	if !strings.HasPrefix(text, "{") {
+		return ""
+	}
+	depth := 0
+	for i, ch := range text {
+		switch ch {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return text[:i+1]
+			}
+		}
+	}
+	return ""
+}
+
+// parseSiteConfig 从站点配置中解析域名、上游地址和站点类型
+// 支持 caddy_site.conf.tmpl 和 proxy.tmpl 生成的配置
+func parseSiteConfig(content string) core.Project {
+	project := core.Project{Config: map[string]interface{}{}}
+	siteType := ""
+
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if project.Domain == "" && strings.HasSuffix(line, "{") {
+			project.Domain = strings.TrimSpace(strings.TrimSuffix(line, "{"))
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "reverse_proxy":
+			if len(fields) > 1 {
+				upstream := fields[1]
+				if !strings.Contains(upstream, "://") {
+					upstream = "http://" + upstream
+				}
+				project.UpstreamURL = upstream
+				siteType = "proxy"
+			}
+		case "root":
+			project.Config["output_path"] = fields[len(fields)-1]
+		case "php_fastcgi":
+			if len(fields) > 1 {
+				project.Config["fastcgi"] = fields[1]
+			}
+			siteType = "php"
+		case "try_files":
+			project.Config["spa"] = true
+		case "file_server":
+			if siteType == "" {
+				siteType = "static"
+			}
+		}
+	}
+
+	project.Config["type"] = siteType
+	return project
+}
+
+// siteHosts 站点地址中的域名，如 "http://a.com:8080, b.com" 返回 a.com 和 b.com
+func siteHosts(address string) []string {
+	var hosts []string
+	for _, addr := range strings.FieldsFunc(address, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if i := strings.Index(addr, "://"); i >= 0 {
+			addr = addr[i+3:]
+		}
+		if i := strings.IndexAny(addr, "/"); i >= 0 {
+			addr = addr[:i]
+		}
+		if host, _, ok := strings.Cut(addr, ":"); ok {
+			addr = host
+		}
+		if addr != "" {
+			hosts = append(hosts, strings.ToLower(addr))
+		}
+	}
+	return hosts
+}
+
+// siteListen 只有端口的站点地址（如 :8080）对应的监听地址，地址中有域名时返回空
+func siteListen(address string) string {
+	addr := strings.TrimSpace(address)
+	if i := strings.Index(addr, "://"); i >= 0 {
+		addr = addr[i+3:]
+	}
+	if strings.HasPrefix(addr, ":") && !strings.ContainsAny(addr, ", ") {
+		return addr
+	}
+	return ""
+}
@@ -0,0 +1,432 @@
+package caddy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"servon/core"
+)
+
+// fakeServer 模拟的 HTTP 服务器配置，只保留监听地址和路由
+type fakeServer struct {
+	Listen []string          `json:"listen"`
+	Routes []json.RawMessage `json:"routes,omitempty"`
+}
+
+// fakeCaddyAdmin 模拟 Caddy 的管理接口，按站点块把 Caddyfile 转换为服务器和路由
+// 有域名的站点都放在监听 :443 的服务器中，以 host 匹配区分；只有端口的站点独占一个服务器
+type fakeCaddyAdmin struct {
+	mu      sync.Mutex
+	dir     string
+	servers map[string]*fakeServer
+	loads   int
+}
+
+func (f *fakeCaddyAdmin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body, _ := io.ReadAll(r.Body)
+	switch {
+	case r.Method == http.MethodPost && r.URL.Path == "/adapt":
+		writeJSON(w, map[string]interface{}{
+			"result": map[string]interface{}{"apps": map[string]interface{}{"http": map[string]interface{}{"servers": fakeAdapt(string(body))}}},
+		})
+	case r.Method == http.MethodPost && r.URL.Path == "/load":
+		f.servers = fakeAdapt(f.resolveImports(string(body)))
+		f.loads++
+	case strings.HasPrefix(r.URL.Path, "/config/apps/http/servers"):
+		if err := f.handleConfig(r.Method, strings.TrimPrefix(r.URL.Path, "/config/apps/http/servers"), body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]string{"error": err.Error()})
+			return
+		}
+		if r.Method == http.MethodGet {
+			writeJSON(w, f.servers)
+		}
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// handleConfig 处理 /config/apps/http/servers 下服务器和路由的增删改
+func (f *fakeCaddyAdmin) handleConfig(method, path string, body []byte) error {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if method == http.MethodGet && path == "" {
+		return nil
+	}
+
+	server, exists := f.servers[parts[0]]
+	switch {
+	case len(parts) == 1 && method == http.MethodPut:
+		if exists {
+			return fmt.Errorf("key already exists: %s", parts[0])
+		}
+		return f.setServer(parts[0], body)
+	case len(parts) == 1 && method == http.MethodPatch:
+		if !exists {
+			return fmt.Errorf("invalid traversal path: %s", parts[0])
+		}
+		return f.setServer(parts[0], body)
+	case len(parts) == 1 && method == http.MethodDelete:
+		if !exists {
+			return fmt.Errorf("invalid traversal path: %s", parts[0])
+		}
+		delete(f.servers, parts[0])
+		return nil
+	case !exists || parts[1] != "routes":
+		return fmt.Errorf("unsupported path: %s", path)
+	case len(parts) == 2 && method == http.MethodPut:
+		return json.Unmarshal(body, &server.Routes)
+	}
+
+	index, err := strconv.Atoi(parts[2])
+	if err != nil || index < 0 || index > len(server.Routes) {
+		return fmt.Errorf("invalid route index: %s", parts[2])
+	}
+	switch method {
+	case http.MethodPut:
+		server.Routes = append(server.Routes[:index], append([]json.RawMessage{json.RawMessage(body)}, server.Routes[index:]...)...)
+	case http.MethodDelete:
+		if index == len(server.Routes) {
+			return fmt.Errorf("invalid route index: %d", index)
+		}
+		server.Routes = append(server.Routes[:index], server.Routes[index+1:]...)
+	default:
+		return fmt.Errorf("unsupported method: %s", method)
+	}
+	return nil
+}
+
+func (f *fakeCaddyAdmin) setServer(name string, body []byte) error {
+	var server fakeServer
+	if err := json.Unmarshal(body, &server); err != nil {
+		return err
+	}
+	f.servers[name] = &server
+	return nil
+}
+
+// resolveImports 把 Caddyfile 中的 import 替换为配置目录中匹配的文件内容
+func (f *fakeCaddyAdmin) resolveImports(caddyfile string) string {
+	var b strings.Builder
+	for _, line := range strings.Split(caddyfile, "\n") {
+		pattern, ok := strings.CutPrefix(strings.TrimSpace(line), "import ")
+		if !ok {
+			b.WriteString(line + "\n")
+			continue
+		}
+		files, _ := filepath.Glob(filepath.Join(f.dir, pattern))
+		for _, file := range files {
+			content, _ := os.ReadFile(file)
+			b.Write(content)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// fakeAdapt 把每个站点块转换为一条路由，路由中记录站点块中的指令，用于检查路由来自哪个站点
+func fakeAdapt(caddyfile string) map[string]*fakeServer {
+	servers := map[string]*fakeServer{}
+	address, depth := "", 0
+	var directives []string
+
+	for _, raw := range strings.Split(caddyfile, "\n") {
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case depth == 0 && strings.HasSuffix(line, "{"):
+			address = strings.TrimSpace(strings.TrimSuffix(line, "{"))
+			directives = nil
+			depth++
+		case strings.HasSuffix(line, "{"):
+			depth++
+		case line == "}":
+			depth--
+			if depth == 0 && address != "" {
+				addFakeSite(servers, address, directives)
+				address = ""
+			}
+		default:
+			directives = append(directives, line)
+		}
+	}
+	return servers
+}
+
+func addFakeSite(servers map[string]*fakeServer, address string, directives []string) {
+	route := map[string]interface{}{
+		"handle": []interface{}{map[string]interface{}{"handler": "fake", "directives": directives}},
+	}
+	listen := ":443"
+	if strings.HasPrefix(address, ":") {
+		listen = address
+	} else {
+		route["match"] = []interface{}{map[string]interface{}{"host": strings.Split(address, ", ")}}
+	}
+	data, _ := json.Marshal(route)
+
+	for _, server := range servers {
+		if server.Listen[0] == listen {
+			server.Routes = append(server.Routes, data)
+			return
+		}
+	}
+	servers[fmt.Sprintf("srv%d", len(servers))] = &fakeServer{Listen: []string{listen}, Routes: []json.RawMessage{data}}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// startFakeCaddy 启动模拟的管理接口，返回使用临时配置目录并连接到它的 Caddy
+func startFakeCaddy(t *testing.T) (*Caddy, *fakeCaddyAdmin) {
+	dir := t.TempDir()
+	fake := &fakeCaddyAdmin{dir: dir, servers: map[string]*fakeServer{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	return &Caddy{BaseDir: dir, admin: newCaddyAdmin(server.URL)}, fake
+}
+
+// routesFor 返回运行中的配置里匹配 host 的路由，host 为空时返回监听 listen 的服务器的全部路由
+func (f *fakeCaddyAdmin) routesFor(listen, host string) []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	var routes []string
+	for _, server := range f.servers {
+		if server.Listen[0] != listen {
+			continue
+		}
+		for _, route := range server.Routes {
+			if host == "" || routeMatchesHost(route, host) {
+				routes = append(routes, string(route))
+			}
+		}
+	}
+	return routes
+}
+
+func staticProject(name, domain, outputPath string) core.Project {
+	return core.Project{
+		Name:    name,
+		Domain:  domain,
+		Enabled: true,
+		Config:  map[string]interface{}{"type": "static", "output_path": outputPath, "spa": true},
+	}
+}
+
+// TestAddProject 测试添加和替换站点，以及两个项目共用同一个监听地址
+func TestAddProject(t *testing.T) {
+	c, fake := startFakeCaddy(t)
+
+	// 运行中的 Caddy 没有任何站点时加载整个 Caddyfile
+	if err := c.AddProject(staticProject("blog", "blog.example.com", "/srv/blog/v1")); err != nil {
+		t.Fatal(err)
+	}
+	if fake.loads != 1 {
+		t.Errorf("Expected Caddyfile to be loaded once, got %d", fake.loads)
+	}
+	if _, err := os.Stat(c.GetProjectConfigPath("blog")); err != nil {
+		t.Errorf("Expected site config to be written, got %v", err)
+	}
+
+	// 第二个项目加入同一个服务器，不影响已有的站点
+	api := core.Project{Name: "api", Domain: "api.example.com", Enabled: true, UpstreamURL: "http://localhost:3000"}
+	if err := c.AddProject(api); err != nil {
+		t.Fatal(err)
+	}
+	if fake.loads != 1 {
+		t.Errorf("Expected routes to be added without reloading, got %d loads", fake.loads)
+	}
+	if routes := fake.routesFor(":443", ""); len(routes) != 2 {
+		t.Errorf("Expected 2 routes on one listener, got %d: %v", len(routes), routes)
+	}
+
+	// 重新部署时只替换该站点的路由
+	if err := c.AddProject(staticProject("blog", "blog.example.com", "/srv/blog/v2")); err != nil {
+		t.Fatal(err)
+	}
+	blog := fake.routesFor(":443", "blog.example.com")
+	if len(blog) != 1 || !strings.Contains(blog[0], "/srv/blog/v2") {
+		t.Errorf("Expected blog route to be replaced, got %v", blog)
+	}
+	apiRoutes := fake.routesFor(":443", "api.example.com")
+	if len(apiRoutes) != 1 || !strings.Contains(apiRoutes[0], "localhost:3000") {
+		t.Errorf("Expected api route to be kept, got %v", apiRoutes)
+	}
+
+	// 只有端口的站点独占服务器，重新部署时整体替换
+	for _, version := range []string{"v1", "v2"} {
+		if err := c.AddProject(staticProject("docs", ":8081", "/srv/docs/"+version)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	docs := fake.routesFor(":8081", "")
+	if len(docs) != 1 || !strings.Contains(docs[0], "/srv/docs/v2") {
+		t.Errorf("Expected port site to be replaced, got %v", docs)
+	}
+
+	if err := c.AddProject(staticProject("../bad", "bad.example.com", "/srv/bad")); err == nil {
+		t.Error("Expected invalid project name to be rejected")
+	}
+	if err := c.AddProject(staticProject("empty", "", "/srv/empty")); err == nil {
+		t.Error("Expected missing domain to be rejected")
+	}
+}
+
+// TestRemoveProject 测试删除站点和停用站点只移除该站点的路由
+func TestRemoveProject(t *testing.T) {
+	c, fake := startFakeCaddy(t)
+
+	for _, project := range []core.Project{
+		staticProject("blog", "blog.example.com", "/srv/blog"),
+		staticProject("shop", "shop.example.com", "/srv/shop"),
+		staticProject("docs", ":8081", "/srv/docs"),
+	} {
+		if err := c.AddProject(project); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.RemoveProject("blog"); err != nil {
+		t.Fatal(err)
+	}
+	if routes := fake.routesFor(":443", "blog.example.com"); len(routes) != 0 {
+		t.Errorf("Expected blog routes to be removed, got %v", routes)
+	}
+	if routes := fake.routesFor(":443", "shop.example.com"); len(routes) != 1 {
+		t.Errorf("Expected shop route to be kept, got %v", routes)
+	}
+	if _, err := os.Stat(c.GetProjectConfigPath("blog")); !os.IsNotExist(err) {
+		t.Error("Expected blog config to be removed")
+	}
+
+	if err := c.RemoveProject("docs"); err != nil {
+		t.Fatal(err)
+	}
+	if routes := fake.routesFor(":8081", ""); len(routes) != 0 {
+		t.Errorf("Expected port site server to be removed, got %v", routes)
+	}
+
+	// 停用的站点保留配置文件，但不再有路由
+	shop := staticProject("shop", "shop.example.com", "/srv/shop")
+	shop.Enabled = false
+	if err := c.AddProject(shop); err != nil {
+		t.Fatal(err)
+	}
+	if routes := fake.routesFor(":443", "shop.example.com"); len(routes) != 0 {
+		t.Errorf("Expected disabled site routes to be removed, got %v", routes)
+	}
+	if _, err := os.Stat(c.GetDisabledConfigPath("shop")); err != nil {
+		t.Errorf("Expected disabled config to be kept, got %v", err)
+	}
+
+	if err := c.RemoveProject("missing"); err == nil {
+		t.Error("Expected removing a project without config to fail")
+	}
+}
+
+// TestGetProjects 测试从配置目录中的站点文件解析项目
+func TestGetProjects(t *testing.T) {
+	c, _ := startFakeCaddy(t)
+
+	if err := c.AddProject(staticProject("blog", "blog.example.com", "/srv/blog/current")); err != nil {
+		t.Fatal(err)
+	}
+	laravel := core.Project{
+		Name:    "app",
+		Domain:  ":8080",
+		Enabled: true,
+		Config:  map[string]interface{}{"type": "php", "output_path": "/srv/app/current/public", "fastcgi": "unix//run/php/php8.2-fpm.sock"},
+	}
+	if err := c.AddProject(laravel); err != nil {
+		t.Fatal(err)
+	}
+
+	// proxy.tmpl 生成的代理配置和停用的配置
+	proxy, _ := c.RenderProxyConfig("api.example.com", "127.0.0.1:3000")
+	c.WriteConfig(c.GetProjectConfigPath("api"), proxy)
+	c.WriteConfig(c.GetDisabledConfigPath("old"), "old.example.com {\n    reverse_proxy localhost:4000\n}\n")
+
+	projects, err := c.GetProjects()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(projects) != 4 {
+		t.Fatalf("Expected 4 projects, got %d: %+v", len(projects), projects)
+	}
+
+	byName := map[string]core.Project{}
+	for _, project := range projects {
+		byName[project.Name] = project
+	}
+
+	cases := []struct {
+		name     string
+		domain   string
+		siteType string
+		enabled  bool
+		active   bool
+	}{
+		{"api", "api.example.com", "proxy", true, false},
+		{"app", ":8080", "php", true, true},
+		{"blog", "blog.example.com", "static", true, true},
+		{"old", "old.example.com", "proxy", false, false},
+	}
+	for _, want := range cases {
+		got, ok := byName[want.name]
+		if !ok {
+			t.Errorf("Expected project %s", want.name)
+			continue
+		}
+		if got.Domain != want.domain || got.Config["type"] != want.siteType || got.Enabled != want.enabled || got.Config["active"] != want.active {
+			t.Errorf("%s: expected %s %s enabled=%v active=%v, got %s %v enabled=%v active=%v",
+				want.name, want.domain, want.siteType, want.enabled, want.active,
+				got.Domain, got.Config["type"], got.Enabled, got.Config["active"])
+		}
+	}
+
+	if byName["api"].UpstreamURL != "http://127.0.0.1:3000" {
+		t.Errorf("Expected upstream to be parsed, got %q", byName["api"].UpstreamURL)
+	}
+	if byName["blog"].Config["output_path"] != "/srv/blog/current" || byName["blog"].Config["spa"] != true {
+		t.Errorf("Expected static root and spa to be parsed, got %v", byName["blog"].Config)
+	}
+	if byName["app"].Config["fastcgi"] != "unix//run/php/php8.2-fpm.sock" {
+		t.Errorf("Expected fastcgi to be parsed, got %v", byName["app"].Config)
+	}
+}
+
+// TestRenderStaticSite 测试单页应用的页面缓存头在 try_files 改写之后设置
+func TestRenderStaticSite(t *testing.T) {
+	c := &Caddy{BaseDir: t.TempDir()}
+
+	content, err := c.RenderProjectConfig(&Project{Name: "app", Domain: "app.example.com", Type: "static", OutputDir: "/srv/app", SPA: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	route := strings.Index(content, "route {")
+	tryFiles := strings.Index(content, "try_files {path}")
+	header := strings.Index(content, `header @html Cache-Control "no-cache"`)
+	if route < 0 || !(route < tryFiles && tryFiles < header) {
+		t.Errorf("Expected html header after try_files in a route block, got:\n%s", content)
+	}
+
+	project := parseSiteConfig(content)
+	if project.Config["type"] != "static" || project.Config["spa"] != true || project.Config["output_path"] != "/srv/app" {
+		t.Errorf("Expected rendered config to parse back, got %v", project.Config)
+	}
+}
@@ -13,6 +13,7 @@ func Setup(app *core.App) {
 	caddy := Caddy{
 		App:     app,
 		BaseDir: app.GetSoftwareRootFolder("caddy"),
+		admin:   newCaddyAdmin(DefaultAdminAddress),
 	}
 
 	app.RegisterGateway("caddy", &caddy)
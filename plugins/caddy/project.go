@@ -6,4 +6,6 @@ type Project struct {
 	Type      string
 	OutputDir string
 	Port      int
+	SPA       bool   // 静态站点找不到文件时回退到 index.html
+	FastCGI   string // PHP 站点的 php-fpm 地址，如 unix//run/php/php8.2-fpm.sock
 }
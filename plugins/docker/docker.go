@@ -0,0 +1,337 @@
+package docker
+
+import (
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"servon/core"
+	"strconv"
+	"strings"
+)
+
+type DockerPlugin struct {
+	info core.SoftwareInfo
+	*core.App
+}
+
+func Setup(app *core.App) {
+	docker := NewDockerPlugin(app)
+	app.RegisterService("docker", docker)
+	app.AddDeployer(NewDockerDeployer(app, docker))
+}
+
+func NewDockerPlugin(app *core.App) *DockerPlugin {
+	return &DockerPlugin{
+		App: app,
+		info: core.SoftwareInfo{
+			Name:        "docker",
+			Description: "Docker 容器运行时，服务即容器",
+		},
+	}
+}
+
+func (d *DockerPlugin) GetInfo() core.SoftwareInfo {
+	return d.info
+}
+
+// Install 通过 apt 安装 Docker 和 Compose 插件
+func (d *DockerPlugin) Install() error {
+	osType := d.GetOSType()
+
+	switch osType {
+	case core.Ubuntu, core.Debian:
+		fmt.Println("使用 APT 包管理器安装...")
+
+		if _, err := d.AptUpdate(); err != nil {
+			return err
+		}
+		if err := d.AptInstall("docker.io"); err != nil {
+			return err
+		}
+
+		// Ubuntu 中 Compose v2 的包名为 docker-compose-v2，Debian 中为 docker-compose
+		if err := d.AptInstall("docker-compose-v2"); err != nil {
+			fmt.Println("安装 docker-compose-v2 失败，尝试安装 docker-compose")
+			if err := d.AptInstall("docker-compose"); err != nil {
+				fmt.Printf("安装 Docker Compose 失败: %v\n", err)
+			}
+		}
+
+	case core.CentOS, core.RedHat:
+		errMsg := "暂不支持在 RHEL 系统上安装 Docker"
+		fmt.Printf("%s\n", errMsg)
+		return fmt.Errorf("%s", errMsg)
+
+	default:
+		errMsg := fmt.Sprintf("不支持的操作系统: %s", osType)
+		fmt.Printf("%s\n", errMsg)
+		return fmt.Errorf("%s", errMsg)
+	}
+
+	if !d.IsInstalled("docker.io") {
+		errMsg := "Docker 安装验证失败，未检测到已安装的包"
+		fmt.Printf("%s\n", errMsg)
+		return fmt.Errorf("%s", errMsg)
+	}
+
+	fmt.Println("Docker 安装完成")
+
+	return d.Start()
+}
+
+// Uninstall 卸载 Docker，镜像和数据卷保留在 /var/lib/docker
+func (d *DockerPlugin) Uninstall() error {
+	if err := d.AptRemove("docker.io"); err != nil {
+		return err
+	}
+
+	fmt.Println("Docker 卸载完成")
+	return nil
+}
+
+func (d *DockerPlugin) GetStatus() (map[string]string, error) {
+	if !d.IsInstalled("docker.io") {
+		return map[string]string{
+			"status":  "not_installed",
+			"version": "",
+		}, nil
+	}
+
+	// docker info 需要连接守护进程，成功即表示正在运行
+	version, err := exec.Command("docker", "info", "--format", "{{.ServerVersion}}").Output()
+	if err != nil {
+		return map[string]string{
+			"status":  "stopped",
+			"version": "",
+		}, nil
+	}
+
+	return map[string]string{
+		"status":  "running",
+		"version": strings.TrimSpace(string(version)),
+	}, nil
+}
+
+func (d *DockerPlugin) Start() error {
+	fmt.Println("Docker 开始启动")
+
+	err, output := d.RunShellWithSudo("systemctl", "enable", "--now", "docker")
+	if err != nil {
+		return fmt.Errorf("启动 Docker 失败: %v\n%s", err, output)
+	}
+
+	fmt.Println("Docker 启动成功")
+	return nil
+}
+
+func (d *DockerPlugin) Stop() error {
+	fmt.Println("Docker 开始停止")
+
+	err, output := d.RunShellWithSudo("systemctl", "stop", "docker")
+	if err != nil {
+		return fmt.Errorf("停止 Docker 失败: %v\n%s", err, output)
+	}
+
+	fmt.Println("Docker 停止成功")
+	return nil
+}
+
+func (d *DockerPlugin) Restart() error {
+	fmt.Println("重启 Docker")
+
+	err, output := d.RunShellWithSudo("systemctl", "restart", "docker")
+	if err != nil {
+		return fmt.Errorf("重启 Docker 失败: %v\n%s", err, output)
+	}
+	return nil
+}
+
+// 实现 Service 接口的方法，服务名即容器名
+
+func (d *DockerPlugin) StartService(serviceName string) error {
+	return d.container("启动", "start", serviceName)
+}
+
+func (d *DockerPlugin) StopService(serviceName string) error {
+	return d.container("停止", "stop", serviceName)
+}
+
+func (d *DockerPlugin) RestartService(serviceName string) error {
+	return d.container("重启", "restart", serviceName)
+}
+
+// container 对容器执行 docker start/stop/restart
+func (d *DockerPlugin) container(action, command, serviceName string) error {
+	fmt.Printf("%s容器: %s\n", action, serviceName)
+
+	err, output := d.RunShellWithSudo("docker", command, serviceName)
+	if err != nil {
+		return fmt.Errorf("%s容器 %s 失败: %v\n%s", action, serviceName, err, output)
+	}
+
+	fmt.Printf("容器 %s %s成功\n", serviceName, action)
+	return nil
+}
+
+// AddBackgroundService 以 command 为镜像在后台运行容器，args 为容器的命令参数，返回容器ID
+func (d *DockerPlugin) AddBackgroundService(serviceName string, command string, args []string, env []string) (string, error) {
+	fmt.Printf("添加容器: %s\n", serviceName)
+
+	runArgs := []string{"run", "-d", "--name", serviceName, "--restart", "unless-stopped"}
+	for _, e := range env {
+		runArgs = append(runArgs, "-e", e)
+	}
+	runArgs = append(runArgs, command)
+	runArgs = append(runArgs, args...)
+
+	err, output := d.RunShellWithSudo("docker", runArgs...)
+	if err != nil {
+		return "", fmt.Errorf("运行容器 %s 失败: %v\n%s", serviceName, err, output)
+	}
+
+	return strings.TrimSpace(output), nil
+}
+
+// StopBackgroundService 停止并删除容器
+func (d *DockerPlugin) StopBackgroundService(serviceName string) error {
+	fmt.Printf("删除容器: %s\n", serviceName)
+
+	err, output := d.RunShellWithSudo("docker", "rm", "-f", serviceName)
+	if err != nil {
+		return fmt.Errorf("删除容器 %s 失败: %v\n%s", serviceName, err, output)
+	}
+	return nil
+}
+
+func (d *DockerPlugin) GetLogs(serviceName string, lines int) (string, error) {
+	if lines <= 0 {
+		lines = 100 // 默认获取100行
+	}
+
+	// 容器的标准错误输出同样写入日志
+	output, err := exec.Command("docker", "logs", "--tail", strconv.Itoa(lines), serviceName).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("获取容器 %s 的日志失败: %v\n%s", serviceName, err, output)
+	}
+	return string(output), nil
+}
+
+func (d *DockerPlugin) IsRunning(serviceName string) (bool, error) {
+	output, err := exec.Command("docker", "inspect", "--type", "container", "--format", "{{.State.Running}}", serviceName).Output()
+	if err != nil {
+		return false, fmt.Errorf("容器 %s 不存在", serviceName)
+	}
+	return strings.TrimSpace(string(output)) == "true", nil
+}
+
+// GetServiceConfig 返回 docker inspect 的结果
+func (d *DockerPlugin) GetServiceConfig(serviceName string) (map[string]interface{}, error) {
+	return d.inspect(serviceName)
+}
+
+// UpdateServiceConfig 通过 docker update 修改运行中容器的重启策略和资源限制
+// 支持的配置项: restart、memory、cpus
+func (d *DockerPlugin) UpdateServiceConfig(serviceName string, config map[string]interface{}) error {
+	args := []string{"update"}
+	for _, key := range []string{"restart", "memory", "cpus"} {
+		if value, ok := config[key]; ok {
+			args = append(args, "--"+key, fmt.Sprintf("%v", value))
+		}
+	}
+	if len(args) == 1 {
+		return fmt.Errorf("没有可更新的配置项，支持: restart、memory、cpus")
+	}
+	args = append(args, serviceName)
+
+	err, output := d.RunShellWithSudo("docker", args...)
+	if err != nil {
+		return fmt.Errorf("更新容器 %s 失败: %v\n%s", serviceName, err, output)
+	}
+	return nil
+}
+
+// GetResourceUsage 通过 docker stats 获取容器的资源使用情况
+func (d *DockerPlugin) GetResourceUsage(serviceName string) (map[string]interface{}, error) {
+	running, err := d.IsRunning(serviceName)
+	if err != nil {
+		return nil, err
+	}
+	if !running {
+		return map[string]interface{}{
+			"status": "stopped",
+			"cpu":    0,
+			"memory": 0,
+		}, nil
+	}
+
+	output, err := exec.Command("docker", "stats", "--no-stream", "--format", "{{json .}}", serviceName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("获取资源使用情况失败: %v", err)
+	}
+
+	var stats struct {
+		CPUPerc  string
+		MemUsage string
+		MemPerc  string
+		NetIO    string
+		BlockIO  string
+		PIDs     string
+	}
+	if err := json.Unmarshal(output, &stats); err != nil {
+		return nil, fmt.Errorf("解析 docker stats 输出失败: %v", err)
+	}
+
+	return map[string]interface{}{
+		"status":       "running",
+		"cpu":          stats.CPUPerc,
+		"memory":       stats.MemPerc,
+		"memory_usage": stats.MemUsage,
+		"net_io":       stats.NetIO,
+		"block_io":     stats.BlockIO,
+		"pids":         stats.PIDs,
+	}, nil
+}
+
+func (d *DockerPlugin) GetServiceDetails(serviceName string) (map[string]interface{}, error) {
+	info, err := d.inspect(serviceName)
+	if err != nil {
+		return nil, err
+	}
+
+	state, _ := info["State"].(map[string]interface{})
+	config, _ := info["Config"].(map[string]interface{})
+	network, _ := info["NetworkSettings"].(map[string]interface{})
+
+	details := map[string]interface{}{
+		"name":    serviceName,
+		"id":      info["Id"],
+		"created": info["Created"],
+	}
+	if state != nil {
+		details["status"] = state["Status"]
+		details["running"] = state["Running"]
+		details["started_at"] = state["StartedAt"]
+		details["restart_count"] = info["RestartCount"]
+	}
+	if config != nil {
+		details["image"] = config["Image"]
+	}
+	if network != nil {
+		details["ports"] = network["Ports"]
+	}
+	return details, nil
+}
+
+// inspect 读取容器的 docker inspect 结果
+func (d *DockerPlugin) inspect(serviceName string) (map[string]interface{}, error) {
+	output, err := exec.Command("docker", "inspect", "--type", "container", serviceName).Output()
+	if err != nil {
+		return nil, fmt.Errorf("容器 %s 不存在", serviceName)
+	}
+
+	var result []map[string]interface{}
+	if err := json.Unmarshal(output, &result); err != nil || len(result) == 0 {
+		return nil, fmt.Errorf("解析容器 %s 的信息失败", serviceName)
+	}
+	return result[0], nil
+}
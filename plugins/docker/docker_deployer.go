@@ -0,0 +1,368 @@
+package docker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"servon/core"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort servon.yaml 未配置端口时映射到主机的端口
+const DefaultPort = 8080
+
+// imageFile 发布版本中记录镜像的文件，服务启动时运行 current 中记录的镜像
+const imageFile = "image"
+
+// 仓库中可能存在的 Compose 文件，按 docker compose 的查找顺序排列
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+// 镜像名和容器名中不允许的字符
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9_.-]+`)
+
+// DockerDeployer 构建并运行仓库中的 Dockerfile 或 Compose 项目
+// 容器由后台服务以前台方式运行，回滚时切换 current 并重启服务即可运行上一个版本
+type DockerDeployer struct {
+	*core.App
+	docker *DockerPlugin
+}
+
+func NewDockerDeployer(app *core.App, docker *DockerPlugin) *DockerDeployer {
+	return &DockerDeployer{
+		App:    app,
+		docker: docker,
+	}
+}
+
+func (d *DockerDeployer) GetName() string {
+	return "docker"
+}
+
+func (d *DockerDeployer) Deploy(ctx *core.DeployContext) error {
+	ctx.Printf("开始部署 Docker 项目: %s\n", ctx.ProjectName)
+
+	if err := d.ensureDocker(ctx); err != nil {
+		return err
+	}
+
+	dockerfile := ctx.Config.Docker.Dockerfile
+	if dockerfile == "" {
+		dockerfile = "Dockerfile"
+	}
+	if fileExists(filepath.Join(ctx.WorkDir, dockerfile)) {
+		return d.deployImage(ctx, dockerfile)
+	}
+
+	for _, name := range composeFiles {
+		if fileExists(filepath.Join(ctx.WorkDir, name)) {
+			return d.deployCompose(ctx)
+		}
+	}
+
+	ctx.Printf("未找到 %s 或 Compose 文件\n", dockerfile)
+	return fmt.Errorf("未找到 %s 或 Compose 文件", dockerfile)
+}
+
+// ensureDocker Docker 未安装时通过 docker 插件安装，守护进程未运行时启动
+func (d *DockerDeployer) ensureDocker(ctx *core.DeployContext) error {
+	if _, err := exec.LookPath("docker"); err != nil {
+		ctx.Println("未找到 docker，开始安装")
+		if err := d.docker.Install(); err != nil {
+			ctx.Printf("安装 Docker 失败: %v\n", err)
+			return fmt.Errorf("安装 Docker 失败: %v", err)
+		}
+	}
+
+	status, err := d.docker.GetStatus()
+	if err == nil && status["status"] == "running" {
+		return nil
+	}
+
+	ctx.Println("Docker 未运行，开始启动")
+	if err := d.docker.Start(); err != nil {
+		ctx.Println(err)
+		return err
+	}
+	return nil
+}
+
+// deployImage 构建以提交 SHA 为标签的镜像，并以后台服务运行容器
+func (d *DockerDeployer) deployImage(ctx *core.DeployContext, dockerfile string) error {
+	projectName, workDir, targetDir := ctx.ProjectName, ctx.WorkDir, ctx.TargetDir
+	config := ctx.Config
+
+	repository := imageRepository(projectName)
+	image := repository + ":" + imageTag(ctx)
+
+	ctx.Printf("开始构建镜像: %s\n", image)
+	err := ctx.RunCommand(workDir, "docker", "build",
+		"-f", dockerfile,
+		"-t", image,
+		"--label", "servon.project="+projectName,
+		".",
+	)
+	if err != nil {
+		ctx.Printf("构建镜像失败: %v\n", err)
+		return fmt.Errorf("构建镜像失败: %v", err)
+	}
+
+	hostPort := config.PortOrDefault(DefaultPort)
+	containerPort := config.Docker.ContainerPort
+	if containerPort == 0 {
+		containerPort = exposedPort(filepath.Join(workDir, dockerfile))
+	}
+	if containerPort == 0 {
+		containerPort = hostPort
+	}
+	ctx.Port = hostPort
+
+	// 发布版本只记录镜像，current 切换后服务运行的就是该版本的镜像
+	stageDir := filepath.Join(workDir, ".servon-release")
+	if err := os.MkdirAll(stageDir, 0755); err != nil {
+		return fmt.Errorf("创建发布目录失败: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(stageDir, imageFile), []byte(image+"\n"), 0644); err != nil {
+		return fmt.Errorf("写入镜像信息失败: %v", err)
+	}
+
+	releaseDir, err := d.PublishRelease(targetDir, ctx.ID, stageDir)
+	if err != nil {
+		ctx.Printf("创建发布版本失败: %v\n", err)
+		return fmt.Errorf("创建发布版本失败: %v", err)
+	}
+
+	volumes, err := resolveVolumes(targetDir, config.Docker.Volumes)
+	if err != nil {
+		ctx.Println(err)
+		return err
+	}
+
+	if err := d.ActivateRelease(targetDir, ctx.ID); err != nil {
+		ctx.Printf("切换版本失败: %v\n", err)
+		return fmt.Errorf("切换版本失败: %v", err)
+	}
+
+	// 环境变量通过服务配置传给 docker 命令，docker run 中只写变量名，避免值出现在命令行中
+	env := append([]string{fmt.Sprintf("PORT=%d", containerPort)}, config.EnvList()...)
+	container := containerName(projectName)
+	currentLink := filepath.Join(targetDir, "current")
+
+	run := []string{"docker", "run", "--rm", "--name", container,
+		"--label", "servon.project=" + projectName,
+		"-p", fmt.Sprintf("%d:%d", hostPort, containerPort),
+	}
+	for _, port := range config.Docker.Ports {
+		run = append(run, "-p", port)
+	}
+	for _, volume := range volumes {
+		run = append(run, "-v", volume)
+	}
+	for _, e := range env {
+		name, _, _ := strings.Cut(e, "=")
+		run = append(run, "-e", name)
+	}
+
+	// 镜像名在启动时从 current 中读取，回滚后无需修改服务配置，只有这一处命令替换不加引号
+	currentImage := fmt.Sprintf("$(cat %s)", shellQuote(filepath.Join(currentLink, imageFile)))
+
+	// 上次异常退出时残留的同名容器会导致 docker run 失败，启动前先删除
+	script := fmt.Sprintf("docker rm -f %s >/dev/null 2>&1; exec %s %s", shellQuote(container), shellJoin(run), currentImage)
+	spec := core.ServiceSpec{
+		Name:       projectName,
+		Command:    "sh",
+		Args:       []string{"-c", script},
+		WorkingDir: targetDir,
+	}
+	spec.AddEnv(env...)
+	serviceFilePath, err := d.SaveBackgroundService(spec)
+	if err != nil {
+		ctx.Printf("配置后台服务失败: %v\n", err)
+		return fmt.Errorf("配置后台服务失败: %v", err)
+	}
+
+	d.pruneImages(ctx, repository)
+
+	ctx.Println()
+	ctx.Println("✨ Docker 项目部署成功！")
+	ctx.Println()
+	ctx.Printf("🐳 镜像: %s\n", image)
+	ctx.Printf("📦 发布版本: %s\n", releaseDir)
+	ctx.Printf("📁 服务文件路径: %s\n", serviceFilePath)
+	ctx.Printf("🌐 快速打开: http://127.0.0.1:%d\n", hostPort)
+	if config.Domain != "" {
+		ctx.Printf("🌐 域名: %s\n", config.Domain)
+	}
+	ctx.Println()
+	return nil
+}
+
+// deployCompose 将仓库发布为新版本，在其中构建 Compose 项目并以后台服务运行
+// Compose 的镜像按项目命名，回滚时服务重启会从 current 中的代码重新构建
+func (d *DockerDeployer) deployCompose(ctx *core.DeployContext) error {
+	projectName, targetDir, config := ctx.ProjectName, ctx.TargetDir, ctx.Config
+	composeProject := containerName(projectName)
+
+	releaseDir, err := d.CreateRelease(targetDir, ctx.WorkDir)
+	if err != nil {
+		ctx.Printf("创建发布版本失败: %v\n", err)
+		return fmt.Errorf("创建发布版本失败: %v", err)
+	}
+
+	ctx.Println("开始构建 Compose 项目")
+	if err := ctx.RunCommand(releaseDir, "docker", "compose", "-p", composeProject, "build"); err != nil {
+		ctx.Printf("构建失败: %v\n", err)
+		return fmt.Errorf("构建失败: %v", err)
+	}
+
+	if err := d.ActivateRelease(targetDir, ctx.ID); err != nil {
+		ctx.Printf("切换版本失败: %v\n", err)
+		return fmt.Errorf("切换版本失败: %v", err)
+	}
+
+	// Compose 文件自行声明端口，servon.yaml 中的 port 仅用于健康检查
+	ctx.Port = config.Port
+
+	currentLink := filepath.Join(targetDir, "current")
+	script := fmt.Sprintf("cd %s && exec docker compose -p %s up --build --remove-orphans", shellQuote(currentLink), shellQuote(composeProject))
+	spec := core.ServiceSpec{
+		Name:       projectName,
+		Command:    "sh",
+		Args:       []string{"-c", script},
+		WorkingDir: targetDir,
+	}
+	spec.AddEnv(config.EnvList()...)
+	serviceFilePath, err := d.SaveBackgroundService(spec)
+	if err != nil {
+		ctx.Printf("配置后台服务失败: %v\n", err)
+		return fmt.Errorf("配置后台服务失败: %v", err)
+	}
+
+	ctx.Println()
+	ctx.Println("✨ Docker Compose 项目部署成功！")
+	ctx.Println()
+	ctx.Printf("📦 发布版本: %s\n", releaseDir)
+	ctx.Printf("📁 current（软链接） 路径: %s\n", currentLink)
+	ctx.Printf("📁 服务文件路径: %s\n", serviceFilePath)
+	ctx.Println()
+	return nil
+}
+
+// pruneImages 删除项目中不再被任何发布版本使用的镜像，保留上一个版本的镜像用于回滚
+func (d *DockerDeployer) pruneImages(ctx *core.DeployContext, repository string) {
+	used := make(map[string]bool)
+	files, _ := filepath.Glob(filepath.Join(ctx.TargetDir, "releases", "*", imageFile))
+	for _, file := range files {
+		if content, err := os.ReadFile(file); err == nil {
+			used[strings.TrimSpace(string(content))] = true
+		}
+	}
+
+	output, err := exec.CommandContext(ctx.Context, "docker", "images", repository, "--format", "{{.Repository}}:{{.Tag}}").Output()
+	if err != nil {
+		ctx.Printf("获取镜像列表失败: %v\n", err)
+		return
+	}
+
+	var unused []string
+	for _, image := range strings.Fields(string(output)) {
+		if !used[image] {
+			unused = append(unused, image)
+		}
+	}
+	sort.Strings(unused)
+
+	for _, image := range unused {
+		ctx.Printf("删除旧镜像: %s\n", image)
+		if err := ctx.RunCommand("", "docker", "rmi", image); err != nil {
+			ctx.Printf("删除镜像 %s 失败: %v\n", image, err)
+		}
+	}
+}
+
+// imageRepository 项目的镜像仓库名，镜像名只能使用小写字母
+func imageRepository(projectName string) string {
+	return "servon/" + sanitizeName(projectName)
+}
+
+// containerName 项目的容器名，同时作为 Compose 的项目名
+func containerName(projectName string) string {
+	return "servon-" + sanitizeName(projectName)
+}
+
+// shellQuote 用单引号包裹字符串，供 sh 解析，volume 等配置中的空格和特殊字符不会被展开
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// shellJoin 将每个参数加引号后拼接为 sh 命令行
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = shellQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func sanitizeName(name string) string {
+	name = invalidNameChars.ReplaceAllString(strings.ToLower(name), "-")
+	return strings.Trim(name, "-_.")
+}
+
+// imageTag 使用提交 SHA 的前 12 位作为镜像标签，没有提交信息时使用部署ID
+func imageTag(ctx *core.DeployContext) string {
+	if len(ctx.Commit) >= 12 {
+		return ctx.Commit[:12]
+	}
+	if ctx.Commit != "" {
+		return ctx.Commit
+	}
+	return ctx.ID
+}
+
+// exposedPort 读取 Dockerfile 中第一个 EXPOSE 的端口，没有时返回 0
+func exposedPort(dockerfile string) int {
+	f, err := os.Open(dockerfile)
+	if err != nil {
+		return 0
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "EXPOSE") {
+			continue
+		}
+		port, _, _ := strings.Cut(fields[1], "/")
+		if n, err := strconv.Atoi(port); err == nil {
+			return n
+		}
+	}
+	return 0
+}
+
+// resolveVolumes 将卷的相对主机路径解析到项目的 shared 目录，并确保目录存在
+func resolveVolumes(targetDir string, volumes []string) ([]string, error) {
+	resolved := make([]string, 0, len(volumes))
+	for _, volume := range volumes {
+		host, rest, _ := strings.Cut(volume, ":")
+		if !filepath.IsAbs(host) {
+			host = filepath.Join(targetDir, "shared", host)
+			if err := os.MkdirAll(host, 0755); err != nil {
+				return nil, fmt.Errorf("创建卷目录失败: %v", err)
+			}
+		}
+		resolved = append(resolved, host+":"+rest)
+	}
+	return resolved, nil
+}
+
+func fileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
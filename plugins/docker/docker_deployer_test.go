@@ -0,0 +1,34 @@
+package docker
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// TestShellJoin 测试参数中的空格和 shell 特殊字符原样传给命令
+func TestShellJoin(t *testing.T) {
+	args := []string{
+		"/data/my volume:/data",
+		"a; touch /tmp/servon-injected",
+		"$(id)",
+		"`id`",
+		"it's",
+		"",
+	}
+
+	out, err := exec.Command("sh", "-c", "printf '%s\\n' "+shellJoin(args)).Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n")
+	if len(got) != len(args) {
+		t.Fatalf("Expected %d arguments, got %d: %q", len(args), len(got), got)
+	}
+	for i := range args {
+		if got[i] != args[i] {
+			t.Errorf("Expected %q, got %q", args[i], got[i])
+		}
+	}
+}
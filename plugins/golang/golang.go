@@ -0,0 +1,131 @@
+package golang
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/backend"
+	"strings"
+)
+
+func Setup(app *core.App) {
+	app.AddDeployer(NewGoDeployer(app))
+}
+
+// GoDeployer 将 Go 项目编译为二进制文件，作为后台服务运行
+type GoDeployer struct {
+	*backend.Deployer
+}
+
+func NewGoDeployer(app *core.App) *GoDeployer {
+	return &GoDeployer{
+		Deployer: backend.NewDeployer(app, "go", "Go"),
+	}
+}
+
+func (d *GoDeployer) Deploy(ctx *core.DeployContext) error {
+	projectName, workDir, config := ctx.ProjectName, ctx.WorkDir, ctx.Config
+
+	ctx.Printf("开始部署 Go 项目: %s\n", projectName)
+
+	if err := d.EnsurePackages(ctx, "go", "golang-go"); err != nil {
+		return err
+	}
+
+	install := []string{"go", "mod", "download"}
+	if config.Install != "" {
+		install = []string{"sh", "-c", config.Install}
+	}
+	if err := d.Run(ctx, workDir, "下载依赖", install...); err != nil {
+		return err
+	}
+
+	// 配置了 build 时由构建命令决定产物，启动命令也需要在 servon.yaml 中配置
+	start := ""
+	if config.Build != "" {
+		if err := d.Run(ctx, workDir, "构建", "sh", "-c", config.Build); err != nil {
+			return err
+		}
+	} else {
+		pkg, err := findMainPackage(workDir, projectName)
+		if err != nil {
+			ctx.Println(err)
+			return err
+		}
+
+		binary := filepath.Join("bin", projectName)
+		ctx.Printf("编译 main 包: %s\n", pkg)
+		if err := d.Run(ctx, workDir, "构建", "go", "build", "-trimpath", "-ldflags=-s -w", "-o", binary, pkg); err != nil {
+			return err
+		}
+		start = "./" + binary
+	}
+
+	releaseDir, err := d.Release(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.Serve(ctx, releaseDir, start)
+}
+
+// findMainPackage 查找要编译的 main 包：优先根目录，其次 cmd/<项目名>，
+// cmd 下只有一个目录时使用该目录
+func findMainPackage(workDir, projectName string) (string, error) {
+	if isMainPackage(workDir) {
+		return ".", nil
+	}
+
+	if isMainPackage(filepath.Join(workDir, "cmd", projectName)) {
+		return "./cmd/" + projectName, nil
+	}
+
+	entries, _ := os.ReadDir(filepath.Join(workDir, "cmd"))
+	var candidates []string
+	for _, entry := range entries {
+		if entry.IsDir() && isMainPackage(filepath.Join(workDir, "cmd", entry.Name())) {
+			candidates = append(candidates, "./cmd/"+entry.Name())
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	if len(candidates) > 1 {
+		return "", fmt.Errorf("找到多个 main 包 %v，请在 servon.yaml 中配置 build 和 start", candidates)
+	}
+
+	return "", fmt.Errorf("未找到 main 包，请在 servon.yaml 中配置 build 和 start")
+}
+
+// isMainPackage 判断目录中的 Go 文件是否声明了 package main
+func isMainPackage(dir string) bool {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.go"))
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		if packageName(file) == "main" {
+			return true
+		}
+	}
+	return false
+}
+
+// packageName 读取 Go 文件的包名
+func packageName(file string) string {
+	f, err := os.Open(file)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if name, ok := strings.CutPrefix(strings.TrimSpace(scanner.Text()), "package "); ok {
+			return strings.TrimSpace(strings.SplitN(name, "//", 2)[0])
+		}
+	}
+	return ""
+}
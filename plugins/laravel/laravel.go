@@ -0,0 +1,543 @@
+package laravel
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/nodeapp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// DefaultPort 未配置域名时 Caddy 监听的端口
+const DefaultPort = 80
+
+// WebUser php-fpm 进程使用的用户，storage 和队列 worker 都使用该用户
+const WebUser = "www-data"
+
+// 部署 Laravel 需要的系统软件包
+var phpPackages = []string{
+	"php-fpm", "php-cli", "php-mbstring", "php-xml", "php-curl", "php-zip",
+	"php-bcmath", "php-intl", "php-mysql", "php-pgsql", "php-sqlite3",
+	"unzip", "composer",
+}
+
+// storage 中 Laravel 运行时需要存在的目录
+var storageDirs = []string{
+	"app/public",
+	"framework/cache/data",
+	"framework/sessions",
+	"framework/views",
+	"logs",
+}
+
+func Setup(app *core.App) {
+	app.AddDeployer(NewLaravelDeployer(app))
+}
+
+// LaravelDeployer 通过 php-fpm 运行 Laravel 项目，由 Caddy 的 php_fastcgi 提供服务
+// storage/ 和 .env 放在项目的 shared 目录中，所有版本共用
+type LaravelDeployer struct {
+	*core.App
+}
+
+func NewLaravelDeployer(app *core.App) *LaravelDeployer {
+	return &LaravelDeployer{App: app}
+}
+
+func (d *LaravelDeployer) GetName() string {
+	return "laravel"
+}
+
+func (d *LaravelDeployer) Deploy(ctx *core.DeployContext) error {
+	projectName, workDir, targetDir := ctx.ProjectName, ctx.WorkDir, ctx.TargetDir
+	config := ctx.Config
+
+	ctx.Printf("开始部署 Laravel 项目: %s\n", projectName)
+
+	// 未配置域名时按端口提供服务，构建前确认地址未被其他项目占用，避免替换其他项目的站点
+	address := config.Domain
+	if address == "" {
+		ctx.Port = config.PortOrDefault(DefaultPort)
+		address = fmt.Sprintf(":%d", ctx.Port)
+	}
+
+	gateway, err := d.GetGateway("caddy")
+	if err != nil {
+		ctx.Printf("获取 Caddy 网关失败: %v\n", err)
+		return fmt.Errorf("获取 Caddy 网关失败: %v", err)
+	}
+	if err := nodeapp.CheckSiteAddress(gateway, projectName, address); err != nil {
+		ctx.Println(err)
+		return err
+	}
+
+	if err := d.ensurePHP(ctx); err != nil {
+		return err
+	}
+
+	fpm, err := findPHPFPM()
+	if err != nil {
+		ctx.Println(err)
+		return err
+	}
+	ctx.Printf("使用 %s\n", fpm.Service)
+
+	if err := d.build(ctx); err != nil {
+		return err
+	}
+
+	releaseDir, err := d.CreateRelease(targetDir, workDir)
+	if err != nil {
+		ctx.Printf("创建发布版本失败: %v\n", err)
+		return fmt.Errorf("创建发布版本失败: %v", err)
+	}
+
+	if err := d.linkShared(ctx, releaseDir); err != nil {
+		ctx.Println(err)
+		return err
+	}
+
+	if err := d.prepareRelease(ctx, releaseDir); err != nil {
+		return err
+	}
+
+	if err := d.ActivateRelease(targetDir, filepath.Base(releaseDir)); err != nil {
+		ctx.Printf("切换版本失败: %v\n", err)
+		return fmt.Errorf("切换版本失败: %v", err)
+	}
+
+	if err := reloadFPM(ctx, fpm); err != nil {
+		return err
+	}
+
+	currentLink := filepath.Join(targetDir, "current")
+	err = gateway.AddProject(core.Project{
+		Name:    projectName,
+		Domain:  address,
+		Enabled: true,
+		Config: map[string]interface{}{
+			"type":        "php",
+			"output_path": filepath.Join(currentLink, "public"),
+			"fastcgi":     "unix/" + fpm.Socket,
+		},
+	})
+	if err != nil {
+		ctx.Printf("配置 Caddy 站点失败: %v\n", err)
+		return fmt.Errorf("配置 Caddy 站点失败: %v", err)
+	}
+
+	workers, err := d.saveWorkers(ctx, currentLink)
+	if err != nil {
+		return err
+	}
+
+	ctx.Println()
+	ctx.Println("✨ Laravel 项目部署成功！")
+	ctx.Println()
+	ctx.Printf("📦 发布版本: %s\n", releaseDir)
+	ctx.Printf("📁 current（软链接） 路径: %s\n", currentLink)
+	ctx.Printf("📁 共享目录: %s\n", sharedDir(targetDir))
+	for _, worker := range workers {
+		ctx.Printf("⚙️  队列 worker: %s\n", worker)
+	}
+	ctx.Printf("🌐 站点地址: %s\n", address)
+	ctx.Println()
+	return nil
+}
+
+// AfterRollback 回滚后重新加载 php-fpm，队列 worker 由回滚流程统一重启
+func (d *LaravelDeployer) AfterRollback(ctx *core.DeployContext) error {
+	fpm, err := findPHPFPM()
+	if err != nil {
+		ctx.Println(err)
+		return err
+	}
+	return reloadFPM(ctx, fpm)
+}
+
+// reloadFPM 重新加载 php-fpm，清除 opcache 中旧版本的文件路径，未运行时启动
+func reloadFPM(ctx *core.DeployContext, fpm *PHPFPM) error {
+	if err := ctx.RunCommand("", "service", fpm.Service, "reload"); err != nil {
+		ctx.Printf("重新加载 %s 失败，尝试启动: %v\n", fpm.Service, err)
+		if err := ctx.RunCommand("", "service", fpm.Service, "start"); err != nil {
+			ctx.Printf("启动 %s 失败: %v\n", fpm.Service, err)
+			return fmt.Errorf("启动 %s 失败: %v", fpm.Service, err)
+		}
+	}
+	ctx.Printf("已重新加载 %s\n", fpm.Service)
+	return nil
+}
+
+// ensurePHP php-fpm 或 Composer 不可用时通过 apt 安装
+func (d *LaravelDeployer) ensurePHP(ctx *core.DeployContext) error {
+	_, phpErr := exec.LookPath("php")
+	_, composerErr := exec.LookPath("composer")
+	if phpErr == nil && composerErr == nil && d.AptIsInstalled("php-fpm") {
+		return nil
+	}
+
+	ctx.Println("未找到 php-fpm 或 Composer，开始安装")
+	if _, err := d.AptUpdate(); err != nil {
+		ctx.Printf("更新软件包索引失败: %v\n", err)
+		return err
+	}
+	if err := d.AptInstall(phpPackages...); err != nil {
+		ctx.Printf("安装 PHP 失败: %v\n", err)
+		return fmt.Errorf("安装 PHP 失败: %v", err)
+	}
+	return nil
+}
+
+// build 安装 Composer 依赖并构建前端资源，servon.yaml 中的 install 和 build 会替换默认命令
+func (d *LaravelDeployer) build(ctx *core.DeployContext) error {
+	workDir, config := ctx.WorkDir, ctx.Config
+
+	install := []string{"composer", "install", "--no-dev", "--no-interaction", "--prefer-dist", "--optimize-autoloader"}
+	if config.Install != "" {
+		install = []string{"sh", "-c", config.Install}
+	}
+
+	ctx.Println("开始安装 Composer 依赖")
+	if err := ctx.RunCommand(workDir, install[0], install[1:]...); err != nil {
+		ctx.Printf("安装依赖失败: %v\n", err)
+		return fmt.Errorf("安装依赖失败: %v", err)
+	}
+
+	if config.Build != "" {
+		ctx.Println("开始构建")
+		if err := ctx.RunShell(workDir, config.Build); err != nil {
+			ctx.Printf("构建失败: %v\n", err)
+			return fmt.Errorf("构建失败: %v", err)
+		}
+		return nil
+	}
+
+	if !nodeapp.FileExists(filepath.Join(workDir, "package.json")) {
+		return nil
+	}
+
+	// 使用 Vite 等工具的前端资源，构建结果在 public/build 中随版本发布
+	packageManager := nodeapp.DetectPackageManager(workDir)
+	if _, err := exec.LookPath(packageManager); err != nil {
+		ctx.Printf("未找到 %s，开始安装\n", packageManager)
+		if err := d.SoftManager.Install(packageManager); err != nil {
+			ctx.Printf("安装 %s 失败: %v\n", packageManager, err)
+			return fmt.Errorf("安装 %s 失败: %v", packageManager, err)
+		}
+	}
+
+	ctx.Println("开始构建前端资源")
+	for _, command := range [][]string{
+		nodeapp.InstallCommand(packageManager, workDir),
+		nodeapp.BuildCommand(packageManager),
+	} {
+		if err := ctx.RunCommand(workDir, command[0], command[1:]...); err != nil {
+			ctx.Printf("构建前端资源失败: %v\n", err)
+			return fmt.Errorf("构建前端资源失败: %v", err)
+		}
+	}
+	return nil
+}
+
+// sharedDir 所有版本共用的目录
+func sharedDir(targetDir string) string {
+	return filepath.Join(targetDir, "shared")
+}
+
+// linkShared 将发布版本中的 storage/ 和 .env 替换为指向 shared 目录的软链接
+// 第一次部署时用仓库中的 storage/ 初始化共享目录，用 .env.example 初始化 .env
+func (d *LaravelDeployer) linkShared(ctx *core.DeployContext, releaseDir string) error {
+	shared := sharedDir(ctx.TargetDir)
+	sharedStorage := filepath.Join(shared, "storage")
+	sharedEnv := filepath.Join(shared, ".env")
+
+	if err := os.MkdirAll(shared, 0755); err != nil {
+		return fmt.Errorf("创建共享目录失败: %v", err)
+	}
+
+	if _, err := os.Stat(sharedStorage); os.IsNotExist(err) {
+		if src := filepath.Join(ctx.WorkDir, "storage"); dirExists(src) {
+			ctx.Println("初始化共享的 storage 目录")
+			if err := d.CopyDir(src, sharedStorage); err != nil {
+				return fmt.Errorf("初始化 storage 目录失败: %v", err)
+			}
+		}
+	}
+	for _, dir := range storageDirs {
+		if err := os.MkdirAll(filepath.Join(sharedStorage, dir), 0755); err != nil {
+			return fmt.Errorf("创建 storage 目录失败: %v", err)
+		}
+	}
+
+	if _, err := os.Stat(sharedEnv); os.IsNotExist(err) {
+		ctx.Println("初始化共享的 .env")
+		content, err := os.ReadFile(filepath.Join(ctx.WorkDir, ".env.example"))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("读取 .env.example 失败: %v", err)
+		}
+		if err := os.WriteFile(sharedEnv, content, 0640); err != nil {
+			return fmt.Errorf("创建 .env 失败: %v", err)
+		}
+	}
+
+	// servon.yaml 中的环境变量写入共享的 .env，php-fpm 和队列 worker 都从这里读取
+	if len(ctx.Config.Env) > 0 {
+		if err := updateEnvFile(sharedEnv, ctx.Config.Env); err != nil {
+			return fmt.Errorf("更新 .env 失败: %v", err)
+		}
+	}
+
+	for name, target := range map[string]string{"storage": sharedStorage, ".env": sharedEnv} {
+		link := filepath.Join(releaseDir, name)
+		if err := os.RemoveAll(link); err != nil {
+			return fmt.Errorf("删除发布版本中的 %s 失败: %v", name, err)
+		}
+		if err := os.Symlink(target, link); err != nil {
+			return fmt.Errorf("创建 %s 软链接失败: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+// prepareRelease 在切换版本前生成密钥、执行迁移并缓存配置
+func (d *LaravelDeployer) prepareRelease(ctx *core.DeployContext, releaseDir string) error {
+	if !envHasValue(filepath.Join(sharedDir(ctx.TargetDir), ".env"), "APP_KEY") {
+		ctx.Println("生成 APP_KEY")
+		if err := artisan(ctx, releaseDir, "key:generate", "--force"); err != nil {
+			ctx.Printf("生成 APP_KEY 失败: %v\n", err)
+			return fmt.Errorf("生成 APP_KEY 失败: %v", err)
+		}
+	}
+
+	if !dirExists(filepath.Join(releaseDir, "public", "storage")) {
+		if err := artisan(ctx, releaseDir, "storage:link"); err != nil {
+			ctx.Printf("创建 public/storage 软链接失败: %v\n", err)
+		}
+	}
+
+	if ctx.Config.Laravel.Migrate {
+		ctx.Println("开始执行数据库迁移")
+		if err := artisan(ctx, releaseDir, "migrate", "--force"); err != nil {
+			ctx.Printf("数据库迁移失败: %v\n", err)
+			return fmt.Errorf("数据库迁移失败: %v", err)
+		}
+	}
+
+	if err := artisan(ctx, releaseDir, "config:cache"); err != nil {
+		ctx.Printf("缓存配置失败: %v\n", err)
+		return fmt.Errorf("缓存配置失败: %v", err)
+	}
+	// 路由中使用闭包的项目无法缓存路由，不影响运行
+	for _, command := range []string{"route:cache", "view:cache"} {
+		if err := artisan(ctx, releaseDir, command); err != nil {
+			ctx.Printf("执行 %s 失败: %v\n", command, err)
+		}
+	}
+
+	// php-fpm 以 www-data 运行，需要写入 storage 和 bootstrap/cache，并能读取 .env
+	err := ctx.RunCommand("", "chown", "-R", WebUser+":"+WebUser,
+		filepath.Join(sharedDir(ctx.TargetDir), "storage"),
+		filepath.Join(releaseDir, "bootstrap", "cache"),
+	)
+	if err == nil {
+		err = ctx.RunCommand("", "chgrp", WebUser, filepath.Join(sharedDir(ctx.TargetDir), ".env"))
+	}
+	if err != nil {
+		ctx.Printf("设置目录权限失败: %v\n", err)
+		return fmt.Errorf("设置目录权限失败: %v", err)
+	}
+
+	return nil
+}
+
+// saveWorkers 为 servon.yaml 中的每个队列 worker 创建后台服务，并移除已删除的 worker
+// worker 通过 current 软链接运行，每次部署都会重启以加载新代码
+func (d *LaravelDeployer) saveWorkers(ctx *core.DeployContext, currentLink string) ([]string, error) {
+	prefix := ctx.ProjectName + "-worker-"
+	artisanPath := filepath.Join(currentLink, "artisan")
+
+	names := make(map[string]bool)
+	for _, worker := range ctx.Config.Laravel.Workers {
+		name := prefix + worker.Name
+		names[name] = true
+
+		tries, timeout := worker.Tries, worker.Timeout
+		if tries == 0 {
+			tries = 3
+		}
+		if timeout == 0 {
+			timeout = 60
+		}
+
+		args := []string{artisanPath, "queue:work"}
+		if worker.Connection != "" {
+			args = append(args, worker.Connection)
+		}
+		if worker.Queue != "" {
+			args = append(args, "--queue="+worker.Queue)
+		}
+		args = append(args,
+			"--tries="+strconv.Itoa(tries),
+			"--timeout="+strconv.Itoa(timeout),
+			"--sleep=3",
+			"--max-time=3600",
+		)
+
+		// 停止时等待正在执行的任务完成，超时时间需要大于任务的超时时间
+		spec := core.ServiceSpec{
+			Name:        name,
+			Command:     "php",
+			Args:        args,
+			WorkingDir:  currentLink,
+			User:        WebUser,
+			StopTimeout: timeout + 10,
+		}
+		if _, err := d.SaveBackgroundService(spec); err != nil {
+			ctx.Printf("配置队列 worker %s 失败: %v\n", worker.Name, err)
+			return nil, fmt.Errorf("配置队列 worker %s 失败: %v", worker.Name, err)
+		}
+	}
+
+	for _, name := range d.GetServiceNames(prefix) {
+		if names[name] {
+			continue
+		}
+
+		ctx.Printf("移除已删除的队列 worker: %s\n", name)
+		if err := d.StopBackgroundService(name, nil); err != nil {
+			ctx.Printf("移除队列 worker %s 失败: %v\n", name, err)
+		}
+	}
+
+	workers := make([]string, 0, len(names))
+	for name := range names {
+		workers = append(workers, name)
+	}
+	sort.Strings(workers)
+	return workers, nil
+}
+
+// artisan 在发布目录中执行 artisan 命令
+func artisan(ctx *core.DeployContext, releaseDir string, args ...string) error {
+	return ctx.RunCommand(releaseDir, "php", append([]string{"artisan"}, args...)...)
+}
+
+// PHPFPM 本机安装的 php-fpm
+type PHPFPM struct {
+	Service string // 服务名，如 php8.2-fpm
+	Socket  string // 监听的 unix socket
+}
+
+// findPHPFPM 查找已安装的 php-fpm，安装了多个版本时使用最高的版本
+func findPHPFPM() (*PHPFPM, error) {
+	dirs, _ := filepath.Glob("/etc/php/*/fpm")
+	if len(dirs) == 0 {
+		return nil, fmt.Errorf("未找到 php-fpm，请先安装 php-fpm")
+	}
+
+	versions := make([]string, 0, len(dirs))
+	for _, dir := range dirs {
+		versions = append(versions, filepath.Base(filepath.Dir(dir)))
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return compareVersion(versions[i], versions[j]) < 0
+	})
+
+	version := versions[len(versions)-1]
+	return &PHPFPM{
+		Service: fmt.Sprintf("php%s-fpm", version),
+		Socket:  fmt.Sprintf("/run/php/php%s-fpm.sock", version),
+	}, nil
+}
+
+// compareVersion 比较 8.2 这样的版本号
+func compareVersion(a, b string) int {
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		x, _ := strconv.Atoi(as[i])
+		y, _ := strconv.Atoi(bs[i])
+		if x != y {
+			return x - y
+		}
+	}
+	return len(as) - len(bs)
+}
+
+// updateEnvFile 在 .env 中设置环境变量，已存在的键原地替换，其余追加到末尾
+func updateEnvFile(path string, env map[string]string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	written := make(map[string]bool)
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(string(content)))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if key, _, ok := strings.Cut(line, "="); ok {
+			key = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(key), "export "))
+			if value, ok := env[key]; ok {
+				line = key + "=" + quoteEnvValue(value)
+				written[key] = true
+			}
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(env))
+	for key := range env {
+		if !written[key] {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		lines = append(lines, key+"="+quoteEnvValue(env[key]))
+	}
+
+	return os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0640)
+}
+
+// quoteEnvValue 值中包含空白、引号、# 或 $ 时加引号
+// 优先使用不做转义和变量替换的单引号，值中含单引号时使用双引号
+func quoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t\"'#\\$") {
+		return value
+	}
+	if !strings.Contains(value, "'") {
+		return "'" + value + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// envHasValue 判断 .env 中的键是否有非空的值
+func envHasValue(path, key string) bool {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		name, value, ok := strings.Cut(line, "=")
+		if ok && strings.TrimSpace(name) == key {
+			return strings.Trim(strings.TrimSpace(value), `"'`) != ""
+		}
+	}
+	return false
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -0,0 +1,35 @@
+package nextjs
+
+import (
+	"fmt"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/nodeapp"
+)
+
+const DefaultPort = 3000
+
+func Setup(app *core.App) {
+	app.AddDeployer(nodeapp.NewDeployer(app, nodeapp.Framework{
+		Name:        "nextjs",
+		Title:       "Next.js",
+		DefaultPort: DefaultPort,
+		Start:       start,
+		Env:         env,
+	}))
+}
+
+// start 用 node 运行项目依赖中 next 的命令脚本执行 next start，端口从 PORT 环境变量读取
+func start(current, buildDir string) (string, []string, error) {
+	script, err := nodeapp.PackageBin(buildDir, "next", "next")
+	if err != nil {
+		return "", nil, err
+	}
+
+	return "node", []string{filepath.Join(current, script), "start", current}, nil
+}
+
+// env next start 通过 HOSTNAME 指定监听地址
+func env(host string, port int) []string {
+	return []string{fmt.Sprintf("HOSTNAME=%s", host)}
+}
@@ -0,0 +1,284 @@
+// Package nodeapp 提供 Node 框架部署器的通用实现
+//
+// 各框架插件只需描述构建产物的启动方式，依赖安装、构建、
+// 发布版本和后台服务的注册都由这里完成。
+package nodeapp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"servon/core"
+	"strings"
+)
+
+const DefaultHost = "0.0.0.0"
+
+// 支持的包管理器，与已注册的软件名称一致
+const (
+	PackageManagerPnpm = "pnpm"
+	PackageManagerYarn = "yarn"
+	PackageManagerNpm  = "npm"
+)
+
+// Framework 描述一个 Node 框架的部署方式
+type Framework struct {
+	Name        string // 部署器名称，与 DetectProjectType 返回的项目类型一致
+	Title       string // 显示名称
+	DefaultPort int    // servon.yaml 未配置端口时使用的端口
+	// Start 返回启动服务的命令，buildDir 为构建完成的工作目录，用于检查构建产物；
+	// 命令中的路径应基于 current 软链接，回滚后无需修改服务配置
+	Start func(current, buildDir string) (string, []string, error)
+	// Env 返回框架需要的额外环境变量，如 Nuxt 的 NITRO_PORT
+	Env func(host string, port int) []string
+}
+
+// Deployer Node 框架的通用部署器
+type Deployer struct {
+	*core.App
+	Framework
+}
+
+func NewDeployer(app *core.App, framework Framework) *Deployer {
+	return &Deployer{
+		App:       app,
+		Framework: framework,
+	}
+}
+
+func (d *Deployer) GetName() string {
+	return d.Name
+}
+
+// DetectPackageManager 根据锁文件判断项目使用的包管理器，没有锁文件时使用 npm
+func DetectPackageManager(dir string) string {
+	switch {
+	case FileExists(filepath.Join(dir, "pnpm-lock.yaml")):
+		return PackageManagerPnpm
+	case FileExists(filepath.Join(dir, "yarn.lock")):
+		return PackageManagerYarn
+	default:
+		return PackageManagerNpm
+	}
+}
+
+// InstallCommand 返回按锁文件安装依赖的命令
+func InstallCommand(packageManager, dir string) []string {
+	switch packageManager {
+	case PackageManagerPnpm:
+		return []string{"pnpm", "install", "--frozen-lockfile"}
+	case PackageManagerYarn:
+		return []string{"yarn", "install", "--frozen-lockfile"}
+	default:
+		if FileExists(filepath.Join(dir, "package-lock.json")) {
+			return []string{"npm", "ci"}
+		}
+		return []string{"npm", "install"}
+	}
+}
+
+// BuildCommand 返回执行 package.json 中 build 脚本的命令
+func BuildCommand(packageManager string) []string {
+	return []string{packageManager, "run", "build"}
+}
+
+func (d *Deployer) Deploy(ctx *core.DeployContext) error {
+	projectName, workDir, targetDir := ctx.ProjectName, ctx.WorkDir, ctx.TargetDir
+	config := ctx.Config
+
+	ctx.Printf("开始部署 %s 项目: %s\n", d.Title, projectName)
+
+	if err := d.Build(ctx); err != nil {
+		return err
+	}
+
+	currentLink := filepath.Join(targetDir, "current")
+	host := DefaultHost
+	port := config.PortOrDefault(d.DefaultPort)
+	ctx.Port = port
+
+	// 启动命令，servon.yaml 中配置了 start 时在 current 目录下通过 sh 执行
+	var command string
+	var args []string
+	var err error
+	if config.Start != "" {
+		command, args = "sh", []string{"-c", fmt.Sprintf("cd %s && exec %s", currentLink, config.Start)}
+	} else {
+		command, args, err = d.Start(currentLink, workDir)
+		if err != nil {
+			ctx.Printf("无法确定启动命令: %v\n", err)
+			return fmt.Errorf("无法确定启动命令: %v", err)
+		}
+	}
+
+	// 将构建好的项目发布为新版本，并将 current 软链接切换过去
+	releaseDir, err := d.CreateRelease(targetDir, workDir)
+	if err != nil {
+		ctx.Printf("创建发布版本失败: %v\n", err)
+		return fmt.Errorf("创建发布版本失败: %v", err)
+	}
+
+	// 服务以项目专用用户运行，发布版本的文件归该用户所有
+	runUser, err := d.PrepareProjectUser(projectName, releaseDir, config.User)
+	if err != nil {
+		ctx.Printf("准备运行用户失败: %v\n", err)
+		return fmt.Errorf("准备运行用户失败: %v", err)
+	}
+
+	if err := d.ActivateRelease(targetDir, filepath.Base(releaseDir)); err != nil {
+		ctx.Printf("切换版本失败: %v\n", err)
+		return fmt.Errorf("切换版本失败: %v", err)
+	}
+
+	spec := core.ServiceSpec{
+		Name:       projectName,
+		Command:    command,
+		Args:       args,
+		WorkingDir: currentLink,
+		User:       runUser,
+		Sandbox:    config.Sandbox,
+	}
+	spec.AddEnv(
+		fmt.Sprintf("HOST=%s", host),
+		fmt.Sprintf("PORT=%d", port),
+		"NODE_ENV=production",
+	)
+	if d.Env != nil {
+		spec.AddEnv(d.Env(host, port)...)
+	}
+	spec.AddEnv(config.EnvList()...)
+
+	// 每次部署都写入服务配置，使 servon.yaml 中的端口和环境变量生效
+	serviceFilePath, err := d.SaveBackgroundService(spec)
+	if err != nil {
+		ctx.Printf("配置后台服务失败: %v\n", err)
+		return fmt.Errorf("配置后台服务失败: %v", err)
+	}
+
+	ctx.Println()
+	ctx.Printf("✨ %s 项目部署成功！\n", d.Title)
+	ctx.Println()
+	ctx.Printf("📦 发布版本: %s\n", releaseDir)
+	ctx.Printf("📁 current（软链接） 路径: %s\n", currentLink)
+	ctx.Printf("📁 服务文件路径: %s\n", serviceFilePath)
+	ctx.Printf("🚀 启动命令: %s %v\n", command, args)
+	ctx.Printf("👤 运行用户: %s\n", runUser)
+	ctx.Printf("🌐 快速打开: http://%s:%d\n", host, port)
+	if config.Domain != "" {
+		ctx.Printf("🌐 域名: %s\n", config.Domain)
+	}
+	ctx.Println()
+	return nil
+}
+
+// Build 安装依赖并构建项目，servon.yaml 中的 install 和 build 会替换默认命令
+func (d *Deployer) Build(ctx *core.DeployContext) error {
+	workDir, config := ctx.WorkDir, ctx.Config
+
+	packageManager := DetectPackageManager(workDir)
+	ctx.Printf("使用包管理器: %s\n", packageManager)
+
+	if err := d.ensurePackageManager(ctx, packageManager); err != nil {
+		return err
+	}
+
+	install := InstallCommand(packageManager, workDir)
+	if config.Install != "" {
+		install = []string{"sh", "-c", config.Install}
+	}
+
+	ctx.Println("开始安装依赖")
+	if err := ctx.RunCommand(workDir, install[0], install[1:]...); err != nil {
+		ctx.Printf("安装依赖失败: %v\n", err)
+		return fmt.Errorf("安装依赖失败: %v", err)
+	}
+	ctx.Println("安装依赖成功")
+
+	build := BuildCommand(packageManager)
+	if config.Build != "" {
+		build = []string{"sh", "-c", config.Build}
+	}
+
+	ctx.Printf("开始构建 %s 项目\n", d.Title)
+	if err := ctx.RunCommand(workDir, build[0], build[1:]...); err != nil {
+		ctx.Printf("构建失败: %v\n", err)
+		return fmt.Errorf("构建失败: %v", err)
+	}
+	ctx.Println("构建成功")
+
+	return nil
+}
+
+// ensurePackageManager 包管理器不可用时通过对应的软件插件安装
+func (d *Deployer) ensurePackageManager(ctx *core.DeployContext, packageManager string) error {
+	if _, err := exec.LookPath(packageManager); err == nil {
+		return nil
+	}
+
+	ctx.Printf("未找到 %s，开始安装\n", packageManager)
+	if err := d.SoftManager.Install(packageManager); err != nil {
+		ctx.Printf("安装 %s 失败: %v\n", packageManager, err)
+		return fmt.Errorf("安装 %s 失败: %v", packageManager, err)
+	}
+	return nil
+}
+
+// CheckSiteAddress 检查网关中是否已有其他项目使用同一站点地址
+// 未配置域名的站点按端口提供服务（如 :80），网关会用新站点整体替换该端口的服务器，
+// 第二个项目会悄悄顶替第一个，因此部署前拒绝
+func CheckSiteAddress(gateway core.SuperGateway, projectName, address string) error {
+	projects, err := gateway.GetProjects()
+	if err != nil {
+		return fmt.Errorf("读取网关站点失败: %v", err)
+	}
+
+	for _, project := range projects {
+		if project.Name != projectName && strings.EqualFold(strings.TrimSpace(project.Domain), strings.TrimSpace(address)) {
+			return fmt.Errorf("站点地址 %s 已被项目 %s 使用，请在 servon.yaml 中配置 domain 或其他 port", address, project.Name)
+		}
+	}
+	return nil
+}
+
+// PackageBin 读取依赖包 package.json 中的 bin，返回命令对应的脚本相对 buildDir 的路径
+// 启动服务时用 node 直接运行该脚本，不依赖 node_modules/.bin 中的软链接
+func PackageBin(buildDir, pkg, name string) (string, error) {
+	pkgDir := filepath.Join("node_modules", pkg)
+	data, err := os.ReadFile(filepath.Join(buildDir, pkgDir, "package.json"))
+	if err != nil {
+		return "", fmt.Errorf("未找到依赖 %s，请确认它在 dependencies 中", pkg)
+	}
+
+	var manifest struct {
+		Bin json.RawMessage `json:"bin"`
+	}
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return "", fmt.Errorf("解析 %s 的 package.json 失败: %v", pkg, err)
+	}
+
+	// bin 可以是单个路径，此时命令名与包名相同，也可以是命令名到路径的映射
+	var bin string
+	var bins map[string]string
+	if json.Unmarshal(manifest.Bin, &bin) != nil {
+		if json.Unmarshal(manifest.Bin, &bins) == nil {
+			bin = bins[name]
+		}
+	}
+	if bin == "" {
+		return "", fmt.Errorf("依赖 %s 中没有 %s 命令", pkg, name)
+	}
+
+	script := filepath.Join(pkgDir, filepath.Clean(bin))
+	if !FileExists(filepath.Join(buildDir, script)) {
+		return "", fmt.Errorf("未找到 %s", script)
+	}
+	return script, nil
+}
+
+// FileExists 检查文件是否存在，用于判断构建产物的位置
+func FileExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && !info.IsDir()
+}
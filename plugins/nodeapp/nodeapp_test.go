@@ -0,0 +1,44 @@
+package nodeapp
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestPackageBin 测试从依赖包的 package.json 中找到命令脚本
+func TestPackageBin(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"node_modules/next/package.json":             `{"name": "next", "bin": {"next": "./dist/bin/next"}}`,
+		"node_modules/next/dist/bin/next":            "",
+		"node_modules/@remix-run/serve/package.json": `{"name": "@remix-run/serve", "bin": "dist/cli.js"}`,
+		"node_modules/@remix-run/serve/dist/cli.js":  "",
+		"node_modules/broken/package.json":           `{"name": "broken", "bin": {"broken": "missing.js"}}`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		os.MkdirAll(filepath.Dir(path), 0755)
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cases := []struct {
+		pkg, name, want string
+	}{
+		{"next", "next", "node_modules/next/dist/bin/next"},
+		{"@remix-run/serve", "remix-serve", "node_modules/@remix-run/serve/dist/cli.js"},
+	}
+	for _, c := range cases {
+		if got, err := PackageBin(dir, c.pkg, c.name); err != nil || got != filepath.FromSlash(c.want) {
+			t.Errorf("PackageBin(%s, %s) = %q, %v, want %q", c.pkg, c.name, got, err, c.want)
+		}
+	}
+
+	for _, c := range [][2]string{{"next", "other"}, {"broken", "broken"}, {"missing", "missing"}} {
+		if _, err := PackageBin(dir, c[0], c[1]); err == nil {
+			t.Errorf("Expected PackageBin(%s, %s) to fail", c[0], c[1])
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package nuxt
+
+import (
+	"fmt"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/nodeapp"
+)
+
+const DefaultPort = 3000
+
+func Setup(app *core.App) {
+	app.AddDeployer(nodeapp.NewDeployer(app, nodeapp.Framework{
+		Name:        "nuxtjs",
+		Title:       "Nuxt",
+		DefaultPort: DefaultPort,
+		Start:       start,
+		Env:         env,
+	}))
+}
+
+// start 运行 Nitro 构建出的 .output/server/index.mjs
+func start(current, buildDir string) (string, []string, error) {
+	if !nodeapp.FileExists(filepath.Join(buildDir, ".output", "server", "index.mjs")) {
+		return "", nil, fmt.Errorf("未找到 .output/server/index.mjs，请确认使用 node-server 预设构建")
+	}
+
+	return "node", []string{filepath.Join(current, ".output", "server", "index.mjs")}, nil
+}
+
+// env Nitro 优先读取 NITRO_HOST 和 NITRO_PORT
+func env(host string, port int) []string {
+	return []string{
+		fmt.Sprintf("NITRO_HOST=%s", host),
+		fmt.Sprintf("NITRO_PORT=%d", port),
+	}
+}
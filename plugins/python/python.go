@@ -0,0 +1,185 @@
+package python
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/backend"
+	"servon/plugins/nodeapp"
+	"strings"
+)
+
+// venvDir 发布版本中虚拟环境的目录名，虚拟环境不能移动，因此在发布目录中创建
+const venvDir = ".venv"
+
+// 使用这些框架的项目通过 uvicorn 运行，其余通过 gunicorn 运行
+var asgiFrameworks = []string{"fastapi", "starlette", "quart", "litestar"}
+
+func Setup(app *core.App) {
+	app.AddDeployer(NewPythonDeployer(app))
+}
+
+// PythonDeployer 为每个发布版本创建虚拟环境，通过 gunicorn 或 uvicorn 运行
+type PythonDeployer struct {
+	*backend.Deployer
+}
+
+func NewPythonDeployer(app *core.App) *PythonDeployer {
+	d := &PythonDeployer{
+		Deployer: backend.NewDeployer(app, "python", "Python"),
+	}
+	// 服务通过 current 中的虚拟环境运行，start 中可以直接使用虚拟环境中的命令
+	d.Env = func(current string) []string {
+		venv := filepath.Join(current, venvDir)
+		return []string{
+			"VIRTUAL_ENV=" + venv,
+			"PATH=" + filepath.Join(venv, "bin") + ":/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+			"PYTHONUNBUFFERED=1",
+		}
+	}
+	return d
+}
+
+func (d *PythonDeployer) Deploy(ctx *core.DeployContext) error {
+	projectName, workDir, config := ctx.ProjectName, ctx.WorkDir, ctx.Config
+
+	ctx.Printf("开始部署 Python 项目: %s\n", projectName)
+
+	if err := d.ensurePython(ctx); err != nil {
+		return err
+	}
+
+	start := ""
+	if config.Start == "" {
+		server, app, err := detectApp(workDir)
+		if err != nil {
+			ctx.Println(err)
+			return err
+		}
+		start = startCommand(server, app, config.PortOrDefault(backend.DefaultPort))
+		ctx.Printf("使用 %s 运行 %s\n", server, app)
+	}
+
+	releaseDir, err := d.Release(ctx)
+	if err != nil {
+		return err
+	}
+
+	venv := filepath.Join(releaseDir, venvDir)
+	pip := filepath.Join(venv, "bin", "pip")
+	if err := d.Run(ctx, releaseDir, "创建虚拟环境", "python3", "-m", "venv", venv); err != nil {
+		return err
+	}
+	if err := d.Run(ctx, releaseDir, "升级 pip", pip, "install", "--upgrade", "pip"); err != nil {
+		return err
+	}
+
+	// 自定义命令在激活虚拟环境后执行
+	activate := ". " + filepath.Join(venv, "bin", "activate") + " && "
+	switch {
+	case config.Install != "":
+		err = d.Run(ctx, releaseDir, "安装依赖", "sh", "-c", activate+config.Install)
+	case nodeapp.FileExists(filepath.Join(releaseDir, "requirements.txt")):
+		err = d.Run(ctx, releaseDir, "安装依赖", pip, "install", "-r", "requirements.txt")
+	default:
+		err = d.Run(ctx, releaseDir, "安装依赖", pip, "install", ".")
+	}
+	if err != nil {
+		return err
+	}
+
+	if start != "" {
+		server := strings.Fields(start)[0]
+		if err := d.Run(ctx, releaseDir, "安装 "+server, pip, "install", server); err != nil {
+			return err
+		}
+	}
+
+	if config.Build != "" {
+		if err := d.Run(ctx, releaseDir, "构建", "sh", "-c", activate+config.Build); err != nil {
+			return err
+		}
+	}
+
+	return d.Serve(ctx, releaseDir, start)
+}
+
+// ensurePython 确保 python3 和 venv 模块可用，Debian 系统的 venv 需要单独安装
+func (d *PythonDeployer) ensurePython(ctx *core.DeployContext) error {
+	if err := d.EnsurePackages(ctx, "python3", "python3", "python3-venv", "python3-pip"); err != nil {
+		return err
+	}
+
+	if exec.Command("python3", "-c", "import venv, ensurepip").Run() == nil {
+		return nil
+	}
+
+	ctx.Println("未找到 python3-venv，开始安装")
+	if err := d.AptInstall("python3-venv"); err != nil {
+		ctx.Printf("安装 python3-venv 失败: %v\n", err)
+		return fmt.Errorf("安装 python3-venv 失败: %v", err)
+	}
+	return nil
+}
+
+// detectApp 根据项目结构确定应用服务器和应用入口，如 gunicorn 和 mysite.wsgi:application
+func detectApp(workDir string) (string, string, error) {
+	// Django 项目使用 <项目包>/wsgi.py
+	if nodeapp.FileExists(filepath.Join(workDir, "manage.py")) {
+		matches, _ := filepath.Glob(filepath.Join(workDir, "*", "wsgi.py"))
+		if len(matches) == 1 {
+			pkg := filepath.Base(filepath.Dir(matches[0]))
+			return "gunicorn", pkg + ".wsgi:application", nil
+		}
+	}
+
+	server := "gunicorn"
+	if usesASGI(workDir) {
+		server = "uvicorn"
+	}
+
+	candidates := []struct {
+		file string
+		app  string
+	}{
+		{"main.py", "main:app"},
+		{"app.py", "app:app"},
+		{"app/main.py", "app.main:app"},
+		{"wsgi.py", "wsgi:app"},
+		{"asgi.py", "asgi:app"},
+	}
+	for _, candidate := range candidates {
+		if nodeapp.FileExists(filepath.Join(workDir, candidate.file)) {
+			return server, candidate.app, nil
+		}
+	}
+
+	return "", "", fmt.Errorf("未找到应用入口（main.py、app.py 等），请在 servon.yaml 中配置 start")
+}
+
+// usesASGI 判断依赖中是否包含 ASGI 框架
+func usesASGI(workDir string) bool {
+	var deps strings.Builder
+	for _, name := range []string{"requirements.txt", "pyproject.toml"} {
+		if content, err := os.ReadFile(filepath.Join(workDir, name)); err == nil {
+			deps.WriteString(strings.ToLower(string(content)))
+		}
+	}
+
+	for _, framework := range asgiFrameworks {
+		if strings.Contains(deps.String(), framework) {
+			return true
+		}
+	}
+	return false
+}
+
+// startCommand 返回应用服务器的启动命令
+func startCommand(server, app string, port int) string {
+	if server == "uvicorn" {
+		return fmt.Sprintf("uvicorn %s --host %s --port %d", app, backend.DefaultHost, port)
+	}
+	return fmt.Sprintf("gunicorn %s --bind %s:%d --workers 2", app, backend.DefaultHost, port)
+}
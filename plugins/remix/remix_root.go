@@ -0,0 +1,39 @@
+package remix
+
+import (
+	"fmt"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/nodeapp"
+)
+
+const DefaultPort = 3000
+
+func Setup(app *core.App) {
+	app.AddDeployer(nodeapp.NewDeployer(app, nodeapp.Framework{
+		Name:        "remix",
+		Title:       "Remix",
+		DefaultPort: DefaultPort,
+		Start:       start,
+	}))
+}
+
+// start 用 node 运行 @remix-run/serve 的 remix-serve 脚本，启动服务端构建产物
+// Vite 构建输出到 build/server/index.js，旧版编译器输出到 build/index.js
+func start(current, buildDir string) (string, []string, error) {
+	serve, err := nodeapp.PackageBin(buildDir, "@remix-run/serve", "remix-serve")
+	if err != nil {
+		return "", nil, err
+	}
+
+	for _, entry := range []string{
+		filepath.Join("build", "server", "index.js"),
+		filepath.Join("build", "index.js"),
+	} {
+		if nodeapp.FileExists(filepath.Join(buildDir, entry)) {
+			return "node", []string{filepath.Join(current, serve), filepath.Join(current, entry)}, nil
+		}
+	}
+
+	return "", nil, fmt.Errorf("未找到 build/server/index.js 或 build/index.js")
+}
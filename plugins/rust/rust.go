@@ -0,0 +1,137 @@
+package rust
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/backend"
+	"servon/plugins/nodeapp"
+)
+
+func Setup(app *core.App) {
+	app.AddDeployer(NewRustDeployer(app))
+}
+
+// RustDeployer 使用 cargo 编译 Rust 项目，作为后台服务运行
+type RustDeployer struct {
+	*backend.Deployer
+}
+
+func NewRustDeployer(app *core.App) *RustDeployer {
+	return &RustDeployer{
+		Deployer: backend.NewDeployer(app, "rust", "Rust"),
+	}
+}
+
+func (d *RustDeployer) Deploy(ctx *core.DeployContext) error {
+	projectName, workDir, config := ctx.ProjectName, ctx.WorkDir, ctx.Config
+
+	ctx.Printf("开始部署 Rust 项目: %s\n", projectName)
+
+	if err := d.EnsurePackages(ctx, "cargo", "cargo"); err != nil {
+		return err
+	}
+
+	start := ""
+	if config.Build != "" {
+		if err := d.Run(ctx, workDir, "构建", "sh", "-c", config.Build); err != nil {
+			return err
+		}
+	} else {
+		build := []string{"cargo", "build", "--release"}
+		if nodeapp.FileExists(filepath.Join(workDir, "Cargo.lock")) {
+			build = append(build, "--locked")
+		}
+		if err := d.Run(ctx, workDir, "构建", build...); err != nil {
+			return err
+		}
+
+		name, err := d.findBinary(ctx, projectName)
+		if err != nil {
+			ctx.Println(err)
+			return err
+		}
+
+		// 只保留编译好的二进制文件，target 目录不进入发布版本
+		binary := filepath.Join("bin", name)
+		if err := os.MkdirAll(filepath.Join(workDir, "bin"), 0755); err != nil {
+			return fmt.Errorf("创建 bin 目录失败: %v", err)
+		}
+		if err := os.Rename(filepath.Join(workDir, "target", "release", name), filepath.Join(workDir, binary)); err != nil {
+			ctx.Printf("移动二进制文件失败: %v\n", err)
+			return fmt.Errorf("移动二进制文件失败: %v", err)
+		}
+		start = "./" + binary
+	}
+
+	if err := os.RemoveAll(filepath.Join(workDir, "target")); err != nil {
+		return fmt.Errorf("清理 target 目录失败: %v", err)
+	}
+
+	releaseDir, err := d.Release(ctx)
+	if err != nil {
+		return err
+	}
+
+	return d.Serve(ctx, releaseDir, start)
+}
+
+// findBinary 通过 cargo metadata 查找要运行的二进制目标
+// 有多个时使用与项目或 package 同名的目标
+func (d *RustDeployer) findBinary(ctx *core.DeployContext, projectName string) (string, error) {
+	cmd := exec.CommandContext(ctx.Context, "cargo", "metadata", "--no-deps", "--format-version", "1")
+	cmd.Dir = ctx.WorkDir
+	cmd.Stderr = ctx.Output
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("读取 cargo metadata 失败: %v", err)
+	}
+
+	var metadata struct {
+		Packages []struct {
+			Name    string `json:"name"`
+			Targets []struct {
+				Name string   `json:"name"`
+				Kind []string `json:"kind"`
+			} `json:"targets"`
+		} `json:"packages"`
+	}
+	if err := json.Unmarshal(output, &metadata); err != nil {
+		return "", fmt.Errorf("解析 cargo metadata 失败: %v", err)
+	}
+
+	var binaries []string
+	preferred := map[string]bool{projectName: true}
+	for _, pkg := range metadata.Packages {
+		preferred[pkg.Name] = true
+		for _, target := range pkg.Targets {
+			for _, kind := range target.Kind {
+				if kind == "bin" {
+					binaries = append(binaries, target.Name)
+				}
+			}
+		}
+	}
+
+	switch len(binaries) {
+	case 0:
+		return "", fmt.Errorf("项目中没有可执行的二进制目标")
+	case 1:
+		return binaries[0], nil
+	}
+
+	for _, name := range binaries {
+		if name == projectName {
+			return name, nil
+		}
+	}
+	for _, name := range binaries {
+		if preferred[name] {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("找到多个二进制目标 %v，请在 servon.yaml 中配置 build 和 start", binaries)
+}
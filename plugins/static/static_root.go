@@ -0,0 +1,173 @@
+package static
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/nodeapp"
+)
+
+// DefaultPort 未配置域名时 Caddy 监听的端口
+const DefaultPort = 80
+
+// 常见构建工具的输出目录，按顺序查找第一个存在的目录
+var outputDirs = []string{"dist", "build", "out", "public"}
+
+func Setup(app *core.App) {
+	app.AddDeployer(NewStaticDeployer(app))
+}
+
+// StaticDeployer 构建静态站点并交给 Caddy 直接提供服务，不启动后台进程
+type StaticDeployer struct {
+	*nodeapp.Deployer
+}
+
+func NewStaticDeployer(app *core.App) *StaticDeployer {
+	return &StaticDeployer{
+		Deployer: nodeapp.NewDeployer(app, nodeapp.Framework{
+			Name:  "static",
+			Title: "静态站点",
+		}),
+	}
+}
+
+func (d *StaticDeployer) Deploy(ctx *core.DeployContext) error {
+	projectName, workDir, targetDir := ctx.ProjectName, ctx.WorkDir, ctx.TargetDir
+	config := ctx.Config
+
+	ctx.Printf("开始部署静态站点: %s\n", projectName)
+
+	// 未配置域名时按端口提供服务，健康检查也使用该端口
+	address := config.Domain
+	if address == "" {
+		ctx.Port = config.PortOrDefault(DefaultPort)
+		address = fmt.Sprintf(":%d", ctx.Port)
+	}
+
+	// 构建前确认地址未被其他项目占用，避免替换其他项目的站点
+	gateway, err := d.GetGateway("caddy")
+	if err != nil {
+		ctx.Printf("获取 Caddy 网关失败: %v\n", err)
+		return fmt.Errorf("获取 Caddy 网关失败: %v", err)
+	}
+	if err := nodeapp.CheckSiteAddress(gateway, projectName, address); err != nil {
+		ctx.Println(err)
+		return err
+	}
+
+	if err := d.buildSite(ctx); err != nil {
+		return err
+	}
+
+	outputDir, err := d.findOutputDir(workDir, config.Output)
+	if err != nil {
+		ctx.Println(err)
+		return err
+	}
+	ctx.Printf("发布输出目录: %s\n", outputDir)
+
+	// 只发布输出目录，current 指向的就是站点根目录
+	releaseDir, err := d.PublishRelease(targetDir, ctx.ID, outputDir)
+	if err != nil {
+		ctx.Printf("创建发布版本失败: %v\n", err)
+		return fmt.Errorf("创建发布版本失败: %v", err)
+	}
+
+	if err := d.ActivateRelease(targetDir, ctx.ID); err != nil {
+		ctx.Printf("切换版本失败: %v\n", err)
+		return fmt.Errorf("切换版本失败: %v", err)
+	}
+
+	// 通过 package.json 构建的前端项目按单页应用处理
+	currentLink := filepath.Join(targetDir, "current")
+	spa := nodeapp.FileExists(filepath.Join(workDir, "package.json"))
+
+	err = gateway.AddProject(core.Project{
+		Name:    projectName,
+		Domain:  address,
+		Enabled: true,
+		Config: map[string]interface{}{
+			"type":        "static",
+			"output_path": currentLink,
+			"spa":         spa,
+		},
+	})
+	if err != nil {
+		ctx.Printf("配置 Caddy 站点失败: %v\n", err)
+		return fmt.Errorf("配置 Caddy 站点失败: %v", err)
+	}
+
+	ctx.Println()
+	ctx.Println("✨ 静态站点部署成功！")
+	ctx.Println()
+	ctx.Printf("📦 发布版本: %s\n", releaseDir)
+	ctx.Printf("📁 站点根目录: %s\n", currentLink)
+	ctx.Printf("🌐 站点地址: %s\n", address)
+	ctx.Println()
+	return nil
+}
+
+// buildSite 构建站点：Hugo 站点使用 hugo，有 package.json 的项目使用包管理器，
+// 否则视为无需构建的纯静态文件
+func (d *StaticDeployer) buildSite(ctx *core.DeployContext) error {
+	workDir, config := ctx.WorkDir, ctx.Config
+
+	switch {
+	case d.IsHugoSite(workDir) && config.Build == "":
+		if _, err := exec.LookPath("hugo"); err != nil {
+			ctx.Println("未找到 hugo，请先安装 Hugo")
+			return fmt.Errorf("未找到 hugo，请先安装 Hugo")
+		}
+
+		ctx.Println("开始构建 Hugo 站点")
+		if err := ctx.RunCommand(workDir, "hugo", "--minify"); err != nil {
+			ctx.Printf("构建失败: %v\n", err)
+			return fmt.Errorf("构建失败: %v", err)
+		}
+		return nil
+
+	case nodeapp.FileExists(filepath.Join(workDir, "package.json")):
+		return d.Build(ctx)
+
+	case config.Build != "":
+		ctx.Println("开始构建静态站点")
+		if err := ctx.RunShell(workDir, config.Build); err != nil {
+			ctx.Printf("构建失败: %v\n", err)
+			return fmt.Errorf("构建失败: %v", err)
+		}
+		return nil
+	}
+
+	ctx.Println("未检测到构建步骤，直接发布仓库内容")
+	return nil
+}
+
+// findOutputDir 确定要发布的目录，servon.yaml 中的 output 优先
+func (d *StaticDeployer) findOutputDir(workDir, output string) (string, error) {
+	if output != "" {
+		dir := filepath.Join(workDir, output)
+		if !dirExists(dir) {
+			return "", fmt.Errorf("输出目录不存在: %s", output)
+		}
+		return dir, nil
+	}
+
+	for _, name := range outputDirs {
+		if dir := filepath.Join(workDir, name); dirExists(dir) && nodeapp.FileExists(filepath.Join(dir, "index.html")) {
+			return dir, nil
+		}
+	}
+
+	if nodeapp.FileExists(filepath.Join(workDir, "index.html")) {
+		return workDir, nil
+	}
+
+	return "", fmt.Errorf("未找到包含 index.html 的输出目录，请在 servon.yaml 中配置 output")
+}
+
+func dirExists(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
@@ -1,13 +1,14 @@
 package supervisor
 
 import (
-	"bufio"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"servon/components/supervisor_util"
 	"servon/core"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -16,6 +17,7 @@ type SupervisorPlugin struct {
 	info core.SoftwareInfo
 	*core.App
 	configDir string
+	client    *supervisor_util.Client
 }
 
 func Setup(app *core.App) {
@@ -32,6 +34,7 @@ func NewSupervisorPlugin(app *core.App) core.SuperService {
 			Description: "Supervisor 是运行时环境",
 		},
 		configDir: "/etc/supervisor/conf.d",
+		client:    supervisor_util.NewClient(supervisor_util.DefaultSocket),
 	}
 }
 
@@ -121,49 +124,55 @@ func (s *SupervisorPlugin) GetStatus() (map[string]string, error) {
 		version = strings.TrimSpace(string(verOutput))
 	}
 
-	// 3. 检查进程是否运行
-	cmd := exec.Command("pgrep", "supervisord")
-	if err := cmd.Run(); err != nil {
+	// 3. 通过 socket 检查 supervisord 是否运行
+	state, err := s.client.GetState()
+	if err != nil {
+		if supervisor_util.IsFault(err, supervisor_util.FaultShutdownState) {
+			return map[string]string{
+				"status":  StatusStopped,
+				"version": version,
+				"message": "Supervisor 正在关闭",
+			}, nil
+		}
 		return map[string]string{
 			"status":  StatusStopped,
 			"version": version,
 			"message": "Supervisor 已安装但未运行",
+			"error":   err.Error(),
 		}, nil
 	}
-
-	// 4. 检查 socket 文件
-	if _, err := os.Stat("/var/run/supervisor.sock"); os.IsNotExist(err) {
+	if state != "RUNNING" {
 		return map[string]string{
 			"status":  StatusError,
 			"version": version,
-			"message": "Supervisor 进程运行中但 socket 文件不存在",
+			"message": fmt.Sprintf("Supervisor 状态异常: %s", state),
 		}, nil
 	}
 
-	// 5. 检查服务状态
-	statusCmd := exec.Command("supervisorctl", "status")
-	output, err := statusCmd.CombinedOutput()
+	// 4. 获取服务状态
+	infos, err := s.client.GetAllProcessInfo()
 	if err != nil {
 		return map[string]string{
 			"status":  StatusError,
 			"version": version,
 			"message": fmt.Sprintf("Supervisor 运行异常: %v", err),
-			"error":   string(output),
 		}, nil
 	}
 
-	// 6. 获取运行中的服务数量
+	// 5. 获取运行中的服务数量
 	runningServices := 0
-	totalServices := 0
-	scanner := bufio.NewScanner(strings.NewReader(string(output)))
-	for scanner.Scan() {
-		line := scanner.Text()
-		totalServices++
-		if strings.Contains(line, "RUNNING") {
+	totalServices := len(infos)
+	for _, info := range infos {
+		if info.State == supervisor_util.StateRunning {
 			runningServices++
 		}
 	}
 
+	// 6. 优先使用 supervisord 报告的版本
+	if rpcVersion, err := s.client.GetSupervisorVersion(); err == nil {
+		version = rpcVersion
+	}
+
 	// 7. 检查配置文件
 	configExists := false
 	configPaths := []string{
@@ -206,7 +215,11 @@ func (s *SupervisorPlugin) getPidFile() string {
 
 // 获取运行时间
 func (s *SupervisorPlugin) getUptime() string {
-	cmd := exec.Command("ps", "-p", s.getSupervisorPid(), "-o", "etime=")
+	pid := s.getSupervisorPid()
+	if pid == "" {
+		return "未知"
+	}
+	cmd := exec.Command("ps", "-p", pid, "-o", "etime=")
 	output, err := cmd.CombinedOutput()
 	if err != nil {
 		return "未知"
@@ -216,12 +229,11 @@ func (s *SupervisorPlugin) getUptime() string {
 
 // 获取 Supervisor 进程 PID
 func (s *SupervisorPlugin) getSupervisorPid() string {
-	cmd := exec.Command("pgrep", "supervisord")
-	output, err := cmd.CombinedOutput()
+	pid, err := s.client.GetPID()
 	if err != nil {
 		return ""
 	}
-	return strings.TrimSpace(string(output))
+	return strconv.Itoa(pid)
 }
 
 // 检查配置文件语法
@@ -253,8 +265,7 @@ func (s *SupervisorPlugin) Start() error {
 func (s *SupervisorPlugin) Stop() error {
 	fmt.Println("Supervisor 开始停止")
 
-	err, _ := s.RunShellWithSudo("supervisorctl", "shutdown")
-	if err != nil {
+	if err := s.client.Shutdown(); err != nil {
 		return err
 	}
 
@@ -267,9 +278,8 @@ func (s *SupervisorPlugin) Stop() error {
 func (s *SupervisorPlugin) StartService(serviceName string) error {
 	fmt.Printf("启动服务: %s\n", serviceName)
 
-	err, output := s.RunShellWithSudo("supervisorctl", "start", serviceName)
-	if err != nil {
-		return fmt.Errorf("启动服务 %s 失败: %v\n%s", serviceName, err, output)
+	if err := s.client.StartProcess(serviceName, true); err != nil && !supervisor_util.IsFault(err, supervisor_util.FaultAlreadyStarted) {
+		return fmt.Errorf("启动服务 %s 失败: %v", serviceName, err)
 	}
 
 	fmt.Printf("服务 %s 启动成功\n", serviceName)
@@ -279,9 +289,8 @@ func (s *SupervisorPlugin) StartService(serviceName string) error {
 func (s *SupervisorPlugin) StopService(serviceName string) error {
 	fmt.Printf("停止服务: %s\n", serviceName)
 
-	err, output := s.RunShellWithSudo("supervisorctl", "stop", serviceName)
-	if err != nil {
-		return fmt.Errorf("停止服务 %s 失败: %v\n%s", serviceName, err, output)
+	if err := s.client.StopProcess(serviceName, true); err != nil && !supervisor_util.IsFault(err, supervisor_util.FaultNotRunning) {
+		return fmt.Errorf("停止服务 %s 失败: %v", serviceName, err)
 	}
 
 	fmt.Printf("服务 %s 停止成功\n", serviceName)
@@ -385,9 +394,11 @@ func (s *SupervisorPlugin) Restart() error {
 func (s *SupervisorPlugin) RestartService(serviceName string) error {
 	fmt.Printf("重启服务: %s\n", serviceName)
 
-	err, output := s.RunShellWithSudo("supervisorctl", "restart", serviceName)
-	if err != nil {
-		return fmt.Errorf("重启服务 %s 失败: %v\n%s", serviceName, err, output)
+	if err := s.client.StopProcess(serviceName, true); err != nil && !supervisor_util.IsFault(err, supervisor_util.FaultNotRunning) {
+		return fmt.Errorf("重启服务 %s 失败: %v", serviceName, err)
+	}
+	if err := s.client.StartProcess(serviceName, true); err != nil {
+		return fmt.Errorf("重启服务 %s 失败: %v", serviceName, err)
 	}
 
 	fmt.Printf("服务 %s 重启成功\n", serviceName)
@@ -401,33 +412,26 @@ func (s *SupervisorPlugin) GetLogs(serviceName string, lines int) (string, error
 		lines = 100 // 默认获取100行
 	}
 
-	// 获取标准输出日志
-	stdoutLogPath := fmt.Sprintf("/var/log/supervisor/%s.out.log", serviceName)
-	stderrLogPath := fmt.Sprintf("/var/log/supervisor/%s.err.log", serviceName)
-
-	// 检查日志文件是否存在
-	if _, err := os.Stat(stdoutLogPath); os.IsNotExist(err) {
-		return "", fmt.Errorf("服务 %s 的标准输出日志文件不存在", serviceName)
+	info, err := s.getProcessInfo(serviceName)
+	if err != nil {
+		return "", err
 	}
 
-	// 使用 tail 命令获取最后几行日志
-	tailCmd := exec.Command("tail", "-n", fmt.Sprintf("%d", lines), stdoutLogPath)
-	stdoutLog, err := tailCmd.CombinedOutput()
-	if err != nil {
+	// 读取末尾的内容，足够包含所需的行数
+	length := lines * 512
+	stdoutLog, _, _, err := s.client.TailProcessStdoutLog(serviceName, 0, length)
+	if err != nil && !supervisor_util.IsFault(err, supervisor_util.FaultNoFile) {
 		return "", fmt.Errorf("获取标准输出日志失败: %v", err)
 	}
-
-	// 获取错误日志
-	var stderrLog []byte
-	if _, err := os.Stat(stderrLogPath); err == nil {
-		tailErrCmd := exec.Command("tail", "-n", fmt.Sprintf("%d", lines), stderrLogPath)
-		stderrLog, _ = tailErrCmd.CombinedOutput()
+	stderrLog, _, _, err := s.client.TailProcessStderrLog(serviceName, 0, length)
+	if err != nil && !supervisor_util.IsFault(err, supervisor_util.FaultNoFile) {
+		return "", fmt.Errorf("获取错误日志失败: %v", err)
 	}
 
 	// 组合日志
-	result := fmt.Sprintf("=== 标准输出日志 (%s) ===\n%s\n\n", stdoutLogPath, string(stdoutLog))
-	if len(stderrLog) > 0 {
-		result += fmt.Sprintf("=== 错误日志 (%s) ===\n%s", stderrLogPath, string(stderrLog))
+	result := fmt.Sprintf("=== 标准输出日志 (%s) ===\n%s\n\n", info.StdoutLogfile, lastLines(stdoutLog, lines))
+	if stderrLog != "" {
+		result += fmt.Sprintf("=== 错误日志 (%s) ===\n%s", info.StderrLogfile, lastLines(stderrLog, lines))
 	}
 
 	return result, nil
@@ -436,15 +440,12 @@ func (s *SupervisorPlugin) GetLogs(serviceName string, lines int) (string, error
 func (s *SupervisorPlugin) IsRunning(serviceName string) (bool, error) {
 	fmt.Printf("检查服务 %s 是否运行中\n", serviceName)
 
-	err, output := s.RunShell("supervisorctl", "status", serviceName)
+	info, err := s.getProcessInfo(serviceName)
 	if err != nil {
-		if strings.Contains(output, "ERROR") && strings.Contains(output, "not found") {
-			return false, fmt.Errorf("服务 %s 不存在", serviceName)
-		}
-		return false, fmt.Errorf("获取服务状态失败: %v", err)
+		return false, err
 	}
 
-	return strings.Contains(output, "RUNNING"), nil
+	return info.State == supervisor_util.StateRunning, nil
 }
 
 func (s *SupervisorPlugin) GetServiceConfig(serviceName string) (map[string]interface{}, error) {
@@ -553,15 +554,14 @@ func (s *SupervisorPlugin) GetResourceUsage(serviceName string) (map[string]inte
 	}
 
 	// 获取服务的进程ID
-	err, output := s.RunShell("supervisorctl", "pid", serviceName)
+	info, err := s.getProcessInfo(serviceName)
 	if err != nil {
-		return nil, fmt.Errorf("获取进程ID失败: %v", err)
+		return nil, err
 	}
-
-	pid := strings.TrimSpace(output)
-	if pid == "" {
+	if info.PID == 0 {
 		return nil, fmt.Errorf("无法获取服务 %s 的进程ID", serviceName)
 	}
+	pid := strconv.Itoa(info.PID)
 
 	// 获取CPU和内存使用情况
 	psCmd := exec.Command("ps", "-p", pid, "-o", "%cpu,%mem")
@@ -597,29 +597,20 @@ func (s *SupervisorPlugin) GetServiceDetails(serviceName string) (map[string]int
 	fmt.Printf("获取服务 %s 的详细信息\n", serviceName)
 
 	// 获取服务状态
-	err, output := s.RunShell("supervisorctl", "status", serviceName)
+	info, err := s.getProcessInfo(serviceName)
 	if err != nil {
-		if strings.Contains(output, "ERROR") && strings.Contains(output, "not found") {
-			return nil, fmt.Errorf("服务 %s 不存在", serviceName)
-		}
-		return nil, fmt.Errorf("获取服务状态失败: %v", err)
+		return nil, err
 	}
 
-	// 解析状态输出
 	details := make(map[string]interface{})
-	details["raw_status"] = output
-
-	// 解析状态行
-	fields := strings.Fields(output)
-	if len(fields) >= 2 {
-		details["name"] = fields[0]
-		details["status"] = fields[1]
-
-		if len(fields) >= 4 && fields[1] == "RUNNING" {
-			// 格式: name RUNNING pid uptime
-			details["pid"] = fields[2]
-			details["uptime"] = fields[3]
-		}
+	details["name"] = info.FullName()
+	details["status"] = info.StateName
+	details["description"] = info.Description
+	if info.State == supervisor_util.StateRunning {
+		details["pid"] = info.PID
+		details["uptime"] = info.Uptime()
+	} else if info.Stop > 0 {
+		details["exit_status"] = info.ExitStatus
 	}
 
 	// 获取配置信息
@@ -651,15 +642,30 @@ func (s *SupervisorPlugin) ensureConfigDir() error {
 
 // 重新加载配置
 func (s *SupervisorPlugin) reloadConfig() error {
-	err, output := s.RunShellWithSudo("supervisorctl", "reread")
-	if err != nil {
-		return fmt.Errorf("重新读取配置失败: %v\n%s", err, output)
+	if err := s.client.Update(); err != nil {
+		return fmt.Errorf("更新配置失败: %v", err)
 	}
 
-	err, output = s.RunShellWithSudo("supervisorctl", "update")
+	return nil
+}
+
+// 获取进程信息，服务不存在时返回明确的错误
+func (s *SupervisorPlugin) getProcessInfo(serviceName string) (*supervisor_util.ProcessInfo, error) {
+	info, err := s.client.GetProcessInfo(serviceName)
 	if err != nil {
-		return fmt.Errorf("更新配置失败: %v\n%s", err, output)
+		if supervisor_util.IsFault(err, supervisor_util.FaultBadName) {
+			return nil, fmt.Errorf("服务 %s 不存在", serviceName)
+		}
+		return nil, fmt.Errorf("获取服务状态失败: %v", err)
 	}
+	return info, nil
+}
 
-	return nil
+// 返回内容的最后 n 行
+func lastLines(content string, n int) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
 }
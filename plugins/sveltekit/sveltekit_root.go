@@ -0,0 +1,28 @@
+package sveltekit
+
+import (
+	"fmt"
+	"path/filepath"
+	"servon/core"
+	"servon/plugins/nodeapp"
+)
+
+const DefaultPort = 3000
+
+func Setup(app *core.App) {
+	app.AddDeployer(nodeapp.NewDeployer(app, nodeapp.Framework{
+		Name:        "svelte",
+		Title:       "SvelteKit",
+		DefaultPort: DefaultPort,
+		Start:       start,
+	}))
+}
+
+// start 运行 adapter-node 构建出的 build/index.js
+func start(current, buildDir string) (string, []string, error) {
+	if !nodeapp.FileExists(filepath.Join(buildDir, "build", "index.js")) {
+		return "", nil, fmt.Errorf("未找到 build/index.js，请使用 @sveltejs/adapter-node 构建，静态输出的站点请使用 static 部署器")
+	}
+
+	return "node", []string{filepath.Join(current, "build", "index.js")}, nil
+}